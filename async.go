@@ -2,7 +2,10 @@ package gofetch
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -74,30 +77,81 @@ func (c *Client) DoGroupAsync(ctx context.Context, requests []*Request) <-chan [
 	return c.JoinAsyncResponses(ctx, channels...)
 }
 
+// GroupTimeoutMode selects how GroupOptions.IndividualTimeout is applied
+type GroupTimeoutMode int
+
+const (
+	// GroupTimeoutPerRequest gives every request its own IndividualTimeout, starting once the
+	// request clears client-side queueing such as WithPerHostConcurrency
+	GroupTimeoutPerRequest GroupTimeoutMode = iota
+	// GroupTimeoutShared applies IndividualTimeout as one deadline for the whole group, starting
+	// when the group is dispatched
+	GroupTimeoutShared
+)
+
 // GroupOptions specifies options for group async operations
 type GroupOptions struct {
-	IndividualTimeout time.Duration // Timeout for individual requests within a group
-	BufferSize        int           // Buffer size for result channel
+	IndividualTimeout time.Duration    // Timeout for individual requests within a group
+	TimeoutMode       GroupTimeoutMode // Whether IndividualTimeout is per request or shared by the group
+	BufferSize        int              // Buffer size for result channel
+}
+
+type requestTimeoutKey struct{}
+
+// groupContext derives the context shared by every request in a group. The returned cancel
+// function must be called once the whole group is done.
+func groupContext(ctx context.Context, opts GroupOptions) (context.Context, context.CancelFunc) {
+	if opts.IndividualTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if opts.TimeoutMode == GroupTimeoutShared {
+		return context.WithTimeout(ctx, opts.IndividualTimeout)
+	}
+	return context.WithValue(ctx, requestTimeoutKey{}, opts.IndividualTimeout), func() {}
+}
+
+// applyRequestTimeout starts the per-request timeout carried by the request context, if any,
+// when the round trip reaches next. The timeout covers reading the response body and is released
+// once the body is closed.
+func applyRequestTimeout(next http.RoundTripper) http.RoundTripper {
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		timeout, ok := req.Context().Value(requestTimeoutKey{}).(time.Duration)
+		if !ok {
+			return next.RoundTrip(req)
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		resp, err := next.RoundTrip(req.WithContext(ctx))
+		if err != nil || resp == nil || resp.Body == nil {
+			cancel()
+			return resp, err
+		}
+		resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	})
+}
+
+// cancelOnCloseBody releases a request timeout once the body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
 
 // DoGroupAsyncWithOptions is like DoGroupAsync but with additional options
 func (c *Client) DoGroupAsyncWithOptions(ctx context.Context, requests []*Request, opts GroupOptions) <-chan []AsyncResponse {
-	// Apply individual timeouts if specified
+	// Apply individual or shared timeouts if specified
 	channels := make([]<-chan AsyncResponse, len(requests))
-	cancelFuncs := make([]context.CancelFunc, len(requests))
+	groupCtx, groupCancel := groupContext(ctx, opts)
 
 	for i, req := range requests {
-		requestCtx := ctx
-
-		if opts.IndividualTimeout > 0 {
-			var cancel context.CancelFunc
-			requestCtx, cancel = context.WithTimeout(ctx, opts.IndividualTimeout)
-			cancelFuncs[i] = cancel
-		}
-
-		// Use a new context for each request
-		thisReq := req.Clone().WithContext(requestCtx)
-		channels[i] = c.DoAsync(requestCtx, thisReq)
+		// Clone each request onto the group context
+		thisReq := req.Clone().WithContext(groupCtx)
+		channels[i] = c.DoAsync(groupCtx, thisReq)
 	}
 
 	// Use specified buffer size or default to 1
@@ -110,12 +164,8 @@ func (c *Client) DoGroupAsyncWithOptions(ctx context.Context, requests []*Reques
 	go func() {
 		// Add panic recovery to prevent crashes
 		defer func() {
-			// Ensure all timeouts are cleaned up
-			for _, cancel := range cancelFuncs {
-				if cancel != nil {
-					cancel()
-				}
-			}
+			// Ensure the group timeout is cleaned up
+			groupCancel()
 
 			if r := recover(); r != nil {
 				close(out)
@@ -129,13 +179,6 @@ func (c *Client) DoGroupAsyncWithOptions(ctx context.Context, requests []*Reques
 		for i, ch := range channels {
 			go func(index int, ch <-chan AsyncResponse) {
 				defer wg.Done()
-				defer func() {
-					// Clean up the timeout when done
-					if cancelFuncs[index] != nil {
-						cancelFuncs[index]()
-						cancelFuncs[index] = nil
-					}
-				}()
 
 				select {
 				case resp := <-ch:
@@ -200,20 +243,12 @@ func (c *Client) ExecuteGroupAsync(ctx context.Context, requests []*Request, opt
 // ExecuteGroupAsyncWithOptions is like ExecuteGroupAsync but with additional group options
 func (c *Client) ExecuteGroupAsyncWithOptions(ctx context.Context, requests []*Request, groupOpts GroupOptions, execOpts ...ExecuteOption) <-chan []AsyncResponse {
 	channels := make([]<-chan AsyncResponse, len(requests))
-	cancelFuncs := make([]context.CancelFunc, len(requests))
+	groupCtx, groupCancel := groupContext(ctx, groupOpts)
 
 	for i, req := range requests {
-		requestCtx := ctx
-
-		if groupOpts.IndividualTimeout > 0 {
-			var cancel context.CancelFunc
-			requestCtx, cancel = context.WithTimeout(ctx, groupOpts.IndividualTimeout)
-			cancelFuncs[i] = cancel
-		}
-
-		// Use a new context for each request
-		thisReq := req.Clone().WithContext(requestCtx)
-		channels[i] = c.ExecuteAsync(requestCtx, thisReq, execOpts...)
+		// Clone each request onto the group context
+		thisReq := req.Clone().WithContext(groupCtx)
+		channels[i] = c.ExecuteAsync(groupCtx, thisReq, execOpts...)
 	}
 
 	bufferSize := 1
@@ -224,12 +259,8 @@ func (c *Client) ExecuteGroupAsyncWithOptions(ctx context.Context, requests []*R
 	out := make(chan []AsyncResponse, bufferSize)
 	go func() {
 		defer func() {
-			// Ensure all timeouts are cleaned up
-			for _, cancel := range cancelFuncs {
-				if cancel != nil {
-					cancel()
-				}
-			}
+			// Ensure the group timeout is cleaned up
+			groupCancel()
 
 			if r := recover(); r != nil {
 				close(out)
@@ -243,13 +274,6 @@ func (c *Client) ExecuteGroupAsyncWithOptions(ctx context.Context, requests []*R
 		for i, ch := range channels {
 			go func(index int, ch <-chan AsyncResponse) {
 				defer wg.Done()
-				defer func() {
-					// Clean up the timeout when done
-					if cancelFuncs[index] != nil {
-						cancelFuncs[index]()
-						cancelFuncs[index] = nil
-					}
-				}()
 
 				select {
 				case resp := <-ch:
@@ -279,3 +303,32 @@ func (c *Client) PostAsync(ctx context.Context, url string, body []byte, headers
 	req := NewRequest("POST", url).WithBody(body).WithHeaders(headers)
 	return c.DoAsync(ctx, req)
 }
+
+// AwaitJSON waits for the result on ch and decodes a successful JSON body into a T.
+// An async error is returned as is, and a non-2xx status yields a *StatusError.
+// The response body is always closed.
+func AwaitJSON[T any](ch <-chan AsyncResponse) (T, error) {
+	var result T
+
+	res, ok := <-ch
+	if !ok {
+		return result, errors.New("async response channel closed without a result")
+	}
+	if res.Error != nil {
+		return result, res.Error
+	}
+	if res.Response == nil {
+		return result, errors.New("async response has no response")
+	}
+
+	resp := res.Response
+	if !resp.IsSuccess() {
+		statusErr := NewStatusError(resp)
+		_ = resp.CloseBody()
+		return result, statusErr
+	}
+	if err := resp.JSON(&result); err != nil {
+		return result, NewResponseError("decode JSON response", err)
+	}
+	return result, nil
+}
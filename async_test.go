@@ -2,6 +2,7 @@ package gofetch_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -419,4 +420,110 @@ var _ = Describe("Async Operations", func() {
 			return atomic.LoadInt32(tracker.Count)
 		}, "2s", "100ms").Should(Equal(int32(0)), "Context count should be zero after all requests complete")
 	})
+	Context("group timeout modes", func() {
+		var client *gofetch.Client
+		var requests []*core.Request
+
+		BeforeEach(func() {
+			// Serialize requests so the third one queues for about 100ms behind the others
+			client = gofetch.NewClient(gofetch.WithPerHostConcurrency(nil, 1))
+			requests = []*core.Request{
+				core.NewRequest("GET", testServer.URL+"/delay"),
+				core.NewRequest("GET", testServer.URL+"/delay"),
+				core.NewRequest("GET", testServer.URL+"/delay"),
+			}
+		})
+
+		It("should start each per-request timeout once the request leaves the queue", func() {
+			opts := gofetch.GroupOptions{
+				IndividualTimeout: 120 * time.Millisecond,
+				TimeoutMode:       gofetch.GroupTimeoutPerRequest,
+			}
+
+			results := <-client.DoGroupAsyncWithOptions(context.Background(), requests, opts)
+
+			Expect(results).To(HaveLen(3))
+			for _, result := range results {
+				Expect(result.Error).NotTo(HaveOccurred())
+				Expect(result.Response.String()).To(Equal("delayed response"))
+			}
+		})
+
+		It("should fail requests that outlive a shared group deadline", func() {
+			opts := gofetch.GroupOptions{
+				IndividualTimeout: 120 * time.Millisecond,
+				TimeoutMode:       gofetch.GroupTimeoutShared,
+			}
+
+			results := <-client.DoGroupAsyncWithOptions(context.Background(), requests, opts)
+
+			Expect(results).To(HaveLen(3))
+			var timedOut int
+			for _, result := range results {
+				if result.Error != nil {
+					Expect(errors.Is(result.Error, context.DeadlineExceeded)).To(BeTrue())
+					timedOut++
+				}
+			}
+			Expect(timedOut).To(Equal(1))
+		})
+
+		It("should apply a shared deadline in ExecuteGroupAsyncWithOptions", func() {
+			opts := gofetch.GroupOptions{
+				IndividualTimeout: 30 * time.Millisecond,
+				TimeoutMode:       gofetch.GroupTimeoutShared,
+			}
+			requests := []*core.Request{
+				core.NewRequest("GET", testServer.URL+"/1"),
+				core.NewRequest("GET", testServer.URL+"/long-delay"),
+			}
+
+			results := <-gofetch.NewClient().ExecuteGroupAsyncWithOptions(context.Background(), requests, opts)
+
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Error).NotTo(HaveOccurred())
+			Expect(results[1].Error).To(HaveOccurred())
+			Expect(errors.Is(results[1].Error, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+
+	Context("AwaitJSON", func() {
+		type item struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+
+		It("should decode a successful async response", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(w, `{"id": 7, "name": "widget"}`)
+			}))
+			defer server.Close()
+
+			client := gofetch.NewClient()
+			got, err := gofetch.AwaitJSON[item](client.GetAsync(context.Background(), server.URL, nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(item{ID: 7, Name: "widget"}))
+		})
+
+		It("should propagate an async error", func() {
+			ch := make(chan gofetch.AsyncResponse, 1)
+			ch <- gofetch.AsyncResponse{Error: errors.New("boom")}
+			close(ch)
+
+			_, err := gofetch.AwaitJSON[item](ch)
+			Expect(err).To(MatchError("boom"))
+		})
+
+		It("should return a StatusError for a non-2xx response", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			client := gofetch.NewClient()
+			_, err := gofetch.AwaitJSON[item](client.GetAsync(context.Background(), server.URL, nil))
+			Expect(gofetch.IsStatusError(err, http.StatusNotFound)).To(BeTrue())
+		})
+	})
 })
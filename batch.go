@@ -0,0 +1,76 @@
+package gofetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BatchOp is a single operation within a batch request.
+type BatchOp struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// BatchResult is the outcome of a single operation within a batch response.
+type BatchResult struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// batchEnvelope is the JSON document posted to a batch endpoint.
+type batchEnvelope struct {
+	Operations []BatchOp `json:"operations"`
+}
+
+// IsSuccess returns true if the operation succeeded with a 2xx status and no error message.
+func (r BatchResult) IsSuccess() bool {
+	return r.Error == "" && r.Status >= 200 && r.Status < 300
+}
+
+// Err returns an error describing a failed operation, or nil if it succeeded.
+func (r BatchResult) Err() error {
+	if r.IsSuccess() {
+		return nil
+	}
+	if r.Error != "" {
+		return fmt.Errorf("batch operation failed with status %d: %s", r.Status, r.Error)
+	}
+	return fmt.Errorf("batch operation failed with status %d", r.Status)
+}
+
+// Decode unmarshals the operation's body into v.
+func (r BatchResult) Decode(v interface{}) error {
+	if len(r.Body) == 0 {
+		return fmt.Errorf("batch operation has no body")
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+// DoBatch posts ops to a batch endpoint as {"operations": [...]} and parses the
+// JSON array response into one BatchResult per operation, in order.
+// Failures of individual operations are reported through each BatchResult rather than the returned error.
+func (c *Client) DoBatch(ctx context.Context, url string, ops []BatchOp, opts ...RequestOption) ([]BatchResult, error) {
+	req := NewJSONRequest("POST", url, batchEnvelope{Operations: ops}, opts...)
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		_ = resp.CloseBody()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: url}
+	}
+
+	var results []BatchResult
+	if err := resp.JSON(&results); err != nil {
+		return nil, NewResponseError("decode batch response", err)
+	}
+	if len(results) != len(ops) {
+		return nil, NewResponseError(
+			fmt.Sprintf("batch response has %d results for %d operations", len(results), len(ops)), nil)
+	}
+	return results, nil
+}
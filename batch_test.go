@@ -0,0 +1,91 @@
+package gofetch_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jzx17/gofetch"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch Requests", func() {
+	var (
+		ts       *httptest.Server
+		received map[string][]map[string]interface{}
+		reply    string
+		status   int
+	)
+
+	BeforeEach(func() {
+		received = nil
+		status = http.StatusOK
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(reply))
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("should serialize operations and parse per-operation results", func() {
+		reply = `[
+			{"status": 200, "body": {"id": 1}},
+			{"status": 201, "body": {"id": 2}},
+			{"status": 404, "error": "user not found"}
+		]`
+
+		client := gofetch.NewClient()
+		results, err := client.DoBatch(context.Background(), ts.URL, []gofetch.BatchOp{
+			{Method: "GET", Path: "/users/1"},
+			{Method: "POST", Path: "/users", Body: map[string]string{"name": "bob"}},
+			{Method: "DELETE", Path: "/users/9"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ops := received["operations"]
+		Expect(ops).To(HaveLen(3))
+		Expect(ops[0]["method"]).To(Equal("GET"))
+		Expect(ops[0]).NotTo(HaveKey("body"))
+		Expect(ops[1]["path"]).To(Equal("/users"))
+		Expect(ops[1]["body"]).To(Equal(map[string]interface{}{"name": "bob"}))
+
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].IsSuccess()).To(BeTrue())
+		var created struct {
+			ID int `json:"id"`
+		}
+		Expect(results[1].Decode(&created)).To(Succeed())
+		Expect(created.ID).To(Equal(2))
+
+		Expect(results[2].IsSuccess()).To(BeFalse())
+		Expect(results[2].Err()).To(MatchError(ContainSubstring("user not found")))
+	})
+
+	It("should fail if the result count does not match the operations", func() {
+		reply = `[{"status": 200}]`
+
+		client := gofetch.NewClient()
+		_, err := client.DoBatch(context.Background(), ts.URL, []gofetch.BatchOp{
+			{Method: "GET", Path: "/a"},
+			{Method: "GET", Path: "/b"},
+		})
+		Expect(err).To(MatchError(ContainSubstring("1 results for 2 operations")))
+	})
+
+	It("should return a StatusError when the batch endpoint fails", func() {
+		status = http.StatusInternalServerError
+		reply = `oops`
+
+		client := gofetch.NewClient()
+		_, err := client.DoBatch(context.Background(), ts.URL, []gofetch.BatchOp{{Method: "GET", Path: "/a"}})
+		Expect(gofetch.IsStatusError(err, http.StatusInternalServerError)).To(BeTrue())
+	})
+})
@@ -0,0 +1,89 @@
+package gofetch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// BodyBufferFactory creates the storage Do buffers a response body into, such as a temporary
+// file for large downloads. contentLength is the declared length of the body, or -1 when
+// unknown. Reads must return the written bytes from the start; a store that implements
+// io.Seeker, like *os.File, is rewound after the body is written. The store becomes the body
+// of the returned Response and is closed with it, or by Do when buffering fails.
+type BodyBufferFactory func(contentLength int64) (io.ReadWriteCloser, error)
+
+// memoryBodyBuffer is the default in-memory store, retained by the Response for repeated reads
+type memoryBodyBuffer struct {
+	bytes.Buffer
+}
+
+func (b *memoryBodyBuffer) Close() error {
+	return nil
+}
+
+// newBodyBuffer returns a store from the configured factory, or an in-memory buffer
+// pre-sized to the declared length.
+func (c *Client) newBodyBuffer(contentLength int64) (io.ReadWriteCloser, error) {
+	if c.bodyBufferFactory != nil {
+		return c.bodyBufferFactory(contentLength)
+	}
+	buf := &memoryBodyBuffer{}
+	// Chunked responses report a ContentLength of -1, so only pre-size when the length is known.
+	if contentLength > 0 {
+		buf.Grow(int(c.bufferSizeHint(contentLength)))
+	}
+	return buf, nil
+}
+
+// bufferBody reads the body of resp into a body buffer and returns a Response that reads from it.
+func (c *Client) bufferBody(ctx context.Context, resp *http.Response) (*Response, error) {
+	if sizeErr := c.checkBufferedSize(resp.ContentLength); sizeErr != nil {
+		return nil, NewResponseError("read response body", sizeErr)
+	}
+	store, err := c.newBodyBuffer(resp.ContentLength)
+	if err != nil {
+		return nil, NewResponseError("create response body buffer", err)
+	}
+
+	n, err := io.Copy(store, c.limitBufferedBody(resp.Body))
+	if err == nil {
+		err = c.checkBufferedSize(n)
+	}
+	if err == nil {
+		err = rewindBodyBuffer(store)
+	}
+	if truncated := checkTruncated(resp.ContentLength, n, err); truncated != nil {
+		err = truncated
+	} else if err != nil {
+		err = c.timeoutError(ctx, err, TimeoutPhaseBody)
+	}
+	if err != nil {
+		_ = store.Close()
+		return nil, NewResponseError("read response body", err)
+	}
+
+	buffered := &http.Response{
+		Status:           resp.Status,
+		StatusCode:       resp.StatusCode,
+		Header:           resp.Header,
+		TransferEncoding: resp.TransferEncoding,
+		Request:          resp.Request,
+	}
+	if mem, ok := store.(*memoryBodyBuffer); ok {
+		return c.prepareResponse(NewBufferedResponse(buffered, mem.Bytes())), nil
+	}
+	buffered.Body = store
+	buffered.ContentLength = n
+	return c.prepareResponse(&Response{Response: buffered}), nil
+}
+
+// rewindBodyBuffer seeks a store that supports it back to the start of the written body.
+func rewindBodyBuffer(store io.ReadWriteCloser) error {
+	if seeker, ok := store.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		return err
+	}
+	return nil
+}
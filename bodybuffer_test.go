@@ -0,0 +1,150 @@
+package gofetch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jzx17/gofetch"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingBuffer is an in-memory store that counts the bytes written to it
+type countingBuffer struct {
+	buf     bytes.Buffer
+	written int
+	closed  bool
+}
+
+func (b *countingBuffer) Write(p []byte) (int, error) {
+	b.written += len(p)
+	return b.buf.Write(p)
+}
+
+func (b *countingBuffer) Read(p []byte) (int, error) {
+	return b.buf.Read(p)
+}
+
+func (b *countingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+// tempFileBuffer stores the body in a temporary file removed on close
+type tempFileBuffer struct {
+	*os.File
+}
+
+func (b *tempFileBuffer) Close() error {
+	err := b.File.Close()
+	_ = os.Remove(b.Name())
+	return err
+}
+
+var _ = Describe("Response Body Buffer Factory", func() {
+	var (
+		server  *httptest.Server
+		payload string
+	)
+
+	BeforeEach(func() {
+		payload = strings.Repeat("gofetch ", 4096)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			_, _ = w.Write([]byte(payload))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should buffer bodies into the stores created by the factory", func() {
+		var stores []*countingBuffer
+		var hints []int64
+		client := gofetch.NewClient(gofetch.WithResponseBodyReaderFactory(func(contentLength int64) (io.ReadWriteCloser, error) {
+			store := &countingBuffer{}
+			stores = append(stores, store)
+			hints = append(hints, contentLength)
+			return store, nil
+		}))
+
+		resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stores).To(HaveLen(1))
+		Expect(stores[0].written).To(Equal(len(payload)))
+		Expect(hints).To(Equal([]int64{int64(len(payload))}))
+		Expect(resp.ContentLength).To(Equal(int64(len(payload))))
+		Expect(resp.IsBuffered()).To(BeFalse())
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(payload))
+		Expect(stores[0].closed).To(BeTrue())
+	})
+
+	It("should rewind seekable stores such as temporary files", func() {
+		var path string
+		client := gofetch.NewClient(gofetch.WithResponseBodyReaderFactory(func(int64) (io.ReadWriteCloser, error) {
+			f, err := os.CreateTemp(GinkgoT().TempDir(), "body-*")
+			if err != nil {
+				return nil, err
+			}
+			path = f.Name()
+			return &tempFileBuffer{File: f}, nil
+		}))
+
+		resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := resp.Bytes()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(payload))
+		_, err = os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should close the store when buffering fails", func() {
+		store := &countingBuffer{}
+		client := gofetch.NewClient(
+			gofetch.WithResponseBodyReaderFactory(func(int64) (io.ReadWriteCloser, error) { return store, nil }),
+			gofetch.WithResponseBodyMaxBytes(10),
+		)
+
+		// A chunked body reveals its size only while it is buffered
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(payload))
+		})
+		_, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		var sizeErr *gofetch.SizeError
+		Expect(errors.As(err, &sizeErr)).To(BeTrue())
+		Expect(store.closed).To(BeTrue())
+	})
+
+	It("should report factory errors", func() {
+		client := gofetch.NewClient(gofetch.WithResponseBodyReaderFactory(func(int64) (io.ReadWriteCloser, error) {
+			return nil, errors.New("disk full")
+		}))
+
+		_, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		Expect(err).To(MatchError(ContainSubstring("create response body buffer: disk full")))
+	})
+
+	It("should keep in-memory buffering by default", func() {
+		resp, err := gofetch.NewClient().Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.IsBuffered()).To(BeTrue())
+		Expect(resp.BodyString()).To(Equal(payload))
+	})
+})
@@ -3,10 +3,18 @@ package gofetch
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/jzx17/gofetch/middlewares"
 )
 
 // Client is a configurable API client that supports middleware chaining and request building.
@@ -19,11 +27,72 @@ type Client struct {
 	// autoBuffer controls whether non-streaming responses are fully read into memory.
 	autoBuffer bool
 	sizeConfig SizeConfig
-	mu         sync.RWMutex // protects middlewares
+	// bodyBufferFactory creates the storage Do buffers response bodies into, when set.
+	bodyBufferFactory BodyBufferFactory
+	// maxBufferedBody caps the body read into memory by Do when auto-buffering, if positive.
+	maxBufferedBody int64
+	// hostLimiter caps concurrent in-flight requests per host when configured.
+	hostLimiter *hostConcurrencyLimiter
+	// counter assigns sequence numbers to requests when configured.
+	counter *atomic.Uint64
+	// responseTimeout bounds the total time to receive a response, including its body.
+	responseTimeout time.Duration
+	// stallTimeout bounds how long a response body may go without delivering data.
+	stallTimeout time.Duration
+	// baseURL resolves relative request URLs when set.
+	baseURL *url.URL
+	// defaultHeaders are set on every request that does not set them, keyed by canonical name.
+	defaultHeaders map[string]string
+	// defaultQueryParams are added to every request that does not set them.
+	defaultQueryParams map[string]string
+	// charsetOverride forces response bodies to be decoded from this charset to UTF-8.
+	charsetOverride string
+	// bodyDecryptor turns encrypted response bodies into plaintext before they reach the caller.
+	bodyDecryptor func(ciphertext []byte) ([]byte, error)
+	// decryptWhen limits bodyDecryptor to the responses it accepts; nil decrypts every body.
+	decryptWhen func(resp *http.Response) bool
+	// classifier decides retryability for middlewares that consult it.
+	classifier ErrorClassifier
+	// requestEditors run on every built *http.Request before it is sent.
+	requestEditors []RequestEditorFunc
+	// statusRewrite runs innermost in the chain so every middleware sees the rewritten status.
+	statusRewrite ConfigurableMiddleware
+	// headerWhitelist names the only response headers handed to the caller, when set.
+	headerWhitelist map[string]bool
+	// onConnectionError is called for round trips that fail at the connection level.
+	onConnectionError func(host string, err error)
+	// emptyBodyPolicy is applied to every response for its decode helpers.
+	emptyBodyPolicy EmptyBodyPolicy
+	// requestLog keeps the outcomes of recent requests when configured.
+	requestLog *requestLog
+	// redirectPolicy replaces the CheckRedirect of the underlying http.Client when set.
+	redirectPolicy func(req *http.Request, via []*http.Request) error
+	// forwardAuthOnRedirect keeps the Authorization header on cross-host redirects.
+	forwardAuthOnRedirect bool
+	// upgradeSupport hands 101 Switching Protocols responses back with their connection intact.
+	upgradeSupport bool
+	// httpsOnly rejects requests and redirects whose scheme is not https.
+	httpsOnly bool
+	// errorOnStatus makes Do return a StatusError for responses whose status it matches.
+	errorOnStatus func(statusCode int) bool
+	// maxRequestsPerConn closes each connection after it carried this many requests, if positive.
+	maxRequestsPerConn int
+	// retryHistory makes retries of a request dial a different IP after a connection failure.
+	retryHistory bool
+	// transportEdits configure the base *http.Transport once NewClient has settled on it.
+	transportEdits []transportEdit
+	// bodyReplayLimit is the number of leading response body bytes captured for CapturedBody.
+	bodyReplayLimit int
+	mu              sync.RWMutex // protects middlewares
 }
 
 // NewClient creates a new API client with default settings (30-second timeout, auto-buffering enabled),
 // optionally configured by provided options.
+//
+// Options that configure the transport itself, such as WithConnectTimeout or WithProxyFromURL,
+// apply to a clone of the final base transport, whichever of WithTransport, WithUnixSocket or
+// WithHTTPClient supplied it and in whatever order, so shared transports are not modified.
+// NewClient panics if such an option is used and that transport is not an *http.Transport.
 func NewClient(options ...Option) *Client {
 	c := &Client{
 		rt:          http.DefaultTransport,
@@ -45,7 +114,9 @@ func NewClient(options ...Option) *Client {
 	} else if c.rt != nil {
 		baseRt = c.rt
 	}
-	c.baseTransport = baseRt
+	// Per-request proxies are routed once here, so their pooled transports survive every
+	// rebuild of the middleware chain instead of leaking idle connections.
+	c.baseTransport = routeProxies(c.applyTransportEdits(baseRt))
 
 	// Wrap the base transport with the middleware chain.
 	wrappedRt := c.wrapTransport(c.baseTransport)
@@ -65,27 +136,74 @@ func NewClient(options ...Option) *Client {
 		}
 		c.client.Transport = wrappedRt
 	}
+	c.applyRedirectPolicy(c.client)
+	if c.httpsOnly {
+		enforceHTTPSRedirects(c.client)
+	}
 
 	return c
 }
 
+// transportEdit is a transport setting recorded by option, applied by NewClient
+type transportEdit struct {
+	option string
+	apply  func(t *http.Transport)
+}
+
+// editTransport records a setting to apply to the base transport in NewClient.
+func (c *Client) editTransport(option string, apply func(t *http.Transport)) {
+	c.transportEdits = append(c.transportEdits, transportEdit{option: option, apply: apply})
+}
+
+// applyTransportEdits returns a clone of base with the recorded transport settings applied, or
+// base itself when there are none. It panics if base is not an *http.Transport.
+func (c *Client) applyTransportEdits(base http.RoundTripper) http.RoundTripper {
+	if len(c.transportEdits) == 0 {
+		return base
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		panic(fmt.Sprintf("%s requires an *http.Transport, got %T", c.transportEdits[0].option, base))
+	}
+	t = t.Clone()
+	for _, edit := range c.transportEdits {
+		edit.apply(t)
+	}
+	return t
+}
+
 // wrapTransport builds the middleware chain on top of the provided base RoundTripper.
 func (c *Client) wrapTransport(base http.RoundTripper) http.RoundTripper {
-	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+	if c.maxRequestsPerConn > 0 {
+		base = rotateConnections(base, c.maxRequestsPerConn)
+	}
+	if c.onConnectionError != nil {
+		base = observeConnectionErrors(base, c.onConnectionError)
+	}
+	var rt http.RoundTripper = RoundTripFunc(func(req *http.Request) (*http.Response, error) {
 		c.mu.RLock()
 		mws := make([]ConfigurableMiddleware, len(c.middlewares))
 		copy(mws, c.middlewares)
 		c.mu.RUnlock()
 
-		final := func(req *http.Request) (*http.Response, error) {
+		var final RoundTripFunc = func(req *http.Request) (*http.Response, error) {
+			countAttempt(req.Context())
 			return base.RoundTrip(req)
 		}
+		if c.statusRewrite != nil {
+			final = c.statusRewrite.Wrap(final)
+		}
 		chain := ChainMiddlewares(final, mws...)
 		return chain(req)
 	})
+	rt = applyRequestTimeout(rt)
+	if c.hostLimiter != nil {
+		rt = c.hostLimiter.wrap(rt)
+	}
+	return rt
 }
 
-// getBaseRoundTripper returns the unwrapped base RoundTripper.
+// getBaseRoundTripper returns the base RoundTripper, before any middleware is applied.
 func (c *Client) getBaseRoundTripper() http.RoundTripper {
 	if c.baseTransport != nil {
 		return c.baseTransport
@@ -156,36 +274,257 @@ func (c *Client) GetMiddlewares() []ConfigurableMiddleware {
 
 // Do send the HTTP request built from the provided Request and returns a Response.
 // For non-streaming requests, if autoBuffer is enabled, the full response is read into memory.
-func (c *Client) Do(ctx context.Context, req *Request) (res *Response, err error) {
-	httpReq, err := req.BuildHTTPRequest()
+// With WithErrorOnStatus, matching statuses return both the response and a *StatusError.
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	return c.do(ctx, req, false)
+}
+
+// do runs the request pipeline shared by Do and DoStream: it builds req, applies the client
+// defaults and editors, sends it and prepares the response. Streamed responses are never
+// buffered and never report a StatusError.
+func (c *Client) do(ctx context.Context, req *Request, stream bool) (res *Response, err error) {
+	ctx = c.requestContext(ctx, req)
+	if stream {
+		ctx = middlewares.MarkStreamingRequest(ctx)
+	}
+	httpReq, err := req.BuildHTTPRequestWithContext(ctx)
 	if err != nil {
 		return nil, NewRequestError("build request", err)
 	}
-	httpReq = httpReq.WithContext(ctx)
-	resp, err := c.client.Do(httpReq)
+	ctx = httpReq.Context()
+	c.applyDefaults(httpReq)
+	if err := c.applyEditors(httpReq); err != nil {
+		return nil, err
+	}
+	if err := c.checkScheme(httpReq.URL); err != nil {
+		return nil, err
+	}
+	if c.requestLog != nil {
+		var record func(*Response, error)
+		ctx, record = c.requestLog.start(ctx, httpReq)
+		defer func() { record(res, err) }()
+	}
+	ctx, cancel := c.withResponseTimeout(ctx)
+	ctx, cancel, stall := c.withStallDetection(ctx, cancel)
+	httpReq, phase := middlewares.TrackTimeoutPhase(httpReq.WithContext(ctx))
+	resp, err := c.send(httpReq)
 	if err != nil {
-		return nil, NewTransportError("execute request", err)
+		cancel()
+		return nil, classifyDoError("execute request", c.timeoutError(ctx, err, phase()))
+	}
+	if upgraded, ok := c.upgradedResponse(resp); ok {
+		cancel()
+		return upgraded, nil
+	}
+	stall.watch(ctx, resp)
+	if err := c.decryptBody(resp); err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := c.overrideCharset(resp); err != nil {
+		cancel()
+		return nil, err
 	}
-	if c.autoBuffer {
+	c.stripHeaders(resp)
+	if c.autoBuffer && !stream {
+		defer cancel()
 		defer func() {
 			if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
 				err = NewResponseError("close response body", closeErr)
 			}
 		}()
-		var bodyBuf bytes.Buffer
-		data, err := io.ReadAll(resp.Body)
+		res, err = c.bufferBody(ctx, resp)
 		if err != nil {
-			return nil, NewResponseError("read response body", err)
+			return nil, err
 		}
-		bodyBuf.Write(data)
-		return &Response{Response: &http.Response{
-			Status:     resp.Status,
-			StatusCode: resp.StatusCode,
-			Header:     resp.Header,
-			Body:       io.NopCloser(bytes.NewReader(bodyBuf.Bytes())),
-		}}, nil
+		return res, c.statusError(res, httpReq)
+	}
+	c.attachResponseTimeout(ctx, cancel, resp)
+	res = c.prepareResponse(&Response{Response: resp})
+	if stream {
+		return res, nil
+	}
+	return res, c.statusError(res, httpReq)
+}
+
+// statusError returns a StatusError for res when WithErrorOnStatus matches its status.
+func (c *Client) statusError(res *Response, httpReq *http.Request) error {
+	if c.errorOnStatus == nil || !c.errorOnStatus(res.StatusCode) {
+		return nil
+	}
+	statusErr := NewStatusError(res)
+	if statusErr.URL == "" {
+		statusErr.URL = httpReq.URL.String()
 	}
-	return &Response{Response: resp}, nil
+	return statusErr
+}
+
+// requestContext attaches per-call client state, such as the sequence number and error
+// classifier, to ctx for the middleware chain.
+func (c *Client) requestContext(ctx context.Context, req *Request) context.Context {
+	ctx = c.assignSequence(ctx)
+	if c.classifier != nil {
+		ctx = middlewares.WithErrorClassifierContext(ctx, c.classifier)
+	}
+	if c.retryHistory {
+		ctx = withDialHistory(ctx)
+	}
+	return ctx
+}
+
+// prepareResponse applies the per-client response settings: it captures the body prefix of r
+// when WithResponseBodyReplay is set and sets the empty body policy.
+func (c *Client) prepareResponse(r *Response) *Response {
+	r.CaptureBody(c.bodyReplayLimit)
+	return r.WithEmptyBodyPolicy(c.emptyBodyPolicy)
+}
+
+// overrideCharset transcodes the response body when WithResponseCharsetOverride is set.
+// The body is closed if the charset is not supported.
+func (c *Client) overrideCharset(resp *http.Response) error {
+	if c.charsetOverride == "" {
+		return nil
+	}
+	if err := (&Response{Response: resp}).OverrideCharset(c.charsetOverride); err != nil {
+		_ = resp.Body.Close()
+		return NewResponseError("override response charset", err)
+	}
+	return nil
+}
+
+// decryptBody replaces the response body with its plaintext when WithResponseBodyDecryptor is set.
+// The encrypted body is read in full and closed. Responses that cannot carry a body, those
+// rejected by WithResponseBodyDecryptorWhen and empty bodies are left as they are.
+func (c *Client) decryptBody(resp *http.Response) error {
+	if c.bodyDecryptor == nil || !mayHaveBody(resp) {
+		return nil
+	}
+	if c.decryptWhen != nil && !c.decryptWhen(resp) {
+		return nil
+	}
+	ciphertext, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return NewResponseError("read encrypted response body", err)
+	}
+	if len(ciphertext) == 0 {
+		resp.Body = http.NoBody
+		return nil
+	}
+	plaintext, err := c.bodyDecryptor(ciphertext)
+	if err != nil {
+		return NewResponseError("decrypt response body", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(plaintext))
+	resp.ContentLength = int64(len(plaintext))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(plaintext)))
+	return nil
+}
+
+// mayHaveBody reports whether resp can carry a body: HEAD responses and 1xx, 204 and 304
+// statuses never do.
+func mayHaveBody(resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false
+	}
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
+	}
+	code := resp.StatusCode
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+// stripHeaders removes the response headers not allowed by WithResponseHeaderWhitelist.
+func (c *Client) stripHeaders(resp *http.Response) {
+	if c.headerWhitelist == nil {
+		return
+	}
+	for name := range resp.Header {
+		if !c.headerWhitelist[http.CanonicalHeaderKey(name)] {
+			delete(resp.Header, name)
+		}
+	}
+}
+
+// attachResponseTimeout ties the response timeout to the lifetime of a streamed body.
+func (c *Client) attachResponseTimeout(ctx context.Context, cancel context.CancelFunc, resp *http.Response) {
+	if c.responseTimeout <= 0 {
+		return
+	}
+	if resp.Body == nil {
+		cancel()
+		return
+	}
+	resp.Body = &responseTimeoutBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, client: c}
+}
+
+// checkTruncated returns a TruncatedResponseError when a body that ended cleanly or with an
+// unexpected EOF holds fewer than the declared contentLength bytes, and nil otherwise.
+func checkTruncated(contentLength, received int64, err error) error {
+	if contentLength <= 0 || received >= contentLength {
+		return nil
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return &TruncatedResponseError{ExpectedBytes: contentLength, ReceivedBytes: received, Err: err}
+}
+
+// bufferSizeHint bounds a declared Content-Length by the configured response size limits
+// so a misleading header cannot force an oversized allocation.
+func (c *Client) bufferSizeHint(contentLength int64) int64 {
+	if limit := c.sizeConfig.MaxResponseBodySize; limit > 0 && contentLength > limit {
+		contentLength = limit
+	}
+	if limit := c.maxBufferedBody; limit > 0 && contentLength > limit {
+		contentLength = limit
+	}
+	return contentLength
+}
+
+// limitBufferedBody caps body at one byte past WithResponseBodyMaxBytes, enough for
+// checkBufferedSize to detect an oversized body without reading the rest of it.
+func (c *Client) limitBufferedBody(body io.Reader) io.Reader {
+	if c.maxBufferedBody <= 0 {
+		return body
+	}
+	return io.LimitReader(body, c.maxBufferedBody+1)
+}
+
+// checkBufferedSize returns a SizeError when size exceeds WithResponseBodyMaxBytes.
+func (c *Client) checkBufferedSize(size int64) error {
+	if c.maxBufferedBody <= 0 || size <= c.maxBufferedBody {
+		return nil
+	}
+	return &SizeError{Current: size, Max: c.maxBufferedBody, Type: "response"}
+}
+
+// withTimeoutPhase wraps network timeouts in a TimeoutError tagged with the given phase.
+// Errors that already carry a TimeoutError are returned unchanged.
+func withTimeoutPhase(err error, phase TimeoutPhase) error {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &TimeoutError{Phase: phase, Err: err}
+	}
+	return err
+}
+
+// classifyDoError wraps an error from executing a request in the matching ClientError phase.
+// Validation failures raised by middleware are reported as response errors, and redirects
+// blocked by WithHTTPSOnly as request errors.
+func classifyDoError(msg string, err error) error {
+	var validationErr *ResponseValidationError
+	if errors.As(err, &validationErr) {
+		return NewResponseError("validate response", validationErr)
+	}
+	if errors.Is(err, ErrInsecureScheme) {
+		return NewRequestError("enforce https", err)
+	}
+	return NewTransportError(msg, err)
 }
 
 // DoWithTimeout is like Do but with a specific timeout for this request
@@ -197,17 +536,12 @@ func (c *Client) DoWithTimeout(parentCtx context.Context, req *Request, timeout
 
 // DoStream sends the HTTP request built from the provided Request and returns a Response for manual streaming.
 // The caller is responsible for closing the response.
+//
+// When a size limit is configured with WithSizeConfig, MaxStreamSize is enforced through a
+// limited reader: the call itself succeeds, and reading past the limit fails with a SizeError
+// of type "stream". Bodies with a known Content-Length over the limit are rejected up front.
 func (c *Client) DoStream(ctx context.Context, req *Request) (*Response, error) {
-	httpReq, err := req.BuildHTTPRequest()
-	if err != nil {
-		return nil, NewRequestError("build HTTP request", err)
-	}
-	httpReq = httpReq.WithContext(ctx)
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return nil, NewTransportError("execute HTTP request", err)
-	}
-	return &Response{Response: resp}, nil
+	return c.do(ctx, req, true)
 }
 
 // Execute sends HTTP request and returns a response with various options
@@ -224,7 +558,12 @@ func (c *Client) Execute(ctx context.Context, req *Request, opts ...ExecuteOptio
 	}
 
 	if config.stream {
-		return c.DoStream(ctx, req)
+		resp, err := c.DoStream(ctx, req)
+		if err != nil || config.maxBodyBytes <= 0 {
+			return resp, err
+		}
+		resp.Body = middlewares.NewLimitedBody(resp.Body, config.maxBodyBytes, "stream")
+		return resp, nil
 	}
 
 	return c.Do(ctx, req)
@@ -234,8 +573,9 @@ func (c *Client) Execute(ctx context.Context, req *Request, opts ...ExecuteOptio
 type ExecuteOption func(*executeConfig)
 
 type executeConfig struct {
-	stream  bool
-	timeout time.Duration
+	stream       bool
+	timeout      time.Duration
+	maxBodyBytes int64
 }
 
 func defaultExecuteConfig() *executeConfig {
@@ -252,6 +592,15 @@ func WithStreamProcessing() ExecuteOption {
 	}
 }
 
+// WithResponseBodyLimitReader bounds how many bytes may be read from a streamed response
+// (see WithStreamProcessing). Reading past n bytes fails with a SizeError of type "stream".
+// It applies in addition to any client-wide SizeConfig limit.
+func WithResponseBodyLimitReader(n int64) ExecuteOption {
+	return func(c *executeConfig) {
+		c.maxBodyBytes = n
+	}
+}
+
 // WithRequestTimeout sets a specific timeout for this request
 func WithRequestTimeout(timeout time.Duration) ExecuteOption {
 	return func(c *executeConfig) {
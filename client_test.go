@@ -1,9 +1,12 @@
 package gofetch_test
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/jzx17/gofetch"
 	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
 	"github.com/jzx17/gofetch/utils/test"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -371,6 +375,92 @@ var _ = Describe("Client", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should enforce MaxStreamSize on DoStream reads", func() {
+		client := gofetch.NewClient(gofetch.WithSizeConfig(
+			gofetch.DefaultSizeConfig().WithResponseBodySize(1024).WithStreamSize(10),
+		))
+
+		// Flush each chunk so the length is unknown up front and the limit applies on read.
+		flushServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 3; i++ {
+				_, _ = fmt.Fprintf(w, "chunk%d\n", i)
+				w.(http.Flusher).Flush()
+			}
+		}))
+		defer flushServer.Close()
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", flushServer.URL))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+
+		_, err = io.ReadAll(resp.Body)
+		var sizeErr *gofetch.SizeError
+		Expect(errors.As(err, &sizeErr)).To(BeTrue())
+		Expect(sizeErr.Type).To(Equal("stream"))
+		Expect(sizeErr.Max).To(Equal(int64(10)))
+
+		// A known Content-Length over the limit is rejected before any read.
+		_, err = client.DoStream(context.Background(), core.NewRequest("GET", testServer.URL+"/stream"))
+		Expect(errors.As(err, &sizeErr)).To(BeTrue())
+		Expect(sizeErr.Type).To(Equal("stream"))
+	})
+
+	It("should bound streamed reads with WithResponseBodyLimitReader", func() {
+		client := gofetch.NewClient()
+		req := core.NewRequest("GET", testServer.URL+"/stream")
+
+		resp, err := client.Execute(context.Background(), req,
+			gofetch.WithStreamProcessing(),
+			gofetch.WithResponseBodyLimitReader(8),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+
+		_, err = io.ReadAll(resp.Body)
+		var sizeErr *gofetch.SizeError
+		Expect(errors.As(err, &sizeErr)).To(BeTrue())
+		Expect(sizeErr.Max).To(Equal(int64(8)))
+	})
+
+	It("should capture a streamed body prefix with WithResponseBodyReplay", func() {
+		client := gofetch.NewClient(gofetch.WithResponseBodyReplay(8))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", testServer.URL+"/stream"))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+
+		var streamed strings.Builder
+		Expect(resp.StreamChunks(func(chunk []byte) {
+			streamed.Write(chunk)
+		})).To(Succeed())
+
+		Expect(streamed.String()).To(Equal("chunk0\nchunk1\nchunk2\n"))
+		Expect(string(resp.CapturedBody())).To(Equal("chunk0\nc"))
+	})
+
+	It("should allow repeated reads of a buffered response", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `{"id":1}`)
+		}))
+		defer server.Close()
+
+		resp, err := gofetch.NewClient().Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+
+		var payload map[string]int
+		Expect(resp.JSON(&payload)).To(Succeed())
+		Expect(payload).To(HaveKeyWithValue("id", 1))
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(`{"id":1}`))
+
+		data, err := resp.Bytes()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"id":1}`))
+	})
+
 	// Test DoWithTimeout
 	It("should respect DoWithTimeout", func() {
 		client := gofetch.NewClient()
@@ -386,4 +476,227 @@ var _ = Describe("Client", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("context deadline exceeded"))
 	})
+
+	Describe("timeout phases", func() {
+		expectPhase := func(err error, phase gofetch.TimeoutPhase) {
+			Expect(err).To(HaveOccurred())
+			var timeoutErr *gofetch.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+			Expect(timeoutErr.Phase).To(Equal(phase))
+			Expect(err.Error()).To(ContainSubstring("timed out during " + string(phase)))
+		}
+
+		It("should report the dial phase when connecting stalls", func() {
+			transport := &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+			}
+			client := gofetch.NewClient(
+				gofetch.WithTransport(transport),
+				gofetch.WithTimeout(50*time.Millisecond),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+			expectPhase(err, gofetch.TimeoutPhaseDial)
+		})
+
+		It("should report the TLS handshake phase when the server never completes the handshake", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					// Hold the connection open without speaking TLS until the client gives up.
+					go func(conn net.Conn) {
+						defer conn.Close()
+						_, _ = io.Copy(io.Discard, conn)
+					}(conn)
+				}
+			}()
+
+			client := gofetch.NewClient(gofetch.WithTimeout(100 * time.Millisecond))
+			_, err = client.Do(context.Background(), core.NewRequest("GET", "https://"+listener.Addr().String()))
+			expectPhase(err, gofetch.TimeoutPhaseTLSHandshake)
+		})
+
+		It("should report the headers phase when the server is slow to respond", func() {
+			slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			}))
+			defer slowServer.Close()
+
+			client := gofetch.NewClient(gofetch.WithTimeout(50 * time.Millisecond))
+			_, err := client.Do(context.Background(), core.NewRequest("GET", slowServer.URL))
+			expectPhase(err, gofetch.TimeoutPhaseHeaders)
+		})
+
+		It("should report the body phase when the body stalls mid-transfer", func() {
+			slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, "partial")
+				w.(http.Flusher).Flush()
+				time.Sleep(300 * time.Millisecond)
+			}))
+			defer slowServer.Close()
+
+			client := gofetch.NewClient(gofetch.WithTimeout(100 * time.Millisecond))
+			_, err := client.Do(context.Background(), core.NewRequest("GET", slowServer.URL))
+			expectPhase(err, gofetch.TimeoutPhaseBody)
+		})
+
+		It("should report the phase through the retry middleware", func() {
+			slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			}))
+			defer slowServer.Close()
+
+			transport := &http.Transport{ResponseHeaderTimeout: 30 * time.Millisecond}
+			client := gofetch.NewClient(
+				gofetch.WithTransport(transport),
+				gofetch.WithMiddlewares(middlewares.SimpleRetryMiddleware(0, 0)),
+			)
+			_, err := client.Do(context.Background(), core.NewRequest("GET", slowServer.URL))
+			expectPhase(err, gofetch.TimeoutPhaseHeaders)
+		})
+	})
+
+	It("should surface response validation failures as response errors", func() {
+		client := gofetch.NewClient(gofetch.WithMiddlewares(
+			gofetch.ResponseValidationMiddleware(middlewares.RequireHeader("X-Required")),
+		))
+
+		_, err := client.Do(context.Background(), core.NewRequest("GET", testServer.URL+"/text"))
+		Expect(err).To(HaveOccurred())
+
+		var clientErr *gofetch.ClientError
+		Expect(errors.As(err, &clientErr)).To(BeTrue())
+		Expect(clientErr.Phase).To(Equal("response"))
+
+		var validationErr *gofetch.ResponseValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+	})
+
+	It("should buffer chunked responses in full and keep the chunked flag", func() {
+		chunkedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			for i := 0; i < 5; i++ {
+				_, _ = fmt.Fprintf(w, "part%d;", i)
+				flusher.Flush()
+			}
+		}))
+		defer chunkedServer.Close()
+
+		client := gofetch.NewClient()
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", chunkedServer.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.IsChunked()).To(BeTrue())
+
+		body, err := resp.Bytes()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("part0;part1;part2;part3;part4;"))
+	})
+
+	Context("gzip request bodies", func() {
+		var (
+			gzipServer *httptest.Server
+			received   []string
+			calls      int
+		)
+
+		BeforeEach(func() {
+			received = nil
+			calls = 0
+			gzipServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				Expect(r.Header.Get("Content-Encoding")).To(Equal("gzip"))
+				zr, err := gzip.NewReader(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				data, err := io.ReadAll(zr)
+				Expect(err).NotTo(HaveOccurred())
+				received = append(received, r.Header.Get("Content-Type")+"|"+string(data))
+				if calls == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			gzipServer.Close()
+		})
+
+		It("should send a gzip body the server can decompress, across retries", func() {
+			client := gofetch.NewClient(gofetch.WithMiddlewares(
+				middlewares.SimpleRetryMiddleware(1, time.Millisecond),
+			))
+			payload := strings.Repeat("compress me ", 100)
+
+			resp, err := client.Do(context.Background(), core.NewRequest("POST", gzipServer.URL).WithGzipBody([]byte(payload)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(received).To(HaveLen(2))
+			Expect(received[1]).To(Equal(received[0]))
+			Expect(received[1]).To(HaveSuffix("|" + payload))
+			Expect(received[1]).To(HavePrefix("text/plain"))
+		})
+
+		It("should send a gzip JSON body", func() {
+			calls = 1 // skip the simulated failure
+			client := gofetch.NewClient()
+
+			_, err := client.Do(context.Background(), core.NewRequest("POST", gzipServer.URL).
+				WithGzipJSONBody(map[string]int{"answer": 42}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received).To(Equal([]string{`application/json|{"answer":42}`}))
+		})
+	})
+	It("should stop streaming a channel body when the context passed to Do is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := make(chan []byte, 1)
+		ch <- []byte("partial")
+
+		bodyErr := make(chan error, 1)
+		client := gofetch.NewClient(gofetch.WithTransport(core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			first := make([]byte, len("partial"))
+			if _, err := io.ReadFull(req.Body, first); err != nil {
+				return nil, err
+			}
+			cancel()
+			_, err := io.ReadAll(req.Body)
+			bodyErr <- err
+			return nil, err
+		})))
+
+		_, err := client.Do(ctx, core.NewRequest("POST", "http://example.com").WithBodyChannel(ch))
+		Expect(err).To(HaveOccurred())
+		Eventually(bodyErr).Should(Receive(MatchError(core.ErrBodyStreamAborted)))
+	})
+
+	It("should fail Do when the producer aborts a channel body", func() {
+		ch := make(chan []byte, 1)
+		abort := make(chan error, 1)
+		ch <- []byte("partial")
+
+		client := gofetch.NewClient(gofetch.WithTransport(core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			first := make([]byte, len("partial"))
+			if _, err := io.ReadFull(req.Body, first); err != nil {
+				return nil, err
+			}
+			abort <- errors.New("source went away")
+			if _, err := io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})))
+
+		_, err := client.Do(context.Background(), core.NewRequest("POST", "http://example.com").WithBodyChannelAbort(ch, abort))
+		Expect(errors.Is(err, core.ErrBodyStreamAborted)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("source went away")))
+	})
 })
@@ -0,0 +1,58 @@
+package gofetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// observeConnectionErrors wraps base so onError is called with the target host for every
+// round trip that fails at the connection level. Each attempt is observed, including retries.
+func observeConnectionErrors(base http.RoundTripper, onError func(host string, err error)) http.RoundTripper {
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := base.RoundTrip(req)
+		if err != nil && isConnectionError(err) {
+			onError(req.URL.Host, err)
+		}
+		return resp, err
+	})
+}
+
+// isConnectionError reports whether err comes from the connection itself: dialing, a reset or
+// prematurely closed connection, or the TLS handshake. Cancellations are not connection errors,
+// but a ConnectError is, even when the connect timeout expired.
+func isConnectionError(err error) bool {
+	var connectErr *ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var opErr *net.OpError
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &opErr),
+		errors.As(err, &recordErr),
+		errors.As(err, &verifyErr),
+		errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr):
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
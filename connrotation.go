@@ -0,0 +1,118 @@
+package gofetch
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// rotatingConn counts the requests sent over a connection and how many of them are in flight.
+type rotatingConn struct {
+	net.Conn
+
+	mu       sync.Mutex
+	requests int
+	active   int
+	retired  bool
+}
+
+// acquire records a request picked up by the connection and returns how many it has carried.
+func (c *rotatingConn) acquire() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests++
+	c.active++
+	return c.requests
+}
+
+// release marks a request done, closing the connection once it has carried maxRequests and no
+// request is using it anymore.
+func (c *rotatingConn) release(maxRequests int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active--
+	if c.active == 0 && c.requests >= maxRequests && !c.retired {
+		c.retired = true
+		_ = c.Conn.Close()
+	}
+}
+
+// countingDialer wraps dial so every connection it opens records how many requests it carried.
+// A nil dial uses a net.Dialer with the settings of http.DefaultTransport.
+func countingDialer(dial DialContextFunc) DialContextFunc {
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &rotatingConn{Conn: conn}, nil
+	}
+}
+
+// rotateConnections closes each connection once the response to its maxRequests-th request has
+// been closed, so the next request dials a new one. The connection is only known once the
+// transport picks it, which the GotConn hook reports; the request itself is left untouched.
+func rotateConnections(base http.RoundTripper, maxRequests int) http.RoundTripper {
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var conn *rotatingConn
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				if c, ok := unwrapRotatingConn(info.Conn); ok {
+					if conn != nil {
+						// The transport retried on another connection.
+						conn.release(maxRequests)
+					}
+					conn = c
+					c.acquire()
+				}
+			},
+		}
+		resp, err := base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+		if conn == nil {
+			return resp, err
+		}
+		if err != nil {
+			conn.release(maxRequests)
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			// The caller owns the upgraded connection now.
+			return resp, nil
+		}
+		resp.Body = &rotatingBody{ReadCloser: resp.Body, conn: conn, maxRequests: maxRequests}
+		return resp, nil
+	})
+}
+
+// rotatingBody releases its connection's request when the response body is closed.
+type rotatingBody struct {
+	io.ReadCloser
+	conn        *rotatingConn
+	maxRequests int
+	once        sync.Once
+}
+
+func (b *rotatingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.conn.release(b.maxRequests) })
+	return err
+}
+
+// unwrapRotatingConn finds the rotatingConn beneath conn, looking through TLS.
+func unwrapRotatingConn(conn net.Conn) (*rotatingConn, bool) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	rc, ok := conn.(*rotatingConn)
+	return rc, ok
+}
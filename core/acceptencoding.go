@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// WithAcceptEncodingQ sets the Accept-Encoding header from encodings mapped to their quality
+// values, listed by descending preference, e.g. "br;q=1.0, gzip;q=0.8, identity;q=0.1". Equal
+// values are ordered by name. q-values must lie between 0 and 1 with at most three decimals, and
+// a q of 0 marks an encoding as not acceptable. Go's transport leaves bodies compressed when
+// Accept-Encoding is set manually, so pair this with WithResponseCompressionAuto or
+// Response.WithContentDecoding. Invalid input is reported when the request is built.
+func (r *Request) WithAcceptEncodingQ(encodings map[string]float64) *Request {
+	value, err := formatQValues(encodings)
+	if err != nil {
+		r.buildErr = fmt.Errorf("invalid Accept-Encoding: %w", err)
+		return r
+	}
+	return r.WithHeader("Accept-Encoding", value)
+}
+
+// formatQValues renders a q-valued header list, highest quality first.
+func formatQValues(values map[string]float64) (string, error) {
+	if len(values) == 0 {
+		return "", fmt.Errorf("no encodings given")
+	}
+
+	names := make([]string, 0, len(values))
+	for name, q := range values {
+		if name == "" || strings.IndexFunc(name, func(c rune) bool { return !httpguts.IsTokenRune(c) }) != -1 {
+			return "", fmt.Errorf("invalid encoding name %q", name)
+		}
+		if math.IsNaN(q) || q < 0 || q > 1 {
+			return "", fmt.Errorf("q-value %v for %s is outside [0, 1]", q, name)
+		}
+		if math.Round(q*1000)/1000 != q {
+			return "", fmt.Errorf("q-value %v for %s has more than three decimals", q, name)
+		}
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if values[names[i]] != values[names[j]] {
+			return values[names[i]] > values[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ";q=" + strconv.FormatFloat(values[name], 'f', qDecimals(values[name]), 64)
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// qDecimals returns the number of decimals needed to render q, at least one.
+func qDecimals(q float64) int {
+	for decimals := 1; decimals < 3; decimals++ {
+		scale := math.Pow(10, float64(decimals))
+		if math.Round(q*scale)/scale == q {
+			return decimals
+		}
+	}
+	return 3
+}
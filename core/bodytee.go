@@ -0,0 +1,83 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// WithBodyTee copies the request body to sink as it is sent, e.g. to archive uploads or
+// write an audit log. Attempts replayed through GetBody, such as retries and redirects, send
+// the same bytes again, so only bytes past what sink already received are written: sink ends
+// up with exactly one copy of the body, however many attempts it took. A write error from
+// sink fails the request. It has no effect on requests without a body.
+func (r *Request) WithBodyTee(sink io.Writer) *Request {
+	r.bodyTee = sink
+	return r
+}
+
+// bodyTee writes the bytes of every attempt of one request to sink, skipping those already written
+type bodyTee struct {
+	mu      sync.Mutex
+	sink    io.Writer
+	written int64
+	err     error
+}
+
+// teeBody tees httpReq.Body, and every body returned by its GetBody, to sink.
+func teeBody(httpReq *http.Request, sink io.Writer) {
+	if httpReq.Body == nil || httpReq.Body == http.NoBody {
+		return
+	}
+
+	tee := &bodyTee{sink: sink}
+	httpReq.Body = &teeReadCloser{ReadCloser: httpReq.Body, tee: tee}
+	if getBody := httpReq.GetBody; getBody != nil {
+		httpReq.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil || body == http.NoBody {
+				return body, err
+			}
+			return &teeReadCloser{ReadCloser: body, tee: tee}, nil
+		}
+	}
+}
+
+// write passes on the part of p, read at offset of the body, that sink has not received yet.
+func (t *bodyTee) write(p []byte, offset int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil {
+		return t.err
+	}
+	end := offset + int64(len(p))
+	if end <= t.written || offset > t.written {
+		return nil
+	}
+	n, err := t.sink.Write(p[t.written-offset:])
+	t.written += int64(n)
+	if err == nil && t.written < end {
+		err = io.ErrShortWrite
+	}
+	t.err = err
+	return err
+}
+
+// teeReadCloser is one attempt's body, reporting what it reads to the shared bodyTee
+type teeReadCloser struct {
+	io.ReadCloser
+	tee    *bodyTee
+	offset int64
+}
+
+func (b *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if teeErr := b.tee.write(p[:n], b.offset); teeErr != nil {
+			return n, teeErr
+		}
+		b.offset += int64(n)
+	}
+	return n, err
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// NewCharsetReader returns a reader that transcodes r from the named charset to UTF-8.
+// Charset names follow the WHATWG encoding labels, e.g. "windows-1251" or "iso-8859-1".
+func NewCharsetReader(r io.Reader, charset string) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q: %w", charset, err)
+	}
+	return transform.NewReader(r, enc.NewDecoder()), nil
+}
+
+// StringAs reads the full response body, interpreting it as the given charset regardless of
+// what the server declared, and returns it transcoded to UTF-8.
+func (r *Response) StringAs(charset string) (body string, err error) {
+	defer func() {
+		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	reader, err := NewCharsetReader(r.Body, charset)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode response body as %s: %w", charset, err)
+	}
+	return string(data), nil
+}
+
+// OverrideCharset replaces the response body with a UTF-8 transcoding of it, interpreting the
+// original bytes as charset, and rewrites the Content-Type charset parameter to utf-8. Only text
+// bodies are transcoded: responses without a textual Content-Type, such as images or archives,
+// are left untouched.
+func (r *Response) OverrideCharset(charset string) error {
+	if r.Response == nil || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !isTextMediaType(mediaType) {
+		return nil
+	}
+
+	reader, err := NewCharsetReader(r.Body, charset)
+	if err != nil {
+		return err
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, r.Body}
+	r.ContentLength = -1
+	r.Header.Del("Content-Length")
+
+	params["charset"] = "utf-8"
+	r.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	return nil
+}
+
+// isTextMediaType reports whether mediaType carries text that a charset applies to.
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
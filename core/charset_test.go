@@ -0,0 +1,60 @@
+package core_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Charset handling", func() {
+	// "Привет" encoded as Windows-1251
+	cp1251 := []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2}
+
+	newResponse := func(body []byte, contentType string) *core.Response {
+		header := http.Header{}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		return &core.Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}}
+	}
+
+	It("should decode a body as a forced Windows-1251 charset", func() {
+		body, err := newResponse(cp1251, "text/plain; charset=utf-8").StringAs("windows-1251")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("Привет"))
+	})
+
+	It("should error on an unknown charset", func() {
+		_, err := newResponse(cp1251, "").StringAs("no-such-charset")
+		Expect(err).To(MatchError(ContainSubstring("unsupported charset")))
+	})
+
+	It("should override the body and Content-Type charset", func() {
+		resp := newResponse(cp1251, "text/html; charset=iso-8859-1")
+		Expect(resp.OverrideCharset("windows-1251")).To(Succeed())
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("Привет"))
+	})
+
+	It("should leave non-text bodies untouched", func() {
+		resp := newResponse(cp1251, "image/png")
+		Expect(resp.OverrideCharset("windows-1251")).To(Succeed())
+		Expect(resp.Header.Get("Content-Type")).To(Equal("image/png"))
+
+		body, err := resp.Bytes()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(cp1251))
+	})
+})
@@ -0,0 +1,92 @@
+package core_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trailer checksum verification", func() {
+	const payload = "streamed download payload"
+
+	var (
+		ts       *httptest.Server
+		checksum string
+	)
+
+	BeforeEach(func() {
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Trailer", "X-Content-SHA256")
+			_, _ = w.Write([]byte(payload))
+			w.(http.Flusher).Flush()
+			w.Header().Set("X-Content-SHA256", checksum)
+		}))
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	fetch := func() *core.Response {
+		resp, err := http.Get(ts.URL)
+		Expect(err).NotTo(HaveOccurred())
+		return &core.Response{Response: resp}
+	}
+
+	It("should accept a stream whose trailer checksum matches", func() {
+		sum := sha256.Sum256([]byte(payload))
+		checksum = hex.EncodeToString(sum[:])
+
+		resp := fetch()
+		defer resp.CloseBody()
+
+		var got bytes.Buffer
+		err := resp.StreamChunks(func(chunk []byte) {
+			got.Write(chunk)
+		}, core.WithTrailerChecksum("X-Content-SHA256"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.String()).To(Equal(payload))
+	})
+
+	It("should return a ChecksumError when the trailer does not match", func() {
+		checksum = hex.EncodeToString(make([]byte, sha256.Size))
+
+		resp := fetch()
+		defer resp.CloseBody()
+
+		err := resp.StreamChunks(func([]byte) {}, core.WithTrailerChecksum("X-Content-SHA256"))
+		var checksumErr *core.ChecksumError
+		Expect(errors.As(err, &checksumErr)).To(BeTrue())
+		Expect(checksumErr.Expected).To(Equal(checksum))
+	})
+
+	It("should verify the checksum while copying with CopyTo", func() {
+		sum := sha256.Sum256([]byte(payload))
+		checksum = hex.EncodeToString(sum[:])
+
+		resp := fetch()
+		defer resp.CloseBody()
+
+		var got bytes.Buffer
+		n, err := resp.CopyTo(&got, core.WithTrailerChecksum("X-Content-SHA256"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(len(payload))))
+		Expect(got.String()).To(Equal(payload))
+	})
+
+	It("should report a missing trailer", func() {
+		resp := fetch()
+		defer resp.CloseBody()
+
+		err := resp.StreamChunks(func([]byte) {}, core.WithTrailerChecksum("X-Other-Checksum"))
+		Expect(err).To(MatchError(ContainSubstring("is missing")))
+	})
+})
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrConcurrencyLimit is returned by a ConcurrencyLimitedTransport in ConcurrencyFailFast mode
+// when all slots are in use.
+var ErrConcurrencyLimit = errors.New("concurrency limit reached")
+
+// ConcurrencyLimitMode selects what a ConcurrencyLimitedTransport does when all slots are in use
+type ConcurrencyLimitMode int
+
+const (
+	// ConcurrencyBlock waits for a free slot until the request context is done
+	ConcurrencyBlock ConcurrencyLimitMode = iota
+	// ConcurrencyFailFast returns ErrConcurrencyLimit immediately
+	ConcurrencyFailFast
+)
+
+// ConcurrencyLimitedTransport wraps a RoundTripper with a semaphore that caps the number of
+// concurrent round trips. A slot is held until the response body is closed, so streamed
+// responses count against the limit while they are being read.
+type ConcurrencyLimitedTransport struct {
+	Base http.RoundTripper
+	Mode ConcurrencyLimitMode
+
+	sem chan struct{}
+}
+
+// NewConcurrencyLimitedTransport returns a transport that allows at most max concurrent round
+// trips through base, blocking callers beyond that. Set Mode to ConcurrencyFailFast to reject
+// them instead. A max of zero or less leaves base unlimited; a nil base uses http.DefaultTransport.
+func NewConcurrencyLimitedTransport(base http.RoundTripper, max int) *ConcurrencyLimitedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &ConcurrencyLimitedTransport{Base: base}
+	if max > 0 {
+		t.sem = make(chan struct{}, max)
+	}
+	return t
+}
+
+// RoundTrip acquires a slot, then delegates to the base transport.
+func (t *ConcurrencyLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.sem == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	if t.Mode == ConcurrencyFailFast {
+		select {
+		case t.sem <- struct{}{}:
+		default:
+			return nil, ErrConcurrencyLimit
+		}
+	} else {
+		select {
+		case t.sem <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { <-t.sem })
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseOnCloseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// InFlight returns the number of round trips currently holding a slot.
+func (t *ConcurrencyLimitedTransport) InFlight() int {
+	return len(t.sem)
+}
+
+// releaseOnCloseBody frees a concurrency slot once the body is closed.
+type releaseOnCloseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnCloseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
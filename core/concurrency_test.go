@@ -0,0 +1,92 @@
+package core_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConcurrencyLimitedTransport", func() {
+	var (
+		current, peak atomic.Int32
+		slowBase      core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		current.Store(0)
+		peak.Store(0)
+		slowBase = func(req *http.Request) (*http.Response, error) {
+			n := current.Add(1)
+			defer current.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}
+	})
+
+	It("should cap concurrent round trips", func() {
+		transport := core.NewConcurrencyLimitedTransport(slowBase, 3)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 12; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				resp, err := transport.RoundTrip(req)
+				Expect(err).NotTo(HaveOccurred())
+				_ = resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		Expect(peak.Load()).To(Equal(int32(3)))
+		Expect(transport.InFlight()).To(BeZero())
+	})
+
+	It("should hold a slot until the response body is closed", func() {
+		transport := core.NewConcurrencyLimitedTransport(slowBase, 1)
+		transport.Mode = core.ConcurrencyFailFast
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		resp, err := transport.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = transport.RoundTrip(req)
+		Expect(err).To(MatchError(core.ErrConcurrencyLimit))
+
+		Expect(resp.Body.Close()).To(Succeed())
+		resp, err = transport.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		_ = resp.Body.Close()
+	})
+
+	It("should stop waiting when the context is done", func() {
+		transport := core.NewConcurrencyLimitedTransport(slowBase, 1)
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		resp, err := transport.RoundTrip(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = transport.RoundTrip(req.WithContext(ctx))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DialContextFunc matches http.Transport.DialContext
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ConnectError reports that a connection to Addr could not be established, as opposed to a
+// server that accepted the connection but was too slow to respond.
+type ConnectError struct {
+	Network string
+	Addr    string
+	// Limit is the connect timeout that was in effect
+	Limit time.Duration
+	Err   error
+}
+
+func (e *ConnectError) Error() string {
+	if e.Timeout() {
+		return fmt.Sprintf("connect to %s timed out after %v: %v", e.Addr, e.Limit, e.Err)
+	}
+	return fmt.Sprintf("connect to %s failed: %v", e.Addr, e.Err)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the connection attempt ran out of time.
+func (e *ConnectError) Timeout() bool {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+}
+
+// Temporary implements net.Error; connection failures are not considered temporary.
+func (e *ConnectError) Temporary() bool {
+	return false
+}
+
+// DialWithConnectTimeout wraps dial so each connection attempt is bounded by timeout and
+// failures are reported as a *ConnectError. A nil dial uses a net.Dialer with the keep-alive
+// settings of http.DefaultTransport. Cancellation of the caller's context is returned unwrapped.
+func DialWithConnectTimeout(dial DialContextFunc, timeout time.Duration) DialContextFunc {
+	if dial == nil {
+		dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialCtx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			dialCtx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		conn, err := dial(dialCtx, network, addr)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, &ConnectError{Network: network, Addr: addr, Limit: timeout, Err: err}
+		}
+		return conn, nil
+	}
+}
@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DialWithConnectTimeout", func() {
+	// blockingDial behaves like a dial to an unroutable address: it never completes on its own
+	blockingDial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, &net.OpError{Op: "dial", Net: network, Err: ctx.Err()}
+	}
+
+	It("should return a timed out ConnectError within the connect timeout", func() {
+		dial := core.DialWithConnectTimeout(blockingDial, 50*time.Millisecond)
+
+		start := time.Now()
+		_, err := dial(context.Background(), "tcp", "10.255.255.1:81")
+		elapsed := time.Since(start)
+
+		var connectErr *core.ConnectError
+		Expect(errors.As(err, &connectErr)).To(BeTrue())
+		Expect(connectErr.Timeout()).To(BeTrue())
+		Expect(connectErr.Addr).To(Equal("10.255.255.1:81"))
+		Expect(connectErr.Limit).To(Equal(50 * time.Millisecond))
+		Expect(elapsed).To(BeNumerically("<", time.Second))
+
+		var netErr net.Error
+		Expect(errors.As(err, &netErr)).To(BeTrue())
+		Expect(netErr.Timeout()).To(BeTrue())
+	})
+
+	It("should report a refused connection as a ConnectError that did not time out", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		_, err = core.DialWithConnectTimeout(nil, time.Second)(context.Background(), "tcp", addr)
+
+		var connectErr *core.ConnectError
+		Expect(errors.As(err, &connectErr)).To(BeTrue())
+		Expect(connectErr.Timeout()).To(BeFalse())
+	})
+
+	It("should return the caller's cancellation unwrapped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := core.DialWithConnectTimeout(blockingDial, time.Second)(ctx, "tcp", "10.255.255.1:81")
+
+		var connectErr *core.ConnectError
+		Expect(errors.As(err, &connectErr)).To(BeFalse())
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	})
+
+	It("should pass through a successful connection", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		conn, err := core.DialWithConnectTimeout(nil, time.Second)(context.Background(), "tcp", listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSHeaders holds the Access-Control-Allow-* and related headers of a CORS or preflight response
+type CORSHeaders struct {
+	AllowOrigin      string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// MaxAge is how long a preflight result may be cached; zero when absent or invalid
+	MaxAge time.Duration
+}
+
+// AllowedMethods returns the methods listed in the Allow header, as sent by OPTIONS and
+// 405 Method Not Allowed responses. Values split across several header lines are combined.
+func (r *Response) AllowedMethods() []string {
+	return splitHeaderList(r.HeaderValues("Allow"))
+}
+
+// CORSHeaders parses the CORS headers of the response, such as a preflight answer to an
+// OPTIONS request. Missing headers leave their fields at the zero value.
+func (r *Response) CORSHeaders() CORSHeaders {
+	headers := r.HeaderMap()
+	cors := CORSHeaders{
+		AllowOrigin:      strings.TrimSpace(headers.Get("Access-Control-Allow-Origin")),
+		AllowMethods:     splitHeaderList(headers.Values("Access-Control-Allow-Methods")),
+		AllowHeaders:     splitHeaderList(headers.Values("Access-Control-Allow-Headers")),
+		ExposeHeaders:    splitHeaderList(headers.Values("Access-Control-Expose-Headers")),
+		AllowCredentials: strings.EqualFold(strings.TrimSpace(headers.Get("Access-Control-Allow-Credentials")), "true"),
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(headers.Get("Access-Control-Max-Age"))); err == nil && seconds > 0 {
+		cors.MaxAge = time.Duration(seconds) * time.Second
+	}
+	return cors
+}
+
+// splitHeaderList splits comma-separated header values into their trimmed, non-empty elements.
+func splitHeaderList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,47 @@
+package core_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CORS headers", func() {
+	It("should parse a preflight response", func() {
+		header := http.Header{}
+		header.Add("Allow", "GET, HEAD")
+		header.Add("Allow", "POST,OPTIONS")
+		header.Set("Access-Control-Allow-Origin", "https://app.example.com")
+		header.Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+		header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		header.Set("Access-Control-Expose-Headers", "X-Request-Id")
+		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Set("Access-Control-Max-Age", "600")
+		resp := &core.Response{Response: &http.Response{StatusCode: http.StatusNoContent, Header: header}}
+
+		Expect(resp.AllowedMethods()).To(Equal([]string{"GET", "HEAD", "POST", "OPTIONS"}))
+		Expect(resp.CORSHeaders()).To(Equal(core.CORSHeaders{
+			AllowOrigin:      "https://app.example.com",
+			AllowMethods:     []string{"GET", "POST", "DELETE"},
+			AllowHeaders:     []string{"Content-Type", "Authorization"},
+			ExposeHeaders:    []string{"X-Request-Id"},
+			AllowCredentials: true,
+			MaxAge:           10 * time.Minute,
+		}))
+	})
+
+	It("should leave missing or invalid headers at their zero values", func() {
+		header := http.Header{}
+		header.Set("Access-Control-Allow-Credentials", "yes")
+		header.Set("Access-Control-Max-Age", "soon")
+		resp := &core.Response{Response: &http.Response{Header: header}}
+
+		Expect(resp.AllowedMethods()).To(BeNil())
+		Expect(resp.CORSHeaders()).To(Equal(core.CORSHeaders{}))
+		Expect((&core.Response{}).CORSHeaders()).To(Equal(core.CORSHeaders{}))
+	})
+})
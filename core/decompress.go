@@ -0,0 +1,259 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DecompressOption configures Response.Decompressed
+type DecompressOption func(*decompressConfig)
+
+type decompressConfig struct {
+	lenient bool
+}
+
+// WithLenientDecompression makes Decompressed fall back to the raw body when the
+// payload claims an encoding but cannot be decoded. The default is strict mode,
+// which surfaces the decoding error.
+func WithLenientDecompression() DecompressOption {
+	return func(c *decompressConfig) {
+		c.lenient = true
+	}
+}
+
+// Decompressed returns a Response whose body is decoded according to its Content-Encoding header.
+// Only needed when compression is negotiated manually; Go's transport already decodes gzip
+// when it set Accept-Encoding itself. Stacked encodings are undone like DecodedBody does, and
+// responses with an encoding that has no decoder are returned as is.
+func (r *Response) Decompressed(opts ...DecompressOption) (*Response, error) {
+	if r.Response == nil || r.Body == nil || r.Uncompressed {
+		return r, nil
+	}
+
+	config := decompressConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	encodings := contentEncodings(r.Header)
+	if len(encodings) == 0 || unsupportedEncoding(encodings, nil) != "" {
+		return r, nil
+	}
+
+	if config.lenient {
+		return r.decompressLenient(encodings)
+	}
+
+	body, err := decodeEncodings(r.Body, encodings, nil, "response")
+	if err != nil {
+		return nil, err
+	}
+	return r.withDecodedBody(body, -1), nil
+}
+
+// newBodyDecoder wraps body in a decoder for the given Content-Encoding. It reports false
+// for encodings it does not handle. "deflate" accepts both zlib-wrapped and raw DEFLATE
+// data, since servers send either.
+func newBodyDecoder(encoding string, body io.Reader) (io.ReadCloser, bool, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, true, err
+		}
+		return zr, true, nil
+	case "deflate":
+		br := bufio.NewReader(body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, true, err
+			}
+			return zr, true, nil
+		}
+		return flate.NewReader(br), true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// ContentDecoder wraps a body encoded with one Content-Encoding in a decoding reader
+type ContentDecoder func(body io.Reader) (io.ReadCloser, error)
+
+// DecodeContentEncoding replaces resp.Body with a reader that undoes every encoding listed in
+// the Content-Encoding header, in reverse order of application, so "deflate, gzip" is gunzipped
+// first. gzip, deflate and encodings registered with RegisterContentDecoder are supported; extra
+// supplies further decoders, such as br, keyed by lowercase name. The encoding headers are removed
+// and Uncompressed is set once the body is decoded. When any listed encoding has no decoder the
+// response is left untouched.
+func DecodeContentEncoding(resp *http.Response, extra map[string]ContentDecoder) error {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+
+	encodings := contentEncodings(resp.Header)
+	if len(encodings) == 0 || unsupportedEncoding(encodings, extra) != "" {
+		return nil
+	}
+
+	body, err := decodeEncodings(resp.Body, encodings, extra, "response")
+	if err != nil {
+		return err
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodeEncodings wraps raw in a decoder per encoding, innermost last, failing on encodings
+// without a decoder. raw is closed if a decoder cannot be created; kind names what raw holds,
+// e.g. "response" or "stream", in errors.
+func decodeEncodings(raw io.ReadCloser, encodings []string, extra map[string]ContentDecoder, kind string) (io.ReadCloser, error) {
+	if encoding := unsupportedEncoding(encodings, extra); encoding != "" {
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+
+	var body io.Reader = raw
+	closers := []io.Closer{raw}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := decodeLayer(encodings[i], body, extra)
+		if err != nil {
+			_ = raw.Close()
+			return nil, fmt.Errorf("failed to decompress %s %s: %w", encodings[i], kind, err)
+		}
+		body = decoder
+		closers = append(closers, decoder)
+	}
+	return &chainedBody{Reader: body, closers: closers}, nil
+}
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[string]ContentDecoder{}
+)
+
+// RegisterContentDecoder makes decoder available for the named Content-Encoding, e.g. "br"
+// backed by a brotli package, wherever responses are decoded. Registered decoders take
+// precedence over the built-in gzip and deflate support.
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	contentDecoders[strings.ToLower(encoding)] = decoder
+}
+
+// lookupContentDecoder finds a decoder in extra or the registry.
+func lookupContentDecoder(encoding string, extra map[string]ContentDecoder) (ContentDecoder, bool) {
+	if decode, ok := extra[encoding]; ok {
+		return decode, true
+	}
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	decode, ok := contentDecoders[encoding]
+	return decode, ok
+}
+
+// contentEncodings lists the encodings named across all Content-Encoding values, skipping identity.
+func contentEncodings(header http.Header) []string {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+	return encodings
+}
+
+// unsupportedEncoding returns the first of encodings that has no decoder, or "".
+func unsupportedEncoding(encodings []string, extra map[string]ContentDecoder) string {
+	for _, encoding := range encodings {
+		if _, ok := lookupContentDecoder(encoding, extra); ok {
+			continue
+		}
+		switch encoding {
+		case "gzip", "x-gzip", "deflate":
+			continue
+		}
+		return encoding
+	}
+	return ""
+}
+
+// decodeLayer decodes one encoding, preferring a supplied or registered decoder over the built-in ones.
+func decodeLayer(encoding string, body io.Reader, extra map[string]ContentDecoder) (io.ReadCloser, error) {
+	if decode, ok := lookupContentDecoder(encoding, extra); ok {
+		return decode(body)
+	}
+	decoder, _, err := newBodyDecoder(encoding, body)
+	return decoder, err
+}
+
+// chainedBody closes every decoder from the outermost inwards, then the raw body.
+type chainedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *chainedBody) Close() error {
+	var firstErr error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if err := b.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// isZlibHeader reports whether b starts a zlib stream (RFC 1950): DEFLATE method and a valid check.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decompressLenient buffers the raw body and decodes it in full, returning the raw bytes on failure.
+func (r *Response) decompressLenient(encodings []string) (*Response, error) {
+	raw, err := r.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compressed response: %w", err)
+	}
+
+	body, err := decodeEncodings(io.NopCloser(bytes.NewReader(raw)), encodings, nil, "response")
+	if err == nil {
+		decoded, readErr := io.ReadAll(body)
+		_ = body.Close()
+		if readErr == nil {
+			return r.withDecodedBody(io.NopCloser(bytes.NewReader(decoded)), int64(len(decoded))), nil
+		}
+	}
+
+	// Fall back to the undecoded payload, keeping the Content-Encoding header so callers can tell.
+	fallback := *r.Response
+	fallback.Body = io.NopCloser(bytes.NewReader(raw))
+	fallback.ContentLength = int64(len(raw))
+	return &Response{Response: &fallback}, nil
+}
+
+// withDecodedBody copies the response with a decoded body and encoding headers removed.
+func (r *Response) withDecodedBody(body io.ReadCloser, length int64) *Response {
+	decoded := *r.Response
+	decoded.Header = r.Header.Clone()
+	decoded.Header.Del("Content-Encoding")
+	decoded.Header.Del("Content-Length")
+	decoded.ContentLength = length
+	decoded.Uncompressed = true
+	decoded.Body = body
+	return &Response{Response: &decoded}
+}
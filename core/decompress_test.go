@@ -0,0 +1,289 @@
+package core_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decompressed", func() {
+	gzipBytes := func(data string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(data))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	newResponse := func(body []byte, encoding string) *core.Response {
+		header := http.Header{}
+		if encoding != "" {
+			header.Set("Content-Encoding", encoding)
+		}
+		return &core.Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}}
+	}
+
+	// corruptGzip has a valid gzip header followed by a damaged deflate stream.
+	corruptGzip := func() []byte {
+		data := gzipBytes("hello, compressed world")
+		corrupt := append([]byte{}, data[:12]...)
+		return append(corrupt, []byte("not deflate data at all")...)
+	}
+
+	It("should decode a gzip body and drop the encoding header", func() {
+		resp, err := newResponse(gzipBytes("hello"), "gzip").Decompressed()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("hello"))
+	})
+
+	It("should undo stacked and registered encodings", func() {
+		core.RegisterContentDecoder("br", func(body io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, body)), nil
+		})
+		encoded := base64.StdEncoding.EncodeToString(gzipBytes("stacked"))
+
+		for _, opts := range [][]core.DecompressOption{nil, {core.WithLenientDecompression()}} {
+			resp, err := newResponse([]byte(encoded), "gzip, br").Decompressed(opts...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+
+			body, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("stacked"))
+		}
+	})
+
+	It("should return responses without a supported encoding unchanged", func() {
+		original := newResponse([]byte("plain"), "")
+		resp, err := original.Decompressed()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(BeIdenticalTo(original))
+	})
+
+	Context("strict mode", func() {
+		It("should surface an invalid gzip header", func() {
+			_, err := newResponse([]byte("definitely not gzip"), "gzip").Decompressed()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to decompress"))
+		})
+
+		It("should surface a corrupt gzip stream on read", func() {
+			resp, err := newResponse(corruptGzip(), "gzip").Decompressed()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = resp.Bytes()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("lenient mode", func() {
+		It("should fall back to the raw body for a corrupt gzip stream", func() {
+			raw := corruptGzip()
+			resp, err := newResponse(raw, "gzip").Decompressed(core.WithLenientDecompression())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip"))
+
+			body, err := resp.Bytes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal(raw))
+		})
+
+		It("should still decode a valid gzip body", func() {
+			resp, err := newResponse(gzipBytes("hello"), "gzip").Decompressed(core.WithLenientDecompression())
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("hello"))
+		})
+	})
+	Context("StreamChunks with WithDecompression", func() {
+		payload := strings.Repeat("streamed log line\n", 500)
+
+		collect := func(resp *core.Response, opts ...core.StreamOption) (string, error) {
+			var out bytes.Buffer
+			err := resp.StreamChunks(func(chunk []byte) {
+				out.Write(chunk)
+			}, opts...)
+			return out.String(), err
+		}
+
+		It("should stream decompressed gzip chunks", func() {
+			compressed := gzipBytes(payload)
+			resp := newResponse(compressed, "gzip")
+
+			out, err := collect(resp, core.WithDecompression(), core.WithBufferSize(256))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(payload))
+			Expect(resp.BytesRead).To(Equal(int64(len(payload))))
+			Expect(resp.BytesRead).To(BeNumerically(">", len(compressed)))
+		})
+
+		It("should stream decompressed deflate chunks, zlib-wrapped or raw", func() {
+			var zbuf bytes.Buffer
+			zw := zlib.NewWriter(&zbuf)
+			_, _ = zw.Write([]byte(payload))
+			Expect(zw.Close()).To(Succeed())
+
+			out, err := collect(newResponse(zbuf.Bytes(), "deflate"), core.WithDecompression())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(payload))
+
+			var fbuf bytes.Buffer
+			fw, _ := flate.NewWriter(&fbuf, flate.DefaultCompression)
+			_, _ = fw.Write([]byte(payload))
+			Expect(fw.Close()).To(Succeed())
+
+			resp := newResponse(fbuf.Bytes(), "deflate")
+			err = resp.StreamChunksWithContext(context.Background(), func([]byte) {}, core.WithDecompression())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.BytesRead).To(Equal(int64(len(payload))))
+		})
+
+		It("should stream bodies with stacked encodings", func() {
+			var zbuf bytes.Buffer
+			zw := zlib.NewWriter(&zbuf)
+			_, _ = zw.Write([]byte(payload))
+			Expect(zw.Close()).To(Succeed())
+
+			out, err := collect(newResponse(gzipBytes(zbuf.String()), "deflate, gzip"), core.WithDecompression())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(payload))
+		})
+
+		It("should leave chunks compressed without the option", func() {
+			compressed := gzipBytes(payload)
+			out, err := collect(newResponse(compressed, "gzip"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect([]byte(out)).To(Equal(compressed))
+		})
+
+		It("should error on an invalid gzip stream", func() {
+			_, err := collect(newResponse([]byte("not gzip"), "gzip"), core.WithDecompression())
+			Expect(err).To(MatchError(ContainSubstring("failed to decompress gzip stream")))
+		})
+	})
+
+	Context("DecodedBody", func() {
+		zlibBytes := func(data []byte) []byte {
+			var buf bytes.Buffer
+			zw := zlib.NewWriter(&buf)
+			_, _ = zw.Write(data)
+			Expect(zw.Close()).To(Succeed())
+			return buf.Bytes()
+		}
+
+		readDecoded := func(resp *core.Response) (string, error) {
+			body, err := resp.DecodedBody()
+			if err != nil {
+				return "", err
+			}
+			defer body.Close()
+			data, err := io.ReadAll(body)
+			return string(data), err
+		}
+
+		It("should decode gzip and deflate bodies", func() {
+			out, err := readDecoded(newResponse(gzipBytes("hello"), "gzip"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("hello"))
+
+			out, err = readDecoded(newResponse(zlibBytes([]byte("hello")), "deflate"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("hello"))
+		})
+
+		It("should undo stacked encodings in reverse order", func() {
+			encoded := gzipBytes(string(zlibBytes([]byte("stacked"))))
+			out, err := readDecoded(newResponse(encoded, "deflate, identity, gzip"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("stacked"))
+		})
+
+		It("should return identity and unencoded bodies as is", func() {
+			out, err := readDecoded(newResponse([]byte("plain"), "identity"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("plain"))
+
+			out, err = readDecoded(newResponse([]byte("plain"), ""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("plain"))
+		})
+
+		It("should error clearly on an unknown encoding", func() {
+			_, err := readDecoded(newResponse([]byte("data"), "gzip, compress"))
+			Expect(err).To(MatchError(`unsupported Content-Encoding "compress"`))
+		})
+
+		It("should use a registered decoder for br", func() {
+			// Stands in for a brotli package, which is not a dependency of this module
+			core.RegisterContentDecoder("br", func(body io.Reader) (io.ReadCloser, error) {
+				return io.NopCloser(base64.NewDecoder(base64.StdEncoding, body)), nil
+			})
+
+			encoded := base64.StdEncoding.EncodeToString([]byte("brotli body"))
+			out, err := readDecoded(newResponse([]byte(encoded), "br"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("brotli body"))
+		})
+
+		It("should not decode a body the transport already decompressed", func() {
+			resp := newResponse([]byte("already plain"), "")
+			resp.Uncompressed = true
+			out, err := readDecoded(resp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("already plain"))
+		})
+
+		It("should route JSON, Bytes and String through the decoder when opted in", func() {
+			var v map[string]int
+			Expect(newResponse(gzipBytes(`{"n":1}`), "gzip").WithContentDecoding().JSON(&v)).To(Succeed())
+			Expect(v).To(HaveKeyWithValue("n", 1))
+
+			out, err := newResponse(gzipBytes("text"), "gzip").WithContentDecoding().String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("text"))
+
+			raw, err := newResponse(gzipBytes("text"), "gzip").Bytes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(Equal(gzipBytes("text")))
+		})
+
+		It("should decode a buffered response repeatedly when opted in", func() {
+			header := http.Header{"Content-Encoding": {"gzip"}}
+			resp := core.NewBufferedResponse(&http.Response{StatusCode: 200, Header: header}, gzipBytes("<a>1</a>")).
+				WithContentDecoding()
+
+			var v struct {
+				XMLName struct{} `xml:"a"`
+				Value   int      `xml:",chardata"`
+			}
+			Expect(resp.XML(&v)).To(Succeed())
+			Expect(v.Value).To(Equal(1))
+
+			out, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("<a>1</a>"))
+		})
+	})
+})
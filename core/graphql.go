@@ -0,0 +1,73 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GraphQLLocation is a position in the GraphQL query document.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLErrorDetail is one entry of the "errors" array of a GraphQL response.
+type GraphQLErrorDetail struct {
+	Message   string            `json:"message"`
+	Locations []GraphQLLocation `json:"locations,omitempty"`
+	// Path holds the field names and list indices leading to the failed field
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLError is returned by Response.GraphQL when the response carries errors. Any data sent
+// alongside them, a partial result, has already been decoded.
+type GraphQLError struct {
+	StatusCode int
+	Errors     []GraphQLErrorDetail
+}
+
+func (e *GraphQLError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, detail := range e.Errors {
+		messages[i] = detail.Message
+		if len(detail.Path) > 0 {
+			path := make([]string, len(detail.Path))
+			for j, segment := range detail.Path {
+				path[j] = fmt.Sprint(segment)
+			}
+			messages[i] += " (path " + strings.Join(path, ".") + ")"
+		}
+	}
+	return fmt.Sprintf("graphql: %s", strings.Join(messages, "; "))
+}
+
+// GraphQL decodes the "data" field of a GraphQL response into data, which may be nil to skip it,
+// and returns a *GraphQLError when the "errors" array is not empty. Error statuses without a
+// GraphQL errors array are reported as plain errors. The body is closed afterward.
+func (r *Response) GraphQL(data interface{}) error {
+	var envelope struct {
+		Data   json.RawMessage      `json:"data"`
+		Errors []GraphQLErrorDetail `json:"errors"`
+	}
+	if err := r.JSON(&envelope); err != nil {
+		if !r.IsSuccess() {
+			return fmt.Errorf("graphql request failed with status %d: %w", r.StatusCode, err)
+		}
+		return fmt.Errorf("failed to decode GraphQL response: %w", err)
+	}
+
+	if data != nil && len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+		if err := json.Unmarshal(envelope.Data, data); err != nil {
+			return fmt.Errorf("failed to decode GraphQL data: %w", err)
+		}
+	}
+	if len(envelope.Errors) > 0 {
+		return &GraphQLError{StatusCode: r.StatusCode, Errors: envelope.Errors}
+	}
+	if !r.IsSuccess() {
+		return fmt.Errorf("graphql request failed with status %d", r.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+)
+
+// HeaderMap is a read-only, case-insensitive view of a response's headers. Unlike indexing
+// http.Header directly, lookups also find keys that were stored without canonicalization.
+type HeaderMap struct {
+	header http.Header
+}
+
+// HeaderMap returns a case-insensitive accessor for the response headers.
+func (r *Response) HeaderMap() HeaderMap {
+	if r.Response == nil {
+		return HeaderMap{}
+	}
+	return HeaderMap{header: r.Header}
+}
+
+// HeaderValues returns every value of the named header, such as all Set-Cookie lines,
+// where Header.Get only returns the first. The key is matched case-insensitively.
+func (r *Response) HeaderValues(key string) []string {
+	return r.HeaderMap().Values(key)
+}
+
+// Get returns the first value of the named header, or "" if it is not present.
+func (h HeaderMap) Get(key string) string {
+	if values := h.Values(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values returns a copy of all values of the named header, or nil if it is not present.
+// Values stored under differently cased keys are combined.
+func (h HeaderMap) Values(key string) []string {
+	var values []string
+	for k, v := range h.header {
+		if strings.EqualFold(k, key) {
+			values = append(values, v...)
+		}
+	}
+	return values
+}
+
+// Has reports whether the named header is present.
+func (h HeaderMap) Has(key string) bool {
+	for k := range h.header {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns a copy of the headers keyed by lowercase name, with the values of keys that
+// differ only in case combined.
+func (h HeaderMap) Map() map[string][]string {
+	m := make(map[string][]string, len(h.header))
+	for k, v := range h.header {
+		lower := strings.ToLower(k)
+		m[lower] = append(m[lower], v...)
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response headers", func() {
+	var resp *core.Response
+
+	BeforeEach(func() {
+		header := http.Header{}
+		header.Add("Set-Cookie", "session=abc")
+		header.Add("Set-Cookie", "theme=dark")
+		header.Set("Content-Type", "application/json")
+		// Stored without canonicalization, as some transports and tests do
+		header["x-request-id"] = []string{"req-1"}
+		resp = &core.Response{Response: &http.Response{Header: header}}
+	})
+
+	It("should return every value of a multi-valued header", func() {
+		Expect(resp.HeaderValues("Set-Cookie")).To(Equal([]string{"session=abc", "theme=dark"}))
+		Expect(resp.HeaderValues("set-cookie")).To(Equal([]string{"session=abc", "theme=dark"}))
+	})
+
+	It("should look up headers case-insensitively", func() {
+		headers := resp.HeaderMap()
+		Expect(headers.Get("CONTENT-TYPE")).To(Equal("application/json"))
+		Expect(headers.Get("X-Request-Id")).To(Equal("req-1"))
+		Expect(resp.Header.Get("X-Request-Id")).To(BeEmpty())
+		Expect(headers.Has("x-REQUEST-id")).To(BeTrue())
+		Expect(headers.Has("Missing")).To(BeFalse())
+		Expect(headers.Get("Missing")).To(BeEmpty())
+		Expect(headers.Values("Missing")).To(BeNil())
+	})
+
+	It("should copy the headers into a map keyed by lowercase name", func() {
+		m := resp.HeaderMap().Map()
+		Expect(m).To(HaveKeyWithValue("set-cookie", []string{"session=abc", "theme=dark"}))
+		Expect(m).To(HaveKeyWithValue("x-request-id", []string{"req-1"}))
+
+		m["set-cookie"][0] = "changed"
+		Expect(resp.HeaderValues("Set-Cookie")[0]).To(Equal("session=abc"))
+	})
+
+	It("should handle a response without headers", func() {
+		empty := &core.Response{}
+		Expect(empty.HeaderValues("Set-Cookie")).To(BeNil())
+		Expect(empty.HeaderMap().Map()).To(BeEmpty())
+	})
+})
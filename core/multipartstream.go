@@ -0,0 +1,159 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sort"
+	"sync"
+)
+
+// ErrMultipartStreamNotReplayable is returned when a streamed multipart body is needed again,
+// for a retry or redirect, but one of its file readers cannot seek back to its start.
+var ErrMultipartStreamNotReplayable = errors.New("multipart stream cannot be replayed: file reader is not seekable")
+
+// FileReader is a file part of a streamed multipart/form-data body.
+type FileReader struct {
+	// Name is the file name sent in the part's Content-Disposition.
+	Name   string
+	Reader io.Reader
+}
+
+// WithMultipartStream constructs a multipart/form-data body from fields and files that is
+// encoded while it is sent, so files are never held in memory. The length is unknown and the
+// body is sent with chunked encoding. Files whose Reader implements io.Seeker are rewound to
+// the offset they had when this was called, keeping the request retryable; when any reader
+// cannot seek, a second attempt fails with ErrMultipartStreamNotReplayable.
+func (r *Request) WithMultipartStream(fields map[string]string, files map[string]FileReader) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	stream, err := newMultipartStream(fields, files)
+	if err != nil {
+		r.buildErr = err
+		return r
+	}
+
+	r.WithBodyGetter(stream.open)
+	r.isMultipart = true
+	r.WithHeader("Content-Type", stream.contentType)
+
+	return r
+}
+
+// multipartStream encodes a multipart/form-data body into a pipe on every open.
+type multipartStream struct {
+	fields     map[string]string
+	files      map[string]FileReader
+	fieldNames []string
+	fileFields []string
+	// offsets holds the starting offset of every file; nil when any file cannot seek
+	offsets     map[string]int64
+	boundary    string
+	contentType string
+
+	mu     sync.Mutex
+	opened bool
+	prev   *io.PipeReader
+	done   chan struct{}
+}
+
+func newMultipartStream(fields map[string]string, files map[string]FileReader) (*multipartStream, error) {
+	// Every attempt must reuse the boundary announced in the Content-Type header
+	boundaryWriter := multipart.NewWriter(io.Discard)
+	s := &multipartStream{
+		fields:      fields,
+		files:       files,
+		offsets:     make(map[string]int64, len(files)),
+		boundary:    boundaryWriter.Boundary(),
+		contentType: boundaryWriter.FormDataContentType(),
+	}
+
+	for name := range fields {
+		s.fieldNames = append(s.fieldNames, name)
+	}
+	sort.Strings(s.fieldNames)
+
+	for field, file := range files {
+		if file.Reader == nil {
+			return nil, fmt.Errorf("file reader for field %s is nil", field)
+		}
+		s.fileFields = append(s.fileFields, field)
+		if s.offsets == nil {
+			continue
+		}
+		seeker, ok := file.Reader.(io.Seeker)
+		if !ok {
+			s.offsets = nil
+			continue
+		}
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get offset of file %s: %w", file.Name, err)
+		}
+		s.offsets[field] = offset
+	}
+	sort.Strings(s.fileFields)
+
+	return s, nil
+}
+
+// open starts encoding the body into a new pipe. Reopening closes the previous body and waits
+// for its writer before rewinding the files, so attempts never read a file concurrently.
+func (s *multipartStream) open() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opened {
+		if s.offsets == nil {
+			return nil, ErrMultipartStreamNotReplayable
+		}
+		_ = s.prev.Close()
+		<-s.done
+		for field, offset := range s.offsets {
+			if _, err := s.files[field].Reader.(io.Seeker).Seek(offset, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind file %s: %w", s.files[field].Name, err)
+			}
+		}
+	}
+	s.opened = true
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = pw.CloseWithError(s.write(pw))
+	}()
+	s.prev, s.done = pr, done
+
+	return pr, nil
+}
+
+// write encodes the fields, then the files, in name order.
+func (s *multipartStream) write(w io.Writer) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(s.boundary); err != nil {
+		return err
+	}
+
+	for _, name := range s.fieldNames {
+		if err := writer.WriteField(name, s.fields[name]); err != nil {
+			return fmt.Errorf("failed to write form field %s: %w", name, err)
+		}
+	}
+
+	for _, field := range s.fileFields {
+		file := s.files[field]
+		part, err := writer.CreateFormFile(field, file.Name)
+		if err != nil {
+			return fmt.Errorf("failed to create form file for field %s: %w", field, err)
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return fmt.Errorf("failed to copy file %s: %w", file.Name, err)
+		}
+	}
+
+	return writer.Close()
+}
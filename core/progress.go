@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultProgressInterval is the longest SaveToFileWithProgress waits between progress reports
+	DefaultProgressInterval = 100 * time.Millisecond
+	// DefaultProgressBytes is the number of bytes after which SaveToFileWithProgress reports progress
+	DefaultProgressBytes = 1 << 20
+)
+
+// SaveOption configures Response.SaveToFileWithProgress
+type SaveOption func(*saveConfig)
+
+type saveConfig struct {
+	keepPartial bool
+	interval    time.Duration
+	step        int64
+}
+
+// WithKeepPartialFile leaves the partially written file in place when a download fails, e.g. to
+// resume it with a Range request. By default the partial file is removed.
+func WithKeepPartialFile() SaveOption {
+	return func(c *saveConfig) {
+		c.keepPartial = true
+	}
+}
+
+// WithProgressThrottle reports progress once interval has passed or step bytes were written
+// since the last report, whichever comes first. A zero value disables that trigger; when both
+// are zero, progress is reported after every write.
+func WithProgressThrottle(interval time.Duration, step int64) SaveOption {
+	return func(c *saveConfig) {
+		c.interval = interval
+		c.step = step
+	}
+}
+
+// SaveToFileWithProgress writes the response body to a file at the given path like SaveToFile,
+// calling onProgress with the bytes written so far and the total from Content-Length, or -1 when
+// the length is unknown. Reports are throttled to DefaultProgressInterval or DefaultProgressBytes
+// unless WithProgressThrottle says otherwise, and a final report is always made once the body is
+// saved. If the download fails the partial file is removed unless WithKeepPartialFile is given.
+func (r *Response) SaveToFileWithProgress(filePath string, onProgress func(bytesWritten, total int64), opts ...SaveOption) (err error) {
+	defer func() {
+		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	config := saveConfig{interval: DefaultProgressInterval, step: DefaultProgressBytes}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+
+	total := r.ContentLength
+	if total < 0 {
+		total = -1
+	}
+	progress := &progressWriter{
+		w:          f,
+		total:      total,
+		onProgress: onProgress,
+		config:     config,
+		lastReport: time.Now(),
+	}
+
+	_, err = io.Copy(progress, r.Body)
+	if err != nil {
+		err = fmt.Errorf("failed to save response to file %s: %w", filePath, err)
+	}
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to close file %s: %w", filePath, closeErr)
+	}
+	if err != nil {
+		if !config.keepPartial {
+			_ = os.Remove(filePath)
+		}
+		return err
+	}
+
+	progress.report()
+	return nil
+}
+
+// progressWriter counts the bytes written through it and reports them, throttled.
+type progressWriter struct {
+	w          io.Writer
+	total      int64
+	onProgress func(bytesWritten, total int64)
+	config     saveConfig
+
+	written      int64
+	reported     int64
+	lastReport   time.Time
+	reportedOnce bool
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+
+	dueBySize := p.config.step > 0 && p.written-p.reported >= p.config.step
+	dueByTime := p.config.interval > 0 && time.Since(p.lastReport) >= p.config.interval
+	unthrottled := p.config.step <= 0 && p.config.interval <= 0
+	if n > 0 && (dueBySize || dueByTime || unthrottled) {
+		p.report()
+	}
+	return n, err
+}
+
+// report calls onProgress unless the current count was already reported.
+func (p *progressWriter) report() {
+	if p.onProgress == nil || (p.reportedOnce && p.reported == p.written) {
+		return
+	}
+	p.onProgress(p.written, p.total)
+	p.reported = p.written
+	p.lastReport = time.Now()
+	p.reportedOnce = true
+}
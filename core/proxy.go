@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// ProxyRotation selects how a RotatingProxy picks a proxy for each request
+type ProxyRotation int
+
+const (
+	// ProxyRoundRobin cycles through the proxies in order
+	ProxyRoundRobin ProxyRotation = iota
+	// ProxyRandom picks a proxy at random for each request
+	ProxyRandom
+)
+
+// RotatingProxyOptions configures a RotatingProxy
+type RotatingProxyOptions struct {
+	// Rotation controls the selection order
+	Rotation ProxyRotation
+	// SkipInvalid drops entries that fail to parse. When false, a request that lands
+	// on an invalid entry fails with its parse error.
+	SkipInvalid bool
+}
+
+// proxyEntry is a parsed proxy URL or the error from parsing it
+type proxyEntry struct {
+	url *url.URL
+	err error
+}
+
+// RotatingProxy selects a proxy per request from a list, for use as http.Transport.Proxy.
+type RotatingProxy struct {
+	entries  []proxyEntry
+	rotation ProxyRotation
+	next     atomic.Uint64
+}
+
+// NewRotatingProxy parses proxyURLs into a RotatingProxy.
+// It returns an error if no usable proxy remains.
+func NewRotatingProxy(proxyURLs []string, options RotatingProxyOptions) (*RotatingProxy, error) {
+	p := &RotatingProxy{rotation: options.Rotation}
+	for _, raw := range proxyURLs {
+		u, err := parseProxyURL(raw)
+		if err != nil && options.SkipInvalid {
+			continue
+		}
+		p.entries = append(p.entries, proxyEntry{url: u, err: err})
+	}
+	if len(p.entries) == 0 {
+		return nil, fmt.Errorf("no valid proxy URLs provided")
+	}
+	return p, nil
+}
+
+// Proxy returns the proxy to use for req. It matches the signature of http.Transport.Proxy.
+func (p *RotatingProxy) Proxy(_ *http.Request) (*url.URL, error) {
+	var idx int
+	if p.rotation == ProxyRandom {
+		idx = rand.Intn(len(p.entries))
+	} else {
+		idx = int((p.next.Add(1) - 1) % uint64(len(p.entries)))
+	}
+	entry := p.entries[idx]
+	return entry.url, entry.err
+}
+
+// parseProxyURL parses and validates a proxy URL, requiring a scheme and host.
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme and host are required", raw)
+	}
+	return u, nil
+}
+
+// WithProxy sends this request through the proxy at proxyURL instead of the client's proxy.
+// http, https and socks5 proxies are supported; an invalid URL is reported when the request
+// is built. The built *http.Request carries the proxy in its context, see ProxyFromContext.
+// The client sends such requests through a copy of its transport kept per proxy, so each proxy
+// has a separate connection pool and the first request through it always dials.
+func (r *Request) WithProxy(proxyURL string) *Request {
+	u, err := parseProxyURL(proxyURL)
+	if err == nil && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		err = fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", proxyURL, u.Scheme)
+	}
+	if err != nil {
+		r.buildErr = err
+		return r
+	}
+	r.proxy = u
+	return r
+}
+
+type proxyContextKey struct{}
+
+// WithProxyContext attaches a per-request proxy to ctx for transports further down the chain.
+func WithProxyContext(ctx context.Context, proxyURL *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxyURL)
+}
+
+// ProxyFromContext returns the per-request proxy attached to ctx, or nil.
+func ProxyFromContext(ctx context.Context) *url.URL {
+	proxyURL, _ := ctx.Value(proxyContextKey{}).(*url.URL)
+	return proxyURL
+}
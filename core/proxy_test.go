@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RotatingProxy", func() {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	It("should alternate proxies in round-robin order", func() {
+		proxy, err := core.NewRotatingProxy([]string{"http://proxy-a:8080", "http://proxy-b:8080"}, core.RotatingProxyOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var hosts []string
+		for i := 0; i < 4; i++ {
+			u, err := proxy.Proxy(req)
+			Expect(err).NotTo(HaveOccurred())
+			hosts = append(hosts, u.Host)
+		}
+		Expect(hosts).To(Equal([]string{"proxy-a:8080", "proxy-b:8080", "proxy-a:8080", "proxy-b:8080"}))
+	})
+
+	It("should pick only from the configured proxies in random mode", func() {
+		proxy, err := core.NewRotatingProxy([]string{"http://proxy-a:8080", "http://proxy-b:8080"},
+			core.RotatingProxyOptions{Rotation: core.ProxyRandom})
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 20; i++ {
+			u, err := proxy.Proxy(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.Host).To(BeElementOf("proxy-a:8080", "proxy-b:8080"))
+		}
+	})
+
+	It("should skip invalid proxies when SkipInvalid is set", func() {
+		proxy, err := core.NewRotatingProxy([]string{"http://proxy-a:8080", "not a proxy"},
+			core.RotatingProxyOptions{SkipInvalid: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			u, err := proxy.Proxy(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.Host).To(Equal("proxy-a:8080"))
+		}
+	})
+
+	It("should surface an error when a request lands on an invalid proxy", func() {
+		proxy, err := core.NewRotatingProxy([]string{"http://proxy-a:8080", "not a proxy"}, core.RotatingProxyOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = proxy.Proxy(req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = proxy.Proxy(req)
+		Expect(err).To(MatchError(ContainSubstring("invalid proxy URL")))
+	})
+
+	It("should fail when no usable proxy remains", func() {
+		_, err := core.NewRotatingProxy([]string{"bad"}, core.RotatingProxyOptions{SkipInvalid: true})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -2,16 +2,27 @@ package core
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"text/template"
+
+	"golang.org/x/net/http/httpguts"
 )
 
 // SizeConfig holds all size-related configuration parameters
@@ -76,8 +87,36 @@ type Request struct {
 	ctx context.Context
 	// Metadata storage for request-specific data
 	metadata map[string]interface{}
+	// contentTypeMode controls Content-Type detection or suppression for the body
+	contentTypeMode contentTypeMode
+
+	bodyChan <-chan []byte
+	// bodyAbort lets the producer of bodyChan fail the body instead of ending it
+	bodyAbort <-chan error
+	// bodyGetter produces a fresh body for every attempt, in place of body
+	bodyGetter func() (io.ReadCloser, error)
+	// canonicalQuery sorts values within each key and encodes spaces as %20
+	canonicalQuery bool
+	// host overrides the Host sent on the wire; the URL host is still used for dialing
+	host string
+	// bodyTee receives a copy of the body as it is sent
+	bodyTee io.Writer
+	// proxy overrides the client's proxy for this request
+	proxy *url.URL
 }
 
+// contentTypeMode selects how BuildHTTPRequest treats the Content-Type header
+type contentTypeMode int
+
+const (
+	// contentTypeDefault leaves the Content-Type header as configured
+	contentTypeDefault contentTypeMode = iota
+	// contentTypeDetect sniffs the body with http.DetectContentType when no Content-Type is set
+	contentTypeDetect
+	// contentTypeSuppress removes any Content-Type header
+	contentTypeSuppress
+)
+
 var byteBufferPool = sync.Pool{
 	New: func() interface{} {
 		return bytes.NewBuffer(make([]byte, 0, 4096))
@@ -145,6 +184,15 @@ func (r *Request) Clone() *Request {
 		isMultipart: r.isMultipart,
 		buildErr:    r.buildErr,
 		ctx:         r.ctx, // Share the same context
+
+		contentTypeMode: r.contentTypeMode,
+		bodyChan:        r.bodyChan, // Channels can only be drained once; the clone shares it
+		bodyAbort:       r.bodyAbort,
+		host:            r.host,
+		bodyGetter:      r.bodyGetter,
+		canonicalQuery:  r.canonicalQuery,
+		bodyTee:         r.bodyTee,
+		proxy:           r.proxy,
 	}
 
 	// Copy headers
@@ -208,6 +256,91 @@ func (r *Request) WithHeaders(headers map[string]string) *Request {
 	return r
 }
 
+// WithBasicAuth sets the Authorization header to HTTP Basic credentials, encoded exactly as
+// http.Request.SetBasicAuth does. It replaces any Authorization header set before.
+func (r *Request) WithBasicAuth(username, password string) *Request {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return r.WithHeader("Authorization", "Basic "+credentials)
+}
+
+// WithBearerToken sets the Authorization header to a Bearer token. It replaces any
+// Authorization header set before, so calling it again swaps the token.
+func (r *Request) WithBearerToken(token string) *Request {
+	return r.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHost overrides the Host header sent with the request, e.g. to reach a virtual host
+// through an IP address. The connection is still made to the host in the URL. Setting
+// "Host" with WithHeader has no effect because net/http reads it from http.Request.Host.
+func (r *Request) WithHost(host string) *Request {
+	r.host = host
+	return r
+}
+
+// WithCanonicalQuery makes the query string canonical for request signing: parameters are
+// sorted by key and then by value, and encoded per RFC 3986 with spaces as %20. See
+// QueryString for the string it produces.
+func (r *Request) WithCanonicalQuery() *Request {
+	r.canonicalQuery = true
+	return r
+}
+
+// QueryString returns the encoded query string of the request itself, combining any query
+// in the URL with the parameters added through the With* methods. Keys are always sorted;
+// see WithCanonicalQuery for fully canonical output. Parameters a client adds when sending,
+// from WithDefaultQueryParams or the query of WithBaseURL, are not included: they are
+// appended after it. To sign the query as sent, read URL.RawQuery in a RequestEditorFunc,
+// which runs once those defaults are applied.
+func (r *Request) QueryString() (string, error) {
+	if r.buildErr != nil {
+		return "", r.buildErr
+	}
+	parsedURL, err := url.Parse(r.url)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	return r.encodeQuery(parsedURL), nil
+}
+
+// encodeQuery merges the URL's query with the request's parameters and encodes the result.
+func (r *Request) encodeQuery(u *url.URL) string {
+	q := u.Query()
+	for key, values := range r.queryParams {
+		for _, v := range values {
+			q.Add(key, v)
+		}
+	}
+	if !r.canonicalQuery {
+		return q.Encode()
+	}
+
+	keys := make([]string, 0, len(q))
+	for key := range q {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		values := append([]string(nil), q[key]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(canonicalEscape(key))
+			b.WriteByte('=')
+			b.WriteString(canonicalEscape(v))
+		}
+	}
+	return b.String()
+}
+
+// canonicalEscape percent-encodes s per RFC 3986, leaving only unreserved characters as is.
+func canonicalEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
 // WithQueryParam adds a query parameter to the Request.
 func (r *Request) WithQueryParam(key, value string) *Request {
 	r.queryParams.Add(key, value)
@@ -215,6 +348,24 @@ func (r *Request) WithQueryParam(key, value string) *Request {
 	return r
 }
 
+// WithQueryParamInt adds an integer query parameter to the Request.
+func (r *Request) WithQueryParamInt(key string, v int) *Request {
+	return r.WithQueryParam(key, strconv.Itoa(v))
+}
+
+// WithQueryParamBool adds a boolean query parameter ("true" or "false") to the Request.
+func (r *Request) WithQueryParamBool(key string, v bool) *Request {
+	return r.WithQueryParam(key, strconv.FormatBool(v))
+}
+
+// WithQueryParamSlice adds the key once for every value, e.g. ?tag=a&tag=b.
+func (r *Request) WithQueryParamSlice(key string, vs ...string) *Request {
+	for _, v := range vs {
+		r.WithQueryParam(key, v)
+	}
+	return r
+}
+
 // WithQueryParams adds multiple query parameters to the Request.
 func (r *Request) WithQueryParams(params map[string]string) *Request {
 	for k, v := range params {
@@ -223,19 +374,145 @@ func (r *Request) WithQueryParams(params map[string]string) *Request {
 	return r
 }
 
+// allowsBody reports whether the request method may carry a body.
+func (r *Request) allowsBody() bool {
+	return r.method != http.MethodGet && r.method != http.MethodHead
+}
+
+// bodyNotAllowedError is the error reported for a body on a GET or HEAD request.
+func (r *Request) bodyNotAllowedError() error {
+	return fmt.Errorf("http method %s does not allow a body", r.method)
+}
+
+// rejectBody records a build error and returns true if the method does not allow a body.
+func (r *Request) rejectBody() bool {
+	if r.allowsBody() {
+		return false
+	}
+	r.buildErr = r.bodyNotAllowedError()
+	return true
+}
+
 // WithBody sets the request body from a byte slice.
 func (r *Request) WithBody(body []byte) *Request {
-	if r.method == http.MethodGet || r.method == http.MethodHead {
-		r.buildErr = fmt.Errorf("http method %s does not allow a body", r.method)
+	if r.rejectBody() {
 		return r
 	}
 
-	r.body = bytes.NewReader(body)
-	r.bodySize = int64(len(body))
+	r.setBody(body)
 
 	return r
 }
 
+// setBody replaces every body source with data, so whichever body setter runs last wins.
+func (r *Request) setBody(data []byte) {
+	r.body = bytes.NewReader(data)
+	r.bodySize = int64(len(data))
+	r.bodyChan = nil
+	r.bodyAbort = nil
+	r.bodyGetter = nil
+	r.isMultipart = false
+}
+
+// ErrBodyStreamAborted is returned when a channel-fed request body is abandoned, either because
+// the request context ended before the channel was closed or because its producer aborted it.
+var ErrBodyStreamAborted = errors.New("request body stream aborted")
+
+// WithBodyChannel streams the request body from ch using chunked transfer encoding. Each
+// chunk received is written to the wire in order, and the body ends when ch is closed.
+// If the request context is done first, the body fails with ErrBodyStreamAborted so the
+// server never sees a truncated body as complete. The channel is consumed once the
+// request is built, so the request cannot be replayed. Use WithBodyChannelAbort to let the
+// producer fail the body as well.
+func (r *Request) WithBodyChannel(ch <-chan []byte) *Request {
+	return r.WithBodyChannelAbort(ch, nil)
+}
+
+// WithBodyChannelAbort is like WithBodyChannel, but lets the producer abort the body: an error
+// received on abort fails the body with ErrBodyStreamAborted wrapping it, so the request fails
+// rather than sending what was written so far as complete. To abort, send on abort instead of
+// closing ch. Closing abort without sending only stops watching it.
+func (r *Request) WithBodyChannelAbort(ch <-chan []byte, abort <-chan error) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	r.body = nil
+	r.bodySize = 0
+	r.bodyChan = ch
+	r.bodyAbort = abort
+	r.bodyGetter = nil
+	r.isMultipart = false
+
+	return r
+}
+
+// WithBodyGetter sets a body source that is called for every attempt, making bodies that
+// cannot be seeked or buffered retryable: the built request uses getBody for its initial body
+// and as http.Request.GetBody, which retries and redirects call to obtain a fresh copy.
+// The body length is unknown, so it is sent with chunked encoding.
+func (r *Request) WithBodyGetter(getBody func() (io.ReadCloser, error)) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	r.body = nil
+	r.bodySize = 0
+	r.bodyChan = nil
+	r.bodyAbort = nil
+	r.bodyGetter = getBody
+	r.isMultipart = false
+
+	return r
+}
+
+// pipeBodyChannel copies chunks from ch into a pipe until ch is closed, an error arrives on
+// abort or ctx is done.
+func pipeBodyChannel(ctx context.Context, ch <-chan []byte, abort <-chan error) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			select {
+			case chunk, ok := <-ch:
+				if !ok {
+					_ = pw.Close()
+					return
+				}
+				if _, err := pw.Write(chunk); err != nil {
+					return
+				}
+			case err, ok := <-abort:
+				if !ok {
+					abort = nil
+					continue
+				}
+				if err == nil {
+					_ = pw.CloseWithError(ErrBodyStreamAborted)
+				} else {
+					_ = pw.CloseWithError(fmt.Errorf("%w: %w", ErrBodyStreamAborted, err))
+				}
+				return
+			case <-ctx.Done():
+				_ = pw.CloseWithError(fmt.Errorf("%w: %w", ErrBodyStreamAborted, context.Cause(ctx)))
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// WithAutoContentType controls the Content-Type of raw bodies. When enabled, the body is
+// sniffed with http.DetectContentType if no Content-Type header was set. When disabled,
+// any Content-Type header is removed so the request is sent without one.
+func (r *Request) WithAutoContentType(enabled bool) *Request {
+	if enabled {
+		r.contentTypeMode = contentTypeDetect
+	} else {
+		r.contentTypeMode = contentTypeSuppress
+	}
+	return r
+}
+
 // WithChunkedEncoding sets the Transfer-Encoding header to chunk.
 func (r *Request) WithChunkedEncoding() *Request {
 	r.headers["Transfer-Encoding"] = "chunked"
@@ -246,20 +523,137 @@ func (r *Request) WithChunkedEncoding() *Request {
 // WithJSONBody sets the request body to the JSON representation of the provided data
 // and sets the Content-Type header to application/json.
 func (r *Request) WithJSONBody(data interface{}) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		r.buildErr = err
+		return r
+	}
+	r.setBody(b)
+	r.WithHeader("Content-Type", "application/json")
+
+	return r
+}
+
+// WithFormURLEncoded sets the request body to values encoded as a URL-encoded form, as OAuth
+// token endpoints expect, and sets the Content-Type header to application/x-www-form-urlencoded.
+// The encoded form is kept in memory so the body can be replayed on retry.
+func (r *Request) WithFormURLEncoded(values url.Values) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	encoded := values.Encode()
+	r.setBody([]byte(encoded))
+	r.WithHeader("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+// WithJSONTemplate renders tmpl, a text/template, with data and sets the output as the request
+// body with Content-Type: application/json, for parameterized payloads. Template errors and
+// output that is not valid JSON are reported when the request is built. Values are inserted
+// verbatim, so encode them with the json template function, e.g. {{json .Name}}, which
+// renders any value as a JSON literal, including quoting and escaping strings.
+func (r *Request) WithJSONTemplate(tmpl string, data interface{}) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	t, err := template.New("json").Funcs(jsonTemplateFuncs).Parse(tmpl)
+	if err != nil {
+		r.buildErr = fmt.Errorf("failed to parse JSON template: %w", err)
+		return r
+	}
+	var rendered bytes.Buffer
+	if err := t.Execute(&rendered, data); err != nil {
+		r.buildErr = fmt.Errorf("failed to render JSON template: %w", err)
+		return r
+	}
+	if err := json.Unmarshal(rendered.Bytes(), new(json.RawMessage)); err != nil {
+		r.buildErr = fmt.Errorf("JSON template rendered invalid JSON: %w", err)
+		return r
+	}
+
+	r.setBody(rendered.Bytes())
+	r.WithHeader("Content-Type", "application/json")
+
+	return r
+}
+
+// jsonTemplateFuncs are the functions available to WithJSONTemplate templates
+var jsonTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// WithGzipBody compresses data with gzip and sets it as the request body along with
+// Content-Encoding: gzip. The Content-Type is detected from the uncompressed data unless
+// already set. The compressed bytes are kept in memory so the body can be replayed on retry.
+func (r *Request) WithGzipBody(data []byte) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	if r.headers["Content-Type"] == "" {
+		r.WithHeader("Content-Type", http.DetectContentType(data))
+	}
+	return r.withGzipBody(data)
+}
+
+// WithGzipJSONBody marshals data to JSON and sets it as a gzip-compressed request body
+// with Content-Type: application/json and Content-Encoding: gzip.
+func (r *Request) WithGzipJSONBody(data interface{}) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
 	b, err := json.Marshal(data)
 	if err != nil {
 		r.buildErr = err
 		return r
 	}
-	r.body = bytes.NewReader(b)
-	r.bodySize = int64(len(b))
 	r.WithHeader("Content-Type", "application/json")
+	return r.withGzipBody(b)
+}
+
+// withGzipBody compresses data into the request body and records the compressed size.
+func (r *Request) withGzipBody(data []byte) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		r.buildErr = fmt.Errorf("failed to gzip request body: %w", err)
+		return r
+	}
+	if err := zw.Close(); err != nil {
+		r.buildErr = fmt.Errorf("failed to gzip request body: %w", err)
+		return r
+	}
+
+	compressed := append([]byte(nil), buf.Bytes()...)
+	r.setBody(compressed)
+	r.WithHeader("Content-Encoding", "gzip")
 
 	return r
 }
 
 // WithMultipartForm constructs a multipart/form-data body from formFields and fileFields.
 func (r *Request) WithMultipartForm(formFields map[string]string, fileFields map[string]string) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
 	buf := getBuffer()
 
 	writer := multipart.NewWriter(buf)
@@ -308,16 +702,122 @@ func (r *Request) WithMultipartForm(formFields map[string]string, fileFields map
 	data := buf.Bytes()
 	putBuffer(buf)
 
-	r.body = bytes.NewReader(data)
-	r.bodySize = int64(len(data))
+	r.setBody(data)
 	r.isMultipart = true
 	r.WithHeader("Content-Type", writer.FormDataContentType())
 
 	return r
 }
 
+// MultipartPart is a single part of a multipart/mixed or multipart/related body.
+type MultipartPart struct {
+	// Headers are the part headers, such as Content-Type or Content-ID.
+	Headers map[string]string
+	Body    []byte
+}
+
+// WithMultipart constructs a multipart body of the given subtype (e.g. "mixed" or "related")
+// from parts. params are added to the top-level Content-Type alongside the boundary.
+func (r *Request) WithMultipart(subtype string, params map[string]string, parts []MultipartPart) *Request {
+	if r.rejectBody() {
+		return r
+	}
+
+	buf := getBuffer()
+
+	writer := multipart.NewWriter(buf)
+
+	for i, part := range parts {
+		header := make(textproto.MIMEHeader, len(part.Headers))
+		for k, v := range part.Headers {
+			header.Set(k, v)
+		}
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			r.buildErr = fmt.Errorf("failed to create multipart part %d: %w", i, err)
+			putBuffer(buf)
+			return r
+		}
+		if _, err := w.Write(part.Body); err != nil {
+			r.buildErr = fmt.Errorf("failed to write multipart part %d: %w", i, err)
+			putBuffer(buf)
+			return r
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		r.buildErr = err
+		putBuffer(buf)
+		return r
+	}
+
+	mediaParams := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		mediaParams[k] = v
+	}
+	mediaParams["boundary"] = writer.Boundary()
+	contentType := mime.FormatMediaType("multipart/"+subtype, mediaParams)
+	if contentType == "" {
+		r.buildErr = fmt.Errorf("invalid multipart subtype or parameters: %q", subtype)
+		putBuffer(buf)
+		return r
+	}
+
+	data := append([]byte(nil), buf.Bytes()...)
+	putBuffer(buf)
+
+	r.setBody(data)
+	r.isMultipart = true
+	r.WithHeader("Content-Type", contentType)
+
+	return r
+}
+
+// WithMultipartMixed constructs a multipart/mixed body from parts.
+func (r *Request) WithMultipartMixed(parts []MultipartPart) *Request {
+	return r.WithMultipart("mixed", nil, parts)
+}
+
+// WithMultipartRelated constructs a multipart/related body from parts. rootType is the
+// media type of the root part and is set as the "type" parameter when not empty.
+func (r *Request) WithMultipartRelated(rootType string, parts []MultipartPart) *Request {
+	var params map[string]string
+	if rootType != "" {
+		params = map[string]string{"type": rootType}
+	}
+	return r.WithMultipart("related", params, parts)
+}
+
+// Validate reports problems that would make the request fail to build or send, without
+// building it: any error recorded by the With* methods, an invalid method, a missing or
+// malformed URL, or a body on a GET or HEAD request.
+func (r *Request) Validate() error {
+	if r.buildErr != nil {
+		return r.buildErr
+	}
+	if r.method == "" || strings.IndexFunc(r.method, func(c rune) bool { return !httpguts.IsTokenRune(c) }) != -1 {
+		return fmt.Errorf("invalid HTTP method %q", r.method)
+	}
+	if r.url == "" {
+		return fmt.Errorf("request URL is empty")
+	}
+	if _, err := url.ParseRequestURI(r.url); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if !r.allowsBody() && (r.body != nil || r.bodyChan != nil || r.bodyGetter != nil) {
+		return r.bodyNotAllowedError()
+	}
+	return nil
+}
+
 // BuildHTTPRequest constructs an *http.Request from the Request.
 func (r *Request) BuildHTTPRequest() (*http.Request, error) {
+	return r.buildHTTPRequest(r.ctx)
+}
+
+// buildHTTPRequest constructs an *http.Request bound to ctx, or to the background context when
+// ctx is nil. Channel-fed bodies stop streaming once ctx is done.
+func (r *Request) buildHTTPRequest(ctx context.Context) (*http.Request, error) {
 	if r.buildErr != nil {
 		return nil, r.buildErr
 	}
@@ -329,29 +829,51 @@ func (r *Request) BuildHTTPRequest() (*http.Request, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
+	parsedURL.RawQuery = r.encodeQuery(parsedURL)
 
-	q := parsedURL.Query()
-	for key, values := range r.queryParams {
-		for _, v := range values {
-			q.Add(key, v)
-		}
-	}
-	parsedURL.RawQuery = q.Encode()
-
-	// Use the request's context if available, otherwise use background
-	ctx := r.ctx
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if r.proxy != nil {
+		ctx = WithProxyContext(ctx, r.proxy)
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, r.method, parsedURL.String(), r.body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new HTTP request: %w", err)
 	}
+	if r.bodyChan != nil {
+		httpReq.Body = pipeBodyChannel(ctx, r.bodyChan, r.bodyAbort)
+		httpReq.ContentLength = -1
+		httpReq.TransferEncoding = []string{"chunked"}
+	}
+	if r.bodyGetter != nil {
+		body, err := r.bodyGetter()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body: %w", err)
+		}
+		httpReq.Body = body
+		httpReq.GetBody = r.bodyGetter
+		httpReq.ContentLength = -1
+	}
 
 	for key, value := range r.headers {
 		httpReq.Header.Set(key, value)
 	}
+	if r.host != "" {
+		httpReq.Host = r.host
+	}
+
+	switch r.contentTypeMode {
+	case contentTypeDetect:
+		if httpReq.Header.Get("Content-Type") == "" {
+			if sniffed := r.detectContentType(); sniffed != "" {
+				httpReq.Header.Set("Content-Type", sniffed)
+			}
+		}
+	case contentTypeSuppress:
+		httpReq.Header.Del("Content-Type")
+	}
 
 	// Ensure chunked encoding is correctly applied
 	if r.headers["Transfer-Encoding"] == "chunked" {
@@ -359,23 +881,26 @@ func (r *Request) BuildHTTPRequest() (*http.Request, error) {
 	} else if r.bodySize > 0 {
 		httpReq.ContentLength = r.bodySize
 	}
+	if r.bodyTee != nil {
+		teeBody(httpReq, r.bodyTee)
+	}
 
 	return httpReq, nil
 }
 
+// detectContentType sniffs the first 512 bytes of an in-memory body without consuming it.
+func (r *Request) detectContentType() string {
+	buf, ok := r.body.(*bytes.Reader)
+	if !ok || buf.Size() == 0 {
+		return ""
+	}
+	head := make([]byte, 512)
+	n, _ := buf.ReadAt(head, 0)
+	return http.DetectContentType(head[:n])
+}
+
 // BuildHTTPRequestWithContext constructs an *http.Request with context from the Request.
+// The context replaces the one set with WithContext for this build only.
 func (r *Request) BuildHTTPRequestWithContext(ctx context.Context) (*http.Request, error) {
-	// Save the current context
-	originalCtx := r.ctx
-
-	// Temporarily set the provided context
-	r.ctx = ctx
-
-	// Build the request
-	req, err := r.BuildHTTPRequest()
-
-	// Restore the original context
-	r.ctx = originalCtx
-
-	return req, err
+	return r.buildHTTPRequest(ctx)
 }
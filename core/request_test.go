@@ -3,11 +3,18 @@ package core_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/jzx17/gofetch/core"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -42,6 +49,84 @@ var _ = Describe("Request", func() {
 		Expect(httpReq.URL.RawQuery).To(ContainSubstring("baz=qux"))
 	})
 
+	It("should format typed query parameters", func() {
+		req := core.NewRequest("GET", "http://example.com").
+			WithQueryParamInt("page", 42).
+			WithQueryParamInt("offset", -7).
+			WithQueryParamBool("active", true).
+			WithQueryParamBool("deleted", false).
+			WithQueryParamSlice("tag", "a", "b", "c")
+		httpReq, err := req.BuildHTTPRequest()
+		Expect(err).NotTo(HaveOccurred())
+
+		query := httpReq.URL.Query()
+		Expect(query.Get("page")).To(Equal("42"))
+		Expect(query.Get("offset")).To(Equal("-7"))
+		Expect(query.Get("active")).To(Equal("true"))
+		Expect(query.Get("deleted")).To(Equal("false"))
+		Expect(query["tag"]).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	Context("WithAutoContentType", func() {
+		pngBytes := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+		It("should detect image/png for PNG bytes", func() {
+			req := core.NewRequest("POST", "http://example.com").WithBody(pngBytes).WithAutoContentType(true)
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Content-Type")).To(Equal("image/png"))
+
+			// Sniffing must not consume the body.
+			body, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal(pngBytes))
+		})
+
+		It("should keep an explicit Content-Type when detection is enabled", func() {
+			req := core.NewRequest("POST", "http://example.com").
+				WithBody(pngBytes).
+				WithHeader("Content-Type", "application/octet-stream").
+				WithAutoContentType(true)
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Content-Type")).To(Equal("application/octet-stream"))
+		})
+
+		It("should leave Content-Type unset when suppressed", func() {
+			req := core.NewRequest("POST", "http://example.com").
+				WithJSONBody(map[string]string{"a": "b"}).
+				WithAutoContentType(false)
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header).NotTo(HaveKey("Content-Type"))
+		})
+
+		It("should not set a Content-Type by default for raw bodies", func() {
+			req := core.NewRequest("POST", "http://example.com").WithBody(pngBytes)
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Content-Type")).To(BeEmpty())
+		})
+	})
+
+	It("should record the compressed size for gzip bodies", func() {
+		payload := []byte(strings.Repeat("a", 1000))
+		req := core.NewRequest("POST", "http://example.com").WithGzipBody(payload)
+		httpReq, err := req.BuildHTTPRequest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(httpReq.Header.Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(httpReq.ContentLength).To(BeNumerically("<", len(payload)))
+
+		compressed, err := io.ReadAll(httpReq.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(int64(len(compressed))).To(Equal(httpReq.ContentLength))
+	})
+
+	It("should error when setting a gzip body for GET requests", func() {
+		_, err := core.NewRequest("GET", "http://example.com").WithGzipBody([]byte("x")).BuildHTTPRequest()
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should set the body correctly with WithBody", func() {
 		data := []byte("hello")
 		req := core.NewRequest("POST", "http://example.com")
@@ -323,4 +408,692 @@ var _ = Describe("Request", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("Multipart Mixed and Related", func() {
+		readParts := func(req *core.Request, wantType string) (map[string]string, []*multipart.Part, [][]byte) {
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			mediaType, params, err := mime.ParseMediaType(httpReq.Header.Get("Content-Type"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mediaType).To(Equal(wantType))
+			Expect(params).To(HaveKey("boundary"))
+
+			reader := multipart.NewReader(httpReq.Body, params["boundary"])
+			var parts []*multipart.Part
+			var bodies [][]byte
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				data, err := io.ReadAll(part)
+				Expect(err).NotTo(HaveOccurred())
+				parts = append(parts, part)
+				bodies = append(bodies, data)
+			}
+			return params, parts, bodies
+		}
+
+		It("should build a multipart/mixed body with per-part headers", func() {
+			req := core.NewRequest("POST", "http://example.com").WithMultipartMixed([]core.MultipartPart{
+				{Headers: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{"a":1}`)},
+				{Headers: map[string]string{"Content-Type": "text/plain"}, Body: []byte("hello")},
+			})
+
+			_, parts, bodies := readParts(req, "multipart/mixed")
+			Expect(parts).To(HaveLen(2))
+			Expect(parts[0].Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(string(bodies[0])).To(Equal(`{"a":1}`))
+			Expect(parts[1].Header.Get("Content-Type")).To(Equal("text/plain"))
+			Expect(string(bodies[1])).To(Equal("hello"))
+		})
+
+		It("should build a multipart/related body with a root type and Content-IDs", func() {
+			req := core.NewRequest("POST", "http://example.com").WithMultipartRelated("application/xml", []core.MultipartPart{
+				{Headers: map[string]string{"Content-Type": "application/xml", "Content-ID": "<root>"}, Body: []byte("<doc/>")},
+				{Headers: map[string]string{"Content-Type": "image/png", "Content-ID": "<img1>"}, Body: []byte{0x89, 0x50}},
+			})
+
+			params, parts, bodies := readParts(req, "multipart/related")
+			Expect(params["type"]).To(Equal("application/xml"))
+			Expect(parts[0].Header.Get("Content-ID")).To(Equal("<root>"))
+			Expect(parts[1].Header.Get("Content-ID")).To(Equal("<img1>"))
+			Expect(bodies[1]).To(Equal([]byte{0x89, 0x50}))
+		})
+
+		It("should support a custom subtype", func() {
+			req := core.NewRequest("POST", "http://example.com").WithMultipart("alternative", nil, []core.MultipartPart{
+				{Headers: map[string]string{"Content-Type": "text/plain"}, Body: []byte("plain")},
+			})
+
+			_, parts, _ := readParts(req, "multipart/alternative")
+			Expect(parts).To(HaveLen(1))
+		})
+
+		It("should error on an invalid subtype", func() {
+			req := core.NewRequest("POST", "http://example.com").WithMultipart("bad subtype", nil, nil)
+			_, err := req.BuildHTTPRequest()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("WithBodyChannel", func() {
+		It("should stream channel chunks to the server in order", func() {
+			var received []byte
+			var transferEncoding []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				transferEncoding = r.TransferEncoding
+				received, _ = io.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			ch := make(chan []byte)
+			go func() {
+				defer close(ch)
+				for _, chunk := range []string{"log line 1\n", "log line 2\n", "log line 3\n"} {
+					ch <- []byte(chunk)
+				}
+			}()
+
+			httpReq, err := core.NewRequest("POST", server.URL).WithBodyChannel(ch).BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.ContentLength).To(Equal(int64(-1)))
+
+			resp, err := http.DefaultClient.Do(httpReq)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+
+			Expect(transferEncoding).To(Equal([]string{"chunked"}))
+			Expect(string(received)).To(Equal("log line 1\nlog line 2\nlog line 3\n"))
+		})
+
+		It("should abort the body when the context ends before the channel closes", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			ch := make(chan []byte, 1)
+			ch <- []byte("partial")
+
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithBodyChannel(ch).
+				BuildHTTPRequestWithContext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			buf := make([]byte, 7)
+			_, err = io.ReadFull(httpReq.Body, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf)).To(Equal("partial"))
+
+			cancel()
+			_, err = io.ReadAll(httpReq.Body)
+			Expect(errors.Is(err, core.ErrBodyStreamAborted)).To(BeTrue())
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+
+		It("should let a later body setter replace the channel", func() {
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithBodyChannel(make(chan []byte)).
+				WithBody([]byte("fixed")).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.ContentLength).To(Equal(int64(5)))
+
+			data, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("fixed"))
+		})
+
+		It("should reject a body channel on GET", func() {
+			_, err := core.NewRequest("GET", "http://example.com").
+				WithBodyChannel(make(chan []byte)).
+				BuildHTTPRequest()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("WithBodyGetter", func() {
+		It("should use the getter for the initial body and GetBody", func() {
+			calls := 0
+			getBody := func() (io.ReadCloser, error) {
+				calls++
+				return io.NopCloser(strings.NewReader("payload")), nil
+			}
+
+			httpReq, err := core.NewRequest("PUT", "http://example.com").WithBodyGetter(getBody).BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.ContentLength).To(Equal(int64(-1)))
+			Expect(httpReq.GetBody).NotTo(BeNil())
+
+			data, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("payload"))
+
+			replay, err := httpReq.GetBody()
+			Expect(err).NotTo(HaveOccurred())
+			data, err = io.ReadAll(replay)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("payload"))
+			Expect(calls).To(Equal(2))
+		})
+
+		It("should surface a getter error from Build", func() {
+			_, err := core.NewRequest("POST", "http://example.com").
+				WithBodyGetter(func() (io.ReadCloser, error) { return nil, errors.New("source gone") }).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("source gone")))
+		})
+
+		It("should let a later body setter replace the getter", func() {
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithBodyGetter(func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("from getter")), nil
+				}).
+				WithJSONBody(map[string]string{"from": "json"}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"from": "json"}`))
+			Expect(httpReq.ContentLength).To(Equal(int64(len(data))))
+		})
+
+		It("should reject a body getter on HEAD", func() {
+			_, err := core.NewRequest("HEAD", "http://example.com").
+				WithBodyGetter(func() (io.ReadCloser, error) { return http.NoBody, nil }).
+				BuildHTTPRequest()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("WithAcceptEncodingQ", func() {
+		It("should order encodings by descending q-value, then by name", func() {
+			httpReq, err := core.NewRequest("GET", "http://example.com").
+				WithAcceptEncodingQ(map[string]float64{"identity": 0.1, "gzip": 0.8, "br": 1, "deflate": 0.8, "zstd": 0.125}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Accept-Encoding")).To(Equal("br;q=1.0, deflate;q=0.8, gzip;q=0.8, zstd;q=0.125, identity;q=0.1"))
+		})
+
+		It("should keep a q of zero to refuse an encoding", func() {
+			httpReq, err := core.NewRequest("GET", "http://example.com").
+				WithAcceptEncodingQ(map[string]float64{"gzip": 1, "identity": 0}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Accept-Encoding")).To(Equal("gzip;q=1.0, identity;q=0.0"))
+		})
+
+		It("should reject invalid q-values and names", func() {
+			for _, encodings := range []map[string]float64{
+				{"gzip": 1.5},
+				{"gzip": -0.1},
+				{"gzip": 0.1234},
+				{"g zip": 1},
+				{},
+			} {
+				_, err := core.NewRequest("GET", "http://example.com").WithAcceptEncodingQ(encodings).BuildHTTPRequest()
+				Expect(err).To(MatchError(ContainSubstring("invalid Accept-Encoding")), "%v", encodings)
+			}
+		})
+	})
+
+	Context("WithMultipartStream", func() {
+		readParts := func(httpReq *http.Request, body io.Reader) map[string]string {
+			_, params, err := mime.ParseMediaType(httpReq.Header.Get("Content-Type"))
+			Expect(err).NotTo(HaveOccurred())
+			parts := map[string]string{}
+			reader := multipart.NewReader(body, params["boundary"])
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					return parts
+				}
+				Expect(err).NotTo(HaveOccurred())
+				data, err := io.ReadAll(part)
+				Expect(err).NotTo(HaveOccurred())
+				parts[part.FormName()+"|"+part.FileName()] = string(data)
+			}
+		}
+
+		It("should stream fields and files with an unknown length", func() {
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithMultipartStream(
+					map[string]string{"kind": "artifact"},
+					map[string]core.FileReader{"file": {Name: "build.tar", Reader: strings.NewReader("contents")}},
+				).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.ContentLength).To(Equal(int64(-1)))
+			Expect(httpReq.Header.Get("Content-Type")).To(HavePrefix("multipart/form-data; boundary="))
+
+			Expect(readParts(httpReq, httpReq.Body)).To(Equal(map[string]string{
+				"kind|":          "artifact",
+				"file|build.tar": "contents",
+			}))
+		})
+
+		It("should rewind seekable readers when the body is replayed", func() {
+			file := strings.NewReader("xxcontents")
+			_, _ = file.Seek(2, io.SeekStart)
+			httpReq, err := core.NewRequest("PUT", "http://example.com").
+				WithMultipartStream(nil, map[string]core.FileReader{"file": {Name: "a.bin", Reader: file}}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			// Abandon the first attempt part way through
+			_, err = io.ReadFull(httpReq.Body, make([]byte, 10))
+			Expect(err).NotTo(HaveOccurred())
+
+			replay, err := httpReq.GetBody()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readParts(httpReq, replay)).To(Equal(map[string]string{"file|a.bin": "contents"}))
+		})
+
+		It("should refuse to replay a body with an unseekable reader", func() {
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithMultipartStream(nil, map[string]core.FileReader{
+					"file": {Name: "a.bin", Reader: io.MultiReader(strings.NewReader("contents"))},
+				}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readParts(httpReq, httpReq.Body)).To(HaveKeyWithValue("file|a.bin", "contents"))
+
+			_, err = httpReq.GetBody()
+			Expect(err).To(MatchError(core.ErrMultipartStreamNotReplayable))
+		})
+
+		It("should upload with chunked encoding", func() {
+			var received string
+			var transferEncoding []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				transferEncoding = r.TransferEncoding
+				file, _, err := r.FormFile("file")
+				if err == nil {
+					data, _ := io.ReadAll(file)
+					received = string(data)
+				}
+			}))
+			defer server.Close()
+
+			payload := strings.Repeat("0123456789", 100000)
+			httpReq, err := core.NewRequest("POST", server.URL).
+				WithMultipartStream(nil, map[string]core.FileReader{"file": {Name: "big.bin", Reader: strings.NewReader(payload)}}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := http.DefaultClient.Do(httpReq)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+
+			Expect(transferEncoding).To(Equal([]string{"chunked"}))
+			Expect(received).To(Equal(payload))
+		})
+
+		It("should reject a nil file reader", func() {
+			_, err := core.NewRequest("POST", "http://example.com").
+				WithMultipartStream(nil, map[string]core.FileReader{"file": {Name: "a.bin"}}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("file reader for field file is nil")))
+		})
+	})
+	Context("WithBodyTee", func() {
+		It("should copy the body the server receives to the sink", func() {
+			var received string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				received = string(data)
+			}))
+			defer server.Close()
+
+			var sink strings.Builder
+			httpReq, err := core.NewRequest("POST", server.URL).
+				WithBody([]byte(`{"upload":"archive me"}`)).
+				WithBodyTee(&sink).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := http.DefaultClient.Do(httpReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+
+			Expect(received).To(Equal(`{"upload":"archive me"}`))
+			Expect(sink.String()).To(Equal(received))
+		})
+
+		It("should write the body to the sink once across replayed attempts", func() {
+			var sink strings.Builder
+			httpReq, err := core.NewRequest("PUT", "http://example.com").
+				WithBodyTee(&sink).
+				WithBodyGetter(func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("0123456789")), nil
+				}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			// Abandon the first attempt part way through
+			_, err = io.ReadFull(httpReq.Body, make([]byte, 4))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sink.String()).To(Equal("0123"))
+
+			for i := 0; i < 2; i++ {
+				replay, err := httpReq.GetBody()
+				Expect(err).NotTo(HaveOccurred())
+				data, err := io.ReadAll(replay)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("0123456789"))
+			}
+			Expect(sink.String()).To(Equal("0123456789"))
+		})
+
+		It("should fail reading the body when the sink fails", func() {
+			sinkErr := errors.New("disk full")
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithBody([]byte("payload")).
+				WithBodyTee(failingWriter{err: sinkErr}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.ReadAll(httpReq.Body)
+			Expect(err).To(MatchError(sinkErr))
+		})
+
+		It("should leave requests without a body untouched", func() {
+			var sink strings.Builder
+			httpReq, err := core.NewRequest("GET", "http://example.com").WithBodyTee(&sink).BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Body).To(BeNil())
+		})
+	})
+	Context("WithJSONTemplate", func() {
+		It("should render the template into a JSON body", func() {
+			data := map[string]interface{}{"Name": "widget", "Count": 3}
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithJSONTemplate(`{"name": {{json .Name}}, "count": {{.Count}}}`, data).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			body, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{"name": "widget", "count": 3}`))
+			Expect(httpReq.ContentLength).To(Equal(int64(len(body))))
+		})
+
+		It("should encode values with the json function", func() {
+			data := map[string]interface{}{"Name": "O'Brien \"the\x7f\" <x>", "Tags": []string{"a", "b"}}
+			httpReq, err := core.NewRequest("POST", "http://example.com").
+				WithJSONTemplate(`{"name": {{json .Name}}, "tags": {{json .Tags}}}`, data).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded struct {
+				Name string   `json:"name"`
+				Tags []string `json:"tags"`
+			}
+			Expect(json.NewDecoder(httpReq.Body).Decode(&decoded)).To(Succeed())
+			Expect(decoded.Name).To(Equal("O'Brien \"the\x7f\" <x>"))
+			Expect(decoded.Tags).To(Equal([]string{"a", "b"}))
+		})
+
+		It("should reject output that is not valid JSON", func() {
+			_, err := core.NewRequest("POST", "http://example.com").
+				WithJSONTemplate(`{"name": {{.Name}}}`, map[string]string{"Name": "widget"}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("JSON template rendered invalid JSON")))
+		})
+
+		It("should report template parse and render errors", func() {
+			_, err := core.NewRequest("POST", "http://example.com").
+				WithJSONTemplate(`{"name": {{.Name}`, nil).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("failed to parse JSON template")))
+
+			_, err = core.NewRequest("POST", "http://example.com").
+				WithJSONTemplate(`{"name": {{.Name.Missing}}}`, map[string]int{"Name": 1}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("failed to render JSON template")))
+		})
+	})
+
+	Context("Timestamp and nonce params", func() {
+		It("should format timestamps in each format", func() {
+			t := time.Date(2023, 11, 14, 22, 13, 20, 123000000, time.FixedZone("CET", 3600))
+			cases := []struct {
+				format core.TimestampFormat
+				want   string
+			}{
+				{core.TimestampUnixSeconds, "1699996400"},
+				{core.TimestampUnixMillis, "1699996400123"},
+				{core.TimestampRFC3339, "2023-11-14T21:13:20Z"},
+			}
+			for _, tc := range cases {
+				httpReq, err := core.NewRequest("GET", "http://example.com").
+					WithTimestampParam("ts", t, tc.format).
+					BuildHTTPRequest()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(httpReq.URL.Query().Get("ts")).To(Equal(tc.want))
+			}
+		})
+
+		It("should report an unknown timestamp format", func() {
+			_, err := core.NewRequest("GET", "http://example.com").
+				WithTimestampParam("ts", time.Now(), core.TimestampFormat(99)).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("unknown timestamp format")))
+		})
+
+		It("should generate unique hex nonces", func() {
+			seen := map[string]bool{}
+			for i := 0; i < 100; i++ {
+				httpReq, err := core.NewRequest("GET", "http://example.com").
+					WithNonce("nonce", nil).
+					BuildHTTPRequest()
+				Expect(err).NotTo(HaveOccurred())
+				nonce := httpReq.URL.Query().Get("nonce")
+				Expect(nonce).To(MatchRegexp(`^[0-9a-f]{32}$`))
+				Expect(seen).NotTo(HaveKey(nonce))
+				seen[nonce] = true
+			}
+		})
+
+		It("should read nonces from the injected source", func() {
+			source := strings.NewReader(strings.Repeat("\x01", core.DefaultNonceSize))
+			httpReq, err := core.NewRequest("GET", "http://example.com").
+				WithNonce("nonce", source).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.URL.Query().Get("nonce")).To(Equal(strings.Repeat("01", core.DefaultNonceSize)))
+
+			_, err = core.NewRequest("GET", "http://example.com").
+				WithNonce("nonce", strings.NewReader("short")).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError(ContainSubstring("failed to generate nonce")))
+		})
+	})
+
+	Context("Validate", func() {
+		It("should report a body on a GET request", func() {
+			err := core.NewRequest("GET", "http://example.com").WithBody([]byte("data")).Validate()
+			Expect(err).To(MatchError(ContainSubstring("does not allow a body")))
+
+			err = core.NewRequest("GET", "http://example.com").WithJSONBody(map[string]int{"a": 1}).Validate()
+			Expect(err).To(MatchError(ContainSubstring("does not allow a body")))
+		})
+
+		It("should report an invalid URL", func() {
+			err := core.NewRequest("GET", "not a url").Validate()
+			Expect(err).To(MatchError(ContainSubstring("invalid URL")))
+		})
+
+		It("should report an invalid method", func() {
+			err := core.NewRequest("BAD METHOD", "http://example.com").Validate()
+			Expect(err).To(MatchError(ContainSubstring("invalid HTTP method")))
+		})
+
+		It("should return nil for a valid request", func() {
+			req := core.NewRequest("POST", "http://example.com/items").
+				WithJSONBody(map[string]string{"name": "widget"}).
+				WithQueryParam("dry_run", "true")
+			Expect(req.Validate()).To(Succeed())
+		})
+	})
+	Context("Body guard for GET and HEAD", func() {
+		It("should fail to build a GET with a JSON body", func() {
+			_, err := core.NewRequest("GET", "http://example.com").
+				WithJSONBody(map[string]string{"q": "x"}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError("http method GET does not allow a body"))
+		})
+
+		It("should fail to build a HEAD with a multipart form", func() {
+			_, err := core.NewRequest("HEAD", "http://example.com").
+				WithMultipartForm(map[string]string{"field": "value"}, nil).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError("http method HEAD does not allow a body"))
+		})
+
+		It("should fail to build a GET with a multipart mixed body", func() {
+			_, err := core.NewRequest("GET", "http://example.com").
+				WithMultipartMixed([]core.MultipartPart{{Body: []byte("part")}}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError("http method GET does not allow a body"))
+		})
+
+		It("should fail to build a GET with a form body", func() {
+			_, err := core.NewRequest("GET", "http://example.com").
+				WithFormURLEncoded(url.Values{"q": {"x"}}).
+				BuildHTTPRequest()
+			Expect(err).To(MatchError("http method GET does not allow a body"))
+		})
+	})
+	Context("WithFormURLEncoded", func() {
+		It("should encode the form and set the Content-Type", func() {
+			values := url.Values{"grant_type": {"client_credentials"}, "scope": {"read write"}}
+			httpReq, err := core.NewRequest("POST", "http://example.com/token").
+				WithFormURLEncoded(values).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Get("Content-Type")).To(Equal("application/x-www-form-urlencoded"))
+
+			body, err := io.ReadAll(httpReq.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("grant_type=client_credentials&scope=read+write"))
+			Expect(httpReq.ContentLength).To(Equal(int64(len(body))))
+		})
+
+		It("should produce a replayable body", func() {
+			httpReq, err := core.NewRequest("POST", "http://example.com/token").
+				WithFormURLEncoded(url.Values{"a": {"1"}}).
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.GetBody).NotTo(BeNil())
+
+			for i := 0; i < 2; i++ {
+				body, err := httpReq.GetBody()
+				Expect(err).NotTo(HaveOccurred())
+				data, err := io.ReadAll(body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("a=1"))
+			}
+		})
+	})
+	Context("Authorization helpers", func() {
+		It("should encode basic auth like http.Request.SetBasicAuth", func() {
+			httpReq, err := core.NewRequest("GET", "http://example.com").
+				WithBasicAuth("aladdin", "open:sesame").
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			expected, _ := http.NewRequest("GET", "http://example.com", nil)
+			expected.SetBasicAuth("aladdin", "open:sesame")
+			Expect(httpReq.Header.Get("Authorization")).To(Equal(expected.Header.Get("Authorization")))
+
+			user, pass, ok := httpReq.BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(user).To(Equal("aladdin"))
+			Expect(pass).To(Equal("open:sesame"))
+		})
+
+		It("should overwrite a previous bearer token", func() {
+			httpReq, err := core.NewRequest("GET", "http://example.com").
+				WithBearerToken("first").
+				WithBearerToken("second").
+				BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Header.Values("Authorization")).To(Equal([]string{"Bearer second"}))
+		})
+	})
+
+	Context("WithHost", func() {
+		It("should send the override Host while dialing the URL host", func() {
+			var seenHost string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seenHost = r.Host
+			}))
+			defer server.Close()
+
+			httpReq, err := core.NewRequest("GET", server.URL).WithHost("virtual.example").BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Host).To(Equal("virtual.example"))
+			Expect(server.URL).To(HaveSuffix(httpReq.URL.Host))
+
+			resp, err := http.DefaultClient.Do(httpReq)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(seenHost).To(Equal("virtual.example"))
+		})
+
+		It("should keep the override when cloned", func() {
+			httpReq, err := core.NewRequest("GET", "http://10.0.0.1/").WithHost("virtual.example").Clone().BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.Host).To(Equal("virtual.example"))
+			Expect(httpReq.URL.Host).To(Equal("10.0.0.1"))
+		})
+	})
+	Context("Canonical query", func() {
+		It("should match the query string sent on the wire", func() {
+			var rawQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rawQuery = r.URL.RawQuery
+			}))
+			defer server.Close()
+
+			req := core.NewRequest("GET", server.URL+"?b=2&a=z").
+				WithQueryParamSlice("a", "y", "x").
+				WithQueryParam("name", "hello world+1").
+				WithCanonicalQuery()
+
+			canonical, err := req.QueryString()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(canonical).To(Equal("a=x&a=y&a=z&b=2&name=hello%20world%2B1"))
+
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := http.DefaultClient.Do(httpReq)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+
+			Expect(rawQuery).To(Equal(canonical))
+		})
+
+		It("should return the default encoding with sorted keys", func() {
+			req := core.NewRequest("GET", "http://example.com?z=1").WithQueryParam("a", "two words")
+			query, err := req.QueryString()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("a=two+words&z=1"))
+
+			httpReq, err := req.BuildHTTPRequest()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.URL.RawQuery).To(Equal(query))
+		})
+
+		It("should surface build errors", func() {
+			_, err := core.NewRequest("GET", "not a url").QueryString()
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
+
+// failingWriter fails every write with err.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
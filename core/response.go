@@ -1,19 +1,173 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Response wraps a http.Response to provide helper methods.
 type Response struct {
 	*http.Response
 	BytesRead int64
+
+	capture *bodyCapture
+	// buffered retains the full body of a buffered response so it can be read repeatedly
+	buffered []byte
+	// decodeContent makes JSON, XML, Bytes and String decode the body per Content-Encoding
+	decodeContent bool
+	// emptyBodyPolicy decides what the decode helpers do with an empty body
+	emptyBodyPolicy EmptyBodyPolicy
+}
+
+// ErrEmptyBody is returned by the decode helpers for an empty body under EmptyBodyError.
+// It is joined with io.EOF, so errors.Is(err, io.EOF) still holds.
+var ErrEmptyBody = errors.New("empty response body")
+
+// EmptyBodyPolicy selects how JSON, JSONUseNumber, XML and DecodeByStatus treat a response
+// with an empty body, which some APIs send with a JSON Content-Type on 200.
+type EmptyBodyPolicy int
+
+const (
+	// EmptyBodyError fails decoding with ErrEmptyBody
+	EmptyBodyError EmptyBodyPolicy = iota
+	// EmptyBodyIgnore treats an empty body as a successful no-op, leaving the target untouched
+	EmptyBodyIgnore
+)
+
+// WithEmptyBodyPolicy sets how the decode helpers treat an empty body and returns r for chaining.
+func (r *Response) WithEmptyBodyPolicy(policy EmptyBodyPolicy) *Response {
+	r.emptyBodyPolicy = policy
+	return r
+}
+
+// emptyBody returns the outcome of decoding an empty body under the configured policy.
+func (r *Response) emptyBody() error {
+	if r.emptyBodyPolicy == EmptyBodyIgnore {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrEmptyBody, io.EOF)
+}
+
+// decode runs unmarshal on buffered or content-decoded bodies and decodeStream on streamed ones,
+// applying the empty body policy first.
+func (r *Response) decode(unmarshal func(data []byte) error, decodeStream func(body io.Reader) error) error {
+	if r.decodeContent {
+		data, err := r.decodedBytes()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return r.emptyBody()
+		}
+		return unmarshal(data)
+	}
+	if r.buffered != nil {
+		if len(r.buffered) == 0 {
+			return r.emptyBody()
+		}
+		return unmarshal(r.buffered)
+	}
+	if r.Response == nil || r.Body == nil {
+		return r.emptyBody()
+	}
+
+	body := bufio.NewReader(r.Body)
+	if _, err := body.Peek(1); err == io.EOF {
+		return r.emptyBody()
+	}
+	return decodeStream(body)
+}
+
+// NewBufferedResponse wraps resp whose body has already been read into body. The bytes are
+// retained, so Bytes, String, BodyString, JSON and XML can be called any number of times.
+func NewBufferedResponse(resp *http.Response, body []byte) *Response {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	if body == nil {
+		body = []byte{}
+	}
+	return &Response{Response: resp, buffered: body}
+}
+
+// IsBuffered reports whether the response body is retained in memory.
+func (r *Response) IsBuffered() bool {
+	return r.buffered != nil
+}
+
+// BodyString returns the retained body of a buffered response without consuming it,
+// or an empty string if the response is not buffered.
+func (r *Response) BodyString() string {
+	return string(r.buffered)
+}
+
+// bodyCapture holds the prefix of a body teed off while it is read
+type bodyCapture struct {
+	mu    sync.Mutex
+	data  []byte
+	limit int
+}
+
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if room := c.limit - len(c.data); room > 0 {
+		if len(p) > room {
+			c.data = append(c.data, p[:room]...)
+		} else {
+			c.data = append(c.data, p...)
+		}
+	}
+	return len(p), nil
+}
+
+// captureReadCloser tees reads into a bodyCapture while keeping the original Close
+type captureReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// CaptureBody records the first limit bytes of the body as the caller reads it, so they can
+// be inspected later with CapturedBody. The stream itself is passed through unchanged.
+func (r *Response) CaptureBody(limit int) {
+	if r.Response == nil || r.Body == nil || limit <= 0 {
+		return
+	}
+	r.capture = &bodyCapture{limit: limit}
+	if r.buffered != nil {
+		_, _ = r.capture.Write(r.buffered)
+		return
+	}
+	r.Body = &captureReadCloser{
+		Reader: io.TeeReader(r.Body, r.capture),
+		Closer: r.Body,
+	}
+}
+
+// CapturedBody returns a copy of the body prefix recorded so far by CaptureBody,
+// or nil if capturing is not enabled.
+func (r *Response) CapturedBody() []byte {
+	if r.capture == nil {
+		return nil
+	}
+	r.capture.mu.Lock()
+	defer r.capture.mu.Unlock()
+	return append([]byte(nil), r.capture.data...)
 }
 
 // CloseBody closes the response body.
@@ -25,6 +179,7 @@ func (r *Response) CloseBody() error {
 }
 
 // JSON decodes the JSON response into the provided variable.
+// An empty body is handled according to the response's EmptyBodyPolicy.
 func (r *Response) JSON(v interface{}) (err error) {
 	defer func() {
 		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
@@ -32,10 +187,51 @@ func (r *Response) JSON(v interface{}) (err error) {
 		}
 	}()
 
-	return json.NewDecoder(r.Body).Decode(v)
+	return r.decode(func(data []byte) error {
+		return json.Unmarshal(data, v)
+	}, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(v)
+	})
+}
+
+// JSONUseNumber is like JSON but decodes numbers as json.Number instead of float64, so large
+// integers and high-precision decimals such as monetary amounts keep every digit. Numbers in
+// interface{} targets become json.Number; in structs, declare such fields as json.Number and
+// convert with Int64, Float64 or a decimal library parsing String().
+func (r *Response) JSONUseNumber(v interface{}) (err error) {
+	defer func() {
+		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	decodeNumbers := func(body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		decoder.UseNumber()
+		return decoder.Decode(v)
+	}
+	return r.decode(func(data []byte) error {
+		return decodeNumbers(bytes.NewReader(data))
+	}, decodeNumbers)
+}
+
+// DecodeByStatus decodes the JSON response into successTarget for 2xx statuses and into
+// errorTarget otherwise, reporting which one was used. A nil target skips decoding.
+// The body is closed afterward.
+func (r *Response) DecodeByStatus(successTarget, errorTarget interface{}) (isSuccess bool, err error) {
+	isSuccess = r.IsSuccess()
+	target := errorTarget
+	if isSuccess {
+		target = successTarget
+	}
+	if target == nil {
+		return isSuccess, r.CloseBody()
+	}
+	return isSuccess, r.JSON(target)
 }
 
 // XML decodes the XML response into the provided variable.
+// An empty body is handled according to the response's EmptyBodyPolicy.
 func (r *Response) XML(v interface{}) (err error) {
 	defer func() {
 		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
@@ -43,10 +239,15 @@ func (r *Response) XML(v interface{}) (err error) {
 		}
 	}()
 
-	return xml.NewDecoder(r.Body).Decode(v)
+	return r.decode(func(data []byte) error {
+		return xml.Unmarshal(data, v)
+	}, func(body io.Reader) error {
+		return xml.NewDecoder(body).Decode(v)
+	})
 }
 
 // Bytes reads the full response body into a byte slice.
+// For buffered responses the retained body is returned; it must not be modified.
 func (r *Response) Bytes() (body []byte, err error) {
 	defer func() {
 		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
@@ -54,9 +255,72 @@ func (r *Response) Bytes() (body []byte, err error) {
 		}
 	}()
 
+	if r.decodeContent {
+		return r.decodedBytes()
+	}
+	if r.buffered != nil {
+		return r.buffered, nil
+	}
 	return io.ReadAll(r.Body)
 }
 
+// WithContentDecoding makes JSON, XML, Bytes and String decode the body according to its
+// Content-Encoding header, as DecodedBody does, and returns r for chaining.
+func (r *Response) WithContentDecoding() *Response {
+	r.decodeContent = true
+	return r
+}
+
+// DecodedBody returns the body wrapped in decoders for every encoding listed in the
+// Content-Encoding header, e.g. when a transport with DisableCompression received gzip.
+// Stacked encodings such as "deflate, gzip" are undone in reverse order, identity is skipped,
+// and br requires a decoder registered with RegisterContentDecoder. Unknown encodings are an
+// error. When the transport already decompressed the body, which it reports through
+// Uncompressed after stripping the header, the body is returned as is. Closing the returned
+// body closes the response body.
+func (r *Response) DecodedBody() (io.ReadCloser, error) {
+	if r.Response == nil {
+		return nil, fmt.Errorf("nil response")
+	}
+	if r.Body == nil {
+		return http.NoBody, nil
+	}
+	return r.decodeBody(r.Body)
+}
+
+// decodeBody wraps raw in decoders for the response's Content-Encoding.
+func (r *Response) decodeBody(raw io.ReadCloser) (io.ReadCloser, error) {
+	if r.Uncompressed {
+		return raw, nil
+	}
+	encodings := contentEncodings(r.Header)
+	if len(encodings) == 0 {
+		return raw, nil
+	}
+	return decodeEncodings(raw, encodings, nil, "response")
+}
+
+// decodedBytes reads the full body, or the retained body of a buffered response, decoded.
+func (r *Response) decodedBytes() ([]byte, error) {
+	if r.Response == nil {
+		return nil, fmt.Errorf("nil response")
+	}
+	raw := r.Body
+	if r.buffered != nil {
+		raw = io.NopCloser(bytes.NewReader(r.buffered))
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	body, err := r.decodeBody(raw)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
 // String reads the full response body and returns it as a string.
 func (r *Response) String() (body string, err error) {
 	bytes, err := r.Bytes()
@@ -127,11 +391,26 @@ func (r *Response) IsError() bool {
 	return r.StatusCode >= 400
 }
 
-// MustSuccess returns the response if it's successful, otherwise returns an error
+// IsChunked returns true if the response was sent with chunked transfer encoding
+func (r *Response) IsChunked() bool {
+	if r.Response == nil {
+		return false
+	}
+	for _, te := range r.TransferEncoding {
+		if strings.EqualFold(te, "chunked") {
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(r.Header.Get("Transfer-Encoding")), "chunked")
+}
+
+// MustSuccess returns the response if it's successful, otherwise closes the body and returns
+// a *StatusError carrying the headers and up to DefaultStatusErrorBodyLimit bytes of the body.
 func (r *Response) MustSuccess() (*Response, error) {
 	if !r.IsSuccess() {
-		body, _ := r.String()
-		return nil, fmt.Errorf("request failed with status %s: %s", r.Status, body)
+		statusErr := NewStatusError(r)
+		_ = r.CloseBody()
+		return nil, statusErr
 	}
 	return r, nil
 }
@@ -140,6 +419,17 @@ type StreamOption func(*streamConfig)
 
 type streamConfig struct {
 	bufferSize int
+	// checksumTrailer names the trailer holding the expected body digest
+	checksumTrailer string
+	newHash         func() hash.Hash
+	// decompress decodes the body according to Content-Encoding before chunking
+	decompress bool
+	// maxLineLength bounds a single line for line-oriented decoding such as StreamJSON
+	maxLineLength int
+	// progress is called with the running BytesRead total after every chunk
+	progress func(bytesRead int64)
+	// readTimeout bounds how long a single read may wait for data, if positive
+	readTimeout time.Duration
 }
 
 func WithBufferSize(size int) StreamOption {
@@ -150,22 +440,159 @@ func WithBufferSize(size int) StreamOption {
 	}
 }
 
-// StreamChunks reads the response body in chunks and passes each chunk to the callback.
-func (r *Response) StreamChunks(callback func(chunk []byte), opts ...StreamOption) error {
-	config := streamConfig{
-		bufferSize: 4096,
+// WithMaxLineLength bounds the length of a single line read by StreamJSON. Longer lines fail
+// the stream. Defaults to DefaultMaxLineLength.
+func WithMaxLineLength(n int) StreamOption {
+	return func(c *streamConfig) {
+		if n > 0 {
+			c.maxLineLength = n
+		}
+	}
+}
+
+// WithProgress calls fn with the running BytesRead total after every chunk is delivered, e.g. to
+// drive a progress bar for a streamed download. fn runs on the streaming goroutine.
+func WithProgress(fn func(bytesRead int64)) StreamOption {
+	return func(c *streamConfig) {
+		c.progress = fn
+	}
+}
+
+// ErrStreamReadTimeout is returned by StreamChunks and StreamChunksWithContext when a read
+// configured with WithReadTimeout receives no data in time. It is joined with
+// os.ErrDeadlineExceeded, so the error reports Timeout() like a network timeout.
+var ErrStreamReadTimeout = errors.New("timed out waiting for response body data")
+
+// WithReadTimeout aborts StreamChunks and StreamChunksWithContext when a single read waits longer
+// than d for data; the deadline restarts with every read, so slow but steady bodies still stream.
+// The body is closed on timeout to release the connection.
+func WithReadTimeout(d time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.readTimeout = d
+	}
+}
+
+// WithDecompression decodes the body according to its Content-Encoding header before chunking,
+// for responses the transport did not decompress itself. Stacked encodings and those registered
+// with RegisterContentDecoder are decoded like DecodedBody does. Chunks and BytesRead then
+// reflect the decompressed data.
+func WithDecompression() StreamOption {
+	return func(c *streamConfig) {
+		c.decompress = true
 	}
+}
+
+// WithTrailerChecksum verifies the streamed body against a SHA-256 digest sent by the
+// server in the named trailer (e.g. X-Content-SHA256). The digest may be hex or base64 encoded.
+func WithTrailerChecksum(trailer string) StreamOption {
+	return WithTrailerChecksumHash(trailer, sha256.New)
+}
 
+// WithTrailerChecksumHash is like WithTrailerChecksum but uses the provided hash function.
+func WithTrailerChecksumHash(trailer string, newHash func() hash.Hash) StreamOption {
+	return func(c *streamConfig) {
+		c.checksumTrailer = trailer
+		c.newHash = newHash
+	}
+}
+
+// ChecksumError is returned when a streamed body does not match its trailer checksum.
+type ChecksumError struct {
+	Trailer  string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Expected == "" {
+		return fmt.Sprintf("checksum verification failed: trailer %s is missing", e.Trailer)
+	}
+	return fmt.Sprintf("checksum verification failed: trailer %s is %s, computed %s", e.Trailer, e.Expected, e.Actual)
+}
+
+// newStreamConfig applies opts over the default stream settings.
+func newStreamConfig(opts []StreamOption) streamConfig {
+	config := streamConfig{
+		bufferSize:    4096,
+		maxLineLength: DefaultMaxLineLength,
+	}
 	for _, opt := range opts {
 		opt(&config)
 	}
+	return config
+}
+
+// reportProgress passes the running total to the progress callback, if any.
+func (c *streamConfig) reportProgress(bytesRead int64) {
+	if c.progress != nil {
+		c.progress(bytesRead)
+	}
+}
+
+// hasher returns the running hash for checksum verification, or nil if disabled.
+func (c *streamConfig) hasher() hash.Hash {
+	if c.checksumTrailer == "" || c.newHash == nil {
+		return nil
+	}
+	return c.newHash()
+}
+
+// verifyTrailerChecksum compares the digest against the trailer, which is only populated after EOF.
+func (r *Response) verifyTrailerChecksum(trailer string, h hash.Hash) error {
+	if h == nil {
+		return nil
+	}
+	sum := h.Sum(nil)
+	actual := hex.EncodeToString(sum)
+
+	var expected string
+	if r.Trailer != nil {
+		expected = strings.TrimSpace(r.Trailer.Get(trailer))
+	}
+	if expected == "" {
+		return &ChecksumError{Trailer: trailer, Actual: actual}
+	}
+	if strings.EqualFold(expected, actual) || expected == base64.StdEncoding.EncodeToString(sum) {
+		return nil
+	}
+	return &ChecksumError{Trailer: trailer, Expected: expected, Actual: actual}
+}
+
+// streamReader returns the reader that chunks are taken from, decoding the body if requested.
+// Bodies with an encoding that has no decoder are streamed as is.
+func (r *Response) streamReader(config streamConfig) (io.Reader, error) {
+	if !config.decompress || r.Uncompressed {
+		return r.Body, nil
+	}
+	encodings := contentEncodings(r.Header)
+	if len(encodings) == 0 || unsupportedEncoding(encodings, nil) != "" {
+		return r.Body, nil
+	}
+	return decodeEncodings(r.Body, encodings, nil, "stream")
+}
+
+// StreamChunks reads the response body in chunks and passes each chunk to the callback.
+func (r *Response) StreamChunks(callback func(chunk []byte), opts ...StreamOption) error {
+	config := newStreamConfig(opts)
+	if config.readTimeout > 0 {
+		return r.StreamChunksWithContext(context.Background(), callback, opts...)
+	}
+	h := config.hasher()
+	body, err := r.streamReader(config)
+	if err != nil {
+		return err
+	}
 
 	buf := make([]byte, config.bufferSize)
 	for {
-		n, err := r.Body.Read(buf)
+		n, err := body.Read(buf)
 		if n > 0 {
 			r.BytesRead += int64(n)
+			if h != nil {
+				h.Write(buf[:n])
+			}
 			callback(buf[:n])
+			config.reportProgress(r.BytesRead)
 		}
 		if err == io.EOF {
 			break
@@ -175,17 +602,34 @@ func (r *Response) StreamChunks(callback func(chunk []byte), opts ...StreamOptio
 		}
 	}
 
-	return nil
+	return r.verifyTrailerChecksum(config.checksumTrailer, h)
 }
 
-// StreamChunksWithContext reads the response body in chunks and respects context cancellation.
-func (r *Response) StreamChunksWithContext(ctx context.Context, callback func(chunk []byte), opts ...StreamOption) error {
-	config := streamConfig{
-		bufferSize: 4096,
+// CopyTo streams the response body into w and returns the number of bytes written.
+// It accepts the same options as StreamChunks, including trailer checksum verification.
+func (r *Response) CopyTo(w io.Writer, opts ...StreamOption) (written int64, err error) {
+	var writeErr error
+	err = r.StreamChunks(func(chunk []byte) {
+		if writeErr != nil {
+			return
+		}
+		n, wErr := w.Write(chunk)
+		written += int64(n)
+		writeErr = wErr
+	}, opts...)
+	if writeErr != nil {
+		return written, fmt.Errorf("failed to write response body: %w", writeErr)
 	}
+	return written, err
+}
 
-	for _, opt := range opts {
-		opt(&config)
+// StreamChunksWithContext reads the response body in chunks and respects context cancellation.
+func (r *Response) StreamChunksWithContext(ctx context.Context, callback func(chunk []byte), opts ...StreamOption) error {
+	config := newStreamConfig(opts)
+	h := config.hasher()
+	body, err := r.streamReader(config)
+	if err != nil {
+		return err
 	}
 
 	buf := make([]byte, config.bufferSize)
@@ -193,20 +637,37 @@ func (r *Response) StreamChunksWithContext(ctx context.Context, callback func(ch
 
 	for {
 		go func() {
-			n, err := r.Body.Read(buf)
+			n, err := body.Read(buf)
 			readChan <- readResult{n: n, err: err}
 		}()
 
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if config.readTimeout > 0 {
+			timer = time.NewTimer(config.readTimeout)
+			timeout = timer.C
+		}
+
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-timeout:
+			_ = r.CloseBody()
+			return fmt.Errorf("error while streaming chunks: %w after %v: %w", ErrStreamReadTimeout, config.readTimeout, os.ErrDeadlineExceeded)
 		case result := <-readChan:
+			if timer != nil {
+				timer.Stop()
+			}
 			if result.n > 0 {
 				r.BytesRead += int64(result.n)
+				if h != nil {
+					h.Write(buf[:result.n])
+				}
 				callback(buf[:result.n])
+				config.reportProgress(r.BytesRead)
 			}
 			if result.err == io.EOF {
-				return nil
+				return r.verifyTrailerChecksum(config.checksumTrailer, h)
 			}
 			if result.err != nil {
 				return fmt.Errorf("error while streaming chunks: %w", result.err)
@@ -220,6 +681,226 @@ type readResult struct {
 	err error
 }
 
+// DefaultMaxLineLength is the longest line StreamJSON accepts unless WithMaxLineLength is given
+const DefaultMaxLineLength = 1 << 20
+
+// countingReader adds the bytes read to the response's BytesRead
+type countingReader struct {
+	r    io.Reader
+	resp *Response
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.resp.BytesRead += int64(n)
+	return n, err
+}
+
+// StreamJSON decodes a newline-delimited JSON (NDJSON) body one line at a time: each non-empty
+// line is unmarshaled into a fresh value from newValue, which is passed to onValue. Lines are
+// read with a buffer of WithBufferSize bytes that grows up to WithMaxLineLength. A malformed or
+// over-long line fails with an error naming its line number, and an error from onValue stops
+// decoding and is returned as is. The body is closed on return.
+func (r *Response) StreamJSON(newValue func() interface{}, onValue func(interface{}) error, opts ...StreamOption) (err error) {
+	defer func() {
+		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	config := newStreamConfig(opts)
+	body, err := r.streamReader(config)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(&countingReader{r: body, resp: r})
+	bufferSize := config.bufferSize
+	if bufferSize > config.maxLineLength {
+		bufferSize = config.maxLineLength
+	}
+	scanner.Buffer(make([]byte, 0, bufferSize), config.maxLineLength)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		v := newValue()
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to decode JSON on line %d: %w", line, err)
+		}
+		if err := onValue(v); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line %d exceeds the maximum length of %d bytes: %w", line+1, config.maxLineLength, err)
+		}
+		return fmt.Errorf("error while streaming JSON lines: %w", err)
+	}
+	return nil
+}
+
+// SSEEvent is one event of a text/event-stream response.
+type SSEEvent struct {
+	// Event is the event type; empty means the default "message" type
+	Event string
+	// Data joins the event's data lines with "\n"
+	Data string
+	// ID is the last event ID seen on the stream, which persists across events
+	ID string
+	// Retry is the reconnection time sent with this event, or 0
+	Retry time.Duration
+}
+
+// sseParser accumulates the fields of the event being read
+type sseParser struct {
+	event  string
+	data   strings.Builder
+	lastID string
+	retry  time.Duration
+}
+
+// line processes one line without its terminator, returning a complete event on a blank line.
+func (p *sseParser) line(line string) (SSEEvent, bool) {
+	if line == "" {
+		return p.dispatch()
+	}
+	if line[0] == ':' {
+		return SSEEvent{}, false
+	}
+
+	field, value := line, ""
+	if i := strings.IndexByte(line, ':'); i >= 0 {
+		field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+	}
+
+	switch field {
+	case "event":
+		p.event = value
+	case "data":
+		p.data.WriteString(value)
+		p.data.WriteByte('\n')
+	case "id":
+		if !strings.ContainsRune(value, 0) {
+			p.lastID = value
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+			p.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return SSEEvent{}, false
+}
+
+// dispatch completes the current event. Blocks without data lines produce no event.
+func (p *sseParser) dispatch() (SSEEvent, bool) {
+	defer func() {
+		p.event = ""
+		p.data.Reset()
+		p.retry = 0
+	}()
+	if p.data.Len() == 0 {
+		return SSEEvent{}, false
+	}
+	return SSEEvent{
+		Event: p.event,
+		Data:  strings.TrimSuffix(p.data.String(), "\n"),
+		ID:    p.lastID,
+		Retry: p.retry,
+	}, true
+}
+
+// StreamSSE parses a text/event-stream body and calls callback once per event, that is, per
+// blank-line-delimited block carrying data. Comment lines starting with ':' are ignored, and
+// an incomplete block at the end of the stream is discarded. Parsing stops at the first error
+// returned by callback, which is passed through. The stream is bound to the request context
+// when the response carries its request; use StreamSSEWithContext to supply another one. The
+// body is closed on return.
+func (r *Response) StreamSSE(callback func(event SSEEvent) error, opts ...StreamOption) error {
+	ctx := context.Background()
+	if r.Request != nil {
+		ctx = r.Request.Context()
+	}
+	return r.StreamSSEWithContext(ctx, callback, opts...)
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// StreamSSEWithContext is like StreamSSE but returns ctx.Err() as soon as ctx is done.
+// BytesRead counts the event stream bytes consumed so far. The body is closed on return, which
+// also stops the read still blocked on the connection when ctx ends.
+func (r *Response) StreamSSEWithContext(ctx context.Context, callback func(event SSEEvent) error, opts ...StreamOption) (err error) {
+	defer func() {
+		if closeErr := r.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	config := newStreamConfig(opts)
+	h := config.hasher()
+	body, err := r.streamReader(config)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(body, config.bufferSize)
+	lines := make(chan lineResult)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			select {
+			case lines <- lineResult{line: line, err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var parser sseParser
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-lines:
+			if result.line != "" {
+				r.BytesRead += int64(len(result.line))
+				if h != nil {
+					h.Write([]byte(result.line))
+				}
+				// An unterminated final line is part of an incomplete block and never dispatches
+				if strings.HasSuffix(result.line, "\n") {
+					line := strings.TrimSuffix(strings.TrimSuffix(result.line, "\n"), "\r")
+					if event, ok := parser.line(line); ok {
+						if err := callback(event); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			if result.err == io.EOF {
+				return r.verifyTrailerChecksum(config.checksumTrailer, h)
+			}
+			if result.err != nil {
+				return fmt.Errorf("error while streaming events: %w", result.err)
+			}
+		}
+	}
+}
+
 // AsyncResponse represents the eventual outcome of an asynchronous HTTP call.
 type AsyncResponse struct {
 	Response *Response
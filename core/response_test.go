@@ -2,6 +2,7 @@ package core_test
 
 import (
 	"context"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"github.com/jzx17/gofetch/core"
@@ -11,7 +12,10 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"testing/iotest"
+	"time"
 )
 
 var _ = Describe("Response", func() {
@@ -30,6 +34,260 @@ var _ = Describe("Response", func() {
 		Expect(result).To(Equal(map[string]string{"message": "hello"}))
 	})
 
+	Context("StreamSSE", func() {
+		newSSEResponse := func(body io.Reader) *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"text/event-stream"}},
+				Body:       io.NopCloser(body),
+			}}
+		}
+
+		It("should parse events, ids, retries and multi-line data", func() {
+			stream := ": keep-alive comment\n" +
+				"event: delta\n" +
+				"id: 1\n" +
+				"data: {\"text\":\"Hel\"}\n" +
+				"\n" +
+				"data: line one\r\n" +
+				"data:line two\r\n" +
+				"retry: 3000\r\n" +
+				"\r\n" +
+				"id\n" +
+				"data\n" +
+				"\n" +
+				"event: ignored without data\n" +
+				"\n" +
+				"data: incomplete at EOF"
+			resp := newSSEResponse(strings.NewReader(stream))
+
+			var events []core.SSEEvent
+			err := resp.StreamSSE(func(event core.SSEEvent) error {
+				events = append(events, event)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(Equal([]core.SSEEvent{
+				{Event: "delta", Data: `{"text":"Hel"}`, ID: "1"},
+				{Data: "line one\nline two", ID: "1", Retry: 3 * time.Second},
+				{Data: "", ID: ""},
+			}))
+			Expect(resp.BytesRead).To(Equal(int64(len(stream))))
+		})
+
+		It("should stop and propagate a callback error", func() {
+			stop := errors.New("enough")
+			resp := newSSEResponse(strings.NewReader("data: 1\n\ndata: 2\n\ndata: 3\n\n"))
+
+			var seen []string
+			err := resp.StreamSSE(func(event core.SSEEvent) error {
+				seen = append(seen, event.Data)
+				if event.Data == "2" {
+					return stop
+				}
+				return nil
+			})
+			Expect(err).To(BeIdenticalTo(stop))
+			Expect(seen).To(Equal([]string{"1", "2"}))
+		})
+
+		It("should return when the context is cancelled while waiting for events", func() {
+			pr, pw := io.Pipe()
+			defer pw.Close()
+			go func() {
+				_, _ = io.WriteString(pw, "data: first\n\n")
+			}()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			var events []core.SSEEvent
+			err := newSSEResponse(pr).StreamSSEWithContext(ctx, func(event core.SSEEvent) error {
+				events = append(events, event)
+				cancel()
+				return nil
+			})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(events).To(HaveLen(1))
+		})
+
+		It("should close the body when the context ends so the pending read returns", func() {
+			pr, pw := io.Pipe()
+			defer pw.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			resp := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"text/event-stream"}},
+				Body:       pr,
+			}}
+			err := resp.StreamSSEWithContext(ctx, func(core.SSEEvent) error { return nil })
+			Expect(err).To(MatchError(context.Canceled))
+
+			_, err = io.WriteString(pw, "data: late\n\n")
+			Expect(err).To(MatchError(io.ErrClosedPipe))
+		})
+
+		It("should use the request context by default", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/events", nil)
+			pr, pw := io.Pipe()
+			defer pw.Close()
+
+			resp := newSSEResponse(pr)
+			resp.Request = req
+			err := resp.StreamSSE(func(core.SSEEvent) error { return nil })
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	Context("Empty body policy", func() {
+		newEmptyResponse := func() *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader("")),
+			}}
+		}
+
+		It("should fail with ErrEmptyBody by default", func() {
+			var result map[string]string
+			err := newEmptyResponse().JSON(&result)
+			Expect(errors.Is(err, core.ErrEmptyBody)).To(BeTrue())
+			Expect(errors.Is(err, io.EOF)).To(BeTrue())
+
+			err = core.NewBufferedResponse(&http.Response{StatusCode: 200}, nil).XML(&result)
+			Expect(errors.Is(err, core.ErrEmptyBody)).To(BeTrue())
+		})
+
+		It("should treat an empty body as a no-op under EmptyBodyIgnore", func() {
+			result := map[string]string{"kept": "yes"}
+			Expect(newEmptyResponse().WithEmptyBodyPolicy(core.EmptyBodyIgnore).JSON(&result)).To(Succeed())
+			Expect(result).To(Equal(map[string]string{"kept": "yes"}))
+
+			var doc struct{ Name string }
+			Expect(newEmptyResponse().WithEmptyBodyPolicy(core.EmptyBodyIgnore).XML(&doc)).To(Succeed())
+
+			buffered := core.NewBufferedResponse(&http.Response{StatusCode: 200}, []byte{}).
+				WithEmptyBodyPolicy(core.EmptyBodyIgnore)
+			isSuccess, err := buffered.DecodeByStatus(&result, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isSuccess).To(BeTrue())
+		})
+
+		It("should still decode a non-empty body under EmptyBodyIgnore", func() {
+			resp := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(strings.NewReader(`{"a":"b"}`)),
+			}}
+			var result map[string]string
+			Expect(resp.WithEmptyBodyPolicy(core.EmptyBodyIgnore).JSON(&result)).To(Succeed())
+			Expect(result).To(Equal(map[string]string{"a": "b"}))
+		})
+	})
+
+	Context("StreamJSON", func() {
+		type record struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		newRecord := func() interface{} { return &record{} }
+
+		newNDJSONResponse := func(body string) *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/x-ndjson"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}}
+		}
+
+		It("should decode one value per non-empty line", func() {
+			body := `{"id":1,"name":"a"}` + "\n\n" + `{"id":2,"name":"b"}` + "\r\n" + `{"id":3,"name":"c"}`
+			resp := newNDJSONResponse(body)
+
+			var records []record
+			err := resp.StreamJSON(newRecord, func(v interface{}) error {
+				records = append(records, *v.(*record))
+				return nil
+			}, core.WithBufferSize(8))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(Equal([]record{{1, "a"}, {2, "b"}, {3, "c"}}))
+			Expect(resp.BytesRead).To(Equal(int64(len(body))))
+		})
+
+		It("should report the line number of a malformed line", func() {
+			body := `{"id":1}` + "\n" + `{"id":2}` + "\n" + `{"id":` + "\n"
+			err := newNDJSONResponse(body).StreamJSON(newRecord, func(interface{}) error { return nil })
+			Expect(err).To(MatchError(ContainSubstring("line 3")))
+		})
+
+		It("should reject a line longer than the maximum", func() {
+			body := `{"id":1}` + "\n" + `{"name":"` + strings.Repeat("x", 100) + `"}` + "\n"
+			var count int
+			err := newNDJSONResponse(body).StreamJSON(newRecord, func(interface{}) error {
+				count++
+				return nil
+			}, core.WithMaxLineLength(64))
+			Expect(err).To(MatchError(ContainSubstring("line 2 exceeds the maximum length of 64 bytes")))
+			Expect(count).To(Equal(1))
+		})
+
+		It("should stop on an onValue error and close the body", func() {
+			stop := errors.New("stop")
+			closeErr := errors.New("closed")
+			resp := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       test.NewErrorCloser([]byte(`{"id":1}`+"\n"+`{"id":2}`+"\n"), closeErr),
+			}}
+
+			err := resp.StreamJSON(newRecord, func(interface{}) error { return stop })
+			Expect(err).To(BeIdenticalTo(stop))
+
+			resp.Body = test.NewErrorCloser([]byte(`{"id":1}`), closeErr)
+			err = resp.StreamJSON(newRecord, func(interface{}) error { return nil })
+			Expect(err).To(MatchError(ContainSubstring("failed to close response body")))
+		})
+	})
+
+	Context("JSONUseNumber", func() {
+		newJSONResponse := func(body string) *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}}
+		}
+
+		It("should keep a large integer exact", func() {
+			var result map[string]interface{}
+			err := newJSONResponse(`{"id": 9007199254740993}`).JSONUseNumber(&result)
+			Expect(err).NotTo(HaveOccurred())
+
+			id, ok := result["id"].(json.Number)
+			Expect(ok).To(BeTrue())
+			Expect(id.String()).To(Equal("9007199254740993"))
+			n, err := id.Int64()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(int64(9007199254740993)))
+		})
+
+		It("should keep a high-precision decimal in a json.Number field", func() {
+			var payment struct {
+				Amount json.Number `json:"amount"`
+			}
+			err := newJSONResponse(`{"amount": 12345678901234.123456789}`).JSONUseNumber(&payment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payment.Amount.String()).To(Equal("12345678901234.123456789"))
+		})
+
+		It("should decode a buffered response", func() {
+			resp := core.NewBufferedResponse(&http.Response{StatusCode: 200}, []byte(`[1.10]`))
+			var result []interface{}
+			Expect(resp.JSONUseNumber(&result)).To(Succeed())
+			Expect(result).To(Equal([]interface{}{json.Number("1.10")}))
+		})
+	})
+
 	It("should read XML correctly", func() {
 		xmlStr := `<root><message>hello</message></root>`
 		res := &http.Response{
@@ -238,6 +496,51 @@ var _ = Describe("Response", func() {
 		Expect(response.BytesRead).To(Equal(int64(33)), "BytesRead should equal the length of the text")
 	})
 
+	It("should report increasing progress totals matching BytesRead", func() {
+		text := strings.Repeat("0123456789", 10)
+		response := &core.Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(text)),
+		}}
+
+		var totals []int64
+		var chunkSum int64
+		err := response.StreamChunks(func(chunk []byte) {
+			chunkSum += int64(len(chunk))
+		}, core.WithBufferSize(16), core.WithProgress(func(bytesRead int64) {
+			Expect(bytesRead).To(Equal(chunkSum))
+			totals = append(totals, bytesRead)
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(totals).To(HaveLen(7))
+		for i := 1; i < len(totals); i++ {
+			Expect(totals[i]).To(BeNumerically(">", totals[i-1]))
+		}
+		Expect(totals[len(totals)-1]).To(Equal(response.BytesRead))
+		Expect(response.BytesRead).To(Equal(int64(100)))
+	})
+
+	It("should report progress when streaming with a context", func() {
+		response := &core.Response{Response: &http.Response{
+			StatusCode: 200,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 40))),
+		}}
+
+		var last int64
+		calls := 0
+		err := response.StreamChunksWithContext(context.Background(), func([]byte) {},
+			core.WithBufferSize(10), core.WithProgress(func(bytesRead int64) {
+				calls++
+				last = bytesRead
+			}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(4))
+		Expect(last).To(Equal(response.BytesRead))
+	})
+
 	It("should error from Bytes if underlying reader returns an error", func() {
 		res := &http.Response{
 			Status: "200 OK",
@@ -437,8 +740,38 @@ var _ = Describe("Response", func() {
 			response := &core.Response{Response: res}
 			_, err := response.MustSuccess()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("request failed with status 404 Not Found"))
-			Expect(err.Error()).To(ContainSubstring("not found"))
+			Expect(err.Error()).To(ContainSubstring("unexpected status code 404 (404 Not Found)"))
+
+			var statusErr *core.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.StatusCode).To(Equal(404))
+			Expect(string(statusErr.Body)).To(Equal("not found"))
+		})
+	})
+
+	Context("NewStatusError", func() {
+		It("should keep the headers and leading body bytes while leaving the body readable", func() {
+			response := &core.Response{Response: &http.Response{
+				Status:     "500 Internal Server Error",
+				StatusCode: 500,
+				Header:     http.Header{"X-Request-Id": {"abc"}},
+				Body:       io.NopCloser(strings.NewReader("database unavailable")),
+			}}
+
+			statusErr := core.NewStatusErrorWithLimit(response, 8)
+			Expect(string(statusErr.Body)).To(Equal("database"))
+			Expect(statusErr.Header.Get("X-Request-Id")).To(Equal("abc"))
+
+			body, err := response.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("database unavailable"))
+		})
+
+		It("should copy from buffered bodies and skip the body when the limit is zero", func() {
+			response := core.NewBufferedResponse(&http.Response{StatusCode: 400}, []byte("bad request"))
+
+			Expect(string(core.NewStatusError(response).Body)).To(Equal("bad request"))
+			Expect(core.NewStatusErrorWithLimit(response, 0).Body).To(BeNil())
 		})
 	})
 
@@ -501,4 +834,294 @@ var _ = Describe("Response", func() {
 			Expect(asyncRespWithErr.Error).To(Equal(expectedErr))
 		})
 	})
+
+	Context("IsChunked", func() {
+		It("should report chunked transfer encoding", func() {
+			response := &core.Response{Response: &http.Response{TransferEncoding: []string{"chunked"}}}
+			Expect(response.IsChunked()).To(BeTrue())
+		})
+
+		It("should report non-chunked responses", func() {
+			response := &core.Response{Response: &http.Response{ContentLength: 10, Header: http.Header{}}}
+			Expect(response.IsChunked()).To(BeFalse())
+		})
+
+		It("should return false if Response is nil", func() {
+			response := &core.Response{}
+			Expect(response.IsChunked()).To(BeFalse())
+		})
+	})
+
+	Context("DecodeByStatus", func() {
+		type success struct {
+			ID int `json:"id"`
+		}
+		type apiError struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+
+		newResponse := func(status int, body string) *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: status,
+				Body:       test.NewMockReadCloser([]byte(body)),
+			}}
+		}
+
+		It("should decode a 200 into the success target", func() {
+			resp := newResponse(200, `{"id": 7}`)
+			var ok success
+			var fail apiError
+
+			isSuccess, err := resp.DecodeByStatus(&ok, &fail)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isSuccess).To(BeTrue())
+			Expect(ok.ID).To(Equal(7))
+			Expect(fail).To(BeZero())
+		})
+
+		It("should decode a 400 into the error target", func() {
+			resp := newResponse(400, `{"code": "invalid", "message": "bad input"}`)
+			var ok success
+			var fail apiError
+
+			isSuccess, err := resp.DecodeByStatus(&ok, &fail)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isSuccess).To(BeFalse())
+			Expect(fail.Code).To(Equal("invalid"))
+			Expect(fail.Message).To(Equal("bad input"))
+			Expect(ok).To(BeZero())
+		})
+
+		It("should skip decoding when the selected target is nil", func() {
+			resp := newResponse(500, `not json`)
+			isSuccess, err := resp.DecodeByStatus(&success{}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isSuccess).To(BeFalse())
+		})
+
+		It("should surface decode errors", func() {
+			resp := newResponse(200, `not json`)
+			_, err := resp.DecodeByStatus(&success{}, &apiError{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("GraphQL", func() {
+		type viewer struct {
+			Viewer struct {
+				Login string `json:"login"`
+			} `json:"viewer"`
+		}
+
+		newResponse := func(status int, body string) *core.Response {
+			return &core.Response{Response: &http.Response{
+				StatusCode: status,
+				Body:       test.NewMockReadCloser([]byte(body)),
+			}}
+		}
+
+		It("should decode the data field", func() {
+			var result viewer
+			err := newResponse(200, `{"data": {"viewer": {"login": "octocat"}}}`).GraphQL(&result)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Viewer.Login).To(Equal("octocat"))
+		})
+
+		It("should surface errors with path and extensions alongside partial data", func() {
+			var result viewer
+			err := newResponse(200, `{
+				"data": {"viewer": {"login": "octocat"}},
+				"errors": [
+					{"message": "forbidden", "path": ["viewer", "repos", 0], "locations": [{"line": 2, "column": 3}],
+					 "extensions": {"code": "FORBIDDEN"}},
+					{"message": "rate limited"}
+				]
+			}`).GraphQL(&result)
+
+			var gqlErr *core.GraphQLError
+			Expect(errors.As(err, &gqlErr)).To(BeTrue())
+			Expect(gqlErr.Errors).To(HaveLen(2))
+			Expect(gqlErr.Errors[0].Path).To(Equal([]interface{}{"viewer", "repos", float64(0)}))
+			Expect(gqlErr.Errors[0].Locations).To(Equal([]core.GraphQLLocation{{Line: 2, Column: 3}}))
+			Expect(gqlErr.Errors[0].Extensions).To(HaveKeyWithValue("code", "FORBIDDEN"))
+			Expect(err.Error()).To(Equal("graphql: forbidden (path viewer.repos.0); rate limited"))
+			Expect(result.Viewer.Login).To(Equal("octocat"))
+		})
+
+		It("should decode GraphQL errors sent with an error status", func() {
+			err := newResponse(400, `{"errors": [{"message": "syntax error"}]}`).GraphQL(nil)
+			var gqlErr *core.GraphQLError
+			Expect(errors.As(err, &gqlErr)).To(BeTrue())
+			Expect(gqlErr.StatusCode).To(Equal(400))
+		})
+
+		It("should report error statuses without a GraphQL body", func() {
+			err := newResponse(502, `<html>bad gateway</html>`).GraphQL(nil)
+			Expect(err).To(MatchError(ContainSubstring("graphql request failed with status 502")))
+		})
+	})
+
+	Context("WithReadTimeout", func() {
+		// streamingBody writes each chunk into a pipe after delay
+		streamingBody := func(delay time.Duration, chunks ...string) io.ReadCloser {
+			pr, pw := io.Pipe()
+			go func() {
+				for _, chunk := range chunks {
+					time.Sleep(delay)
+					if _, err := pw.Write([]byte(chunk)); err != nil {
+						return
+					}
+				}
+				_ = pw.Close()
+			}()
+			return pr
+		}
+
+		It("should abort a read that stalls beyond the timeout", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       streamingBody(500*time.Millisecond, "late"),
+			}}
+
+			start := time.Now()
+			err := response.StreamChunks(func([]byte) {}, core.WithReadTimeout(50*time.Millisecond))
+			Expect(err).To(MatchError(core.ErrStreamReadTimeout))
+			Expect(err).To(MatchError(os.ErrDeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", 400*time.Millisecond))
+		})
+
+		It("should restart the deadline for every read of a steady body", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       streamingBody(20*time.Millisecond, "a", "b", "c", "d", "e", "f", "g", "h"),
+			}}
+
+			var received string
+			err := response.StreamChunksWithContext(context.Background(), func(chunk []byte) {
+				received += string(chunk)
+			}, core.WithReadTimeout(100*time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received).To(Equal("abcdefgh"))
+		})
+	})
+
+	Context("SaveToFileWithProgress", func() {
+		type report struct{ written, total int64 }
+
+		var (
+			path    string
+			reports []report
+		)
+
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "download.bin")
+			reports = nil
+		})
+
+		onProgress := func(written, total int64) {
+			reports = append(reports, report{written, total})
+		}
+
+		It("should report throttled progress against Content-Length and a final total", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode:    200,
+				ContentLength: 10,
+				Body:          io.NopCloser(iotest.OneByteReader(strings.NewReader("0123456789"))),
+			}}
+
+			err := response.SaveToFileWithProgress(path, onProgress, core.WithProgressThrottle(0, 4))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reports).To(Equal([]report{{4, 10}, {8, 10}, {10, 10}}))
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("0123456789"))
+		})
+
+		It("should report an unknown total as -1", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode:    200,
+				ContentLength: -1,
+				Body:          io.NopCloser(strings.NewReader("abc")),
+			}}
+
+			Expect(response.SaveToFileWithProgress(path, onProgress)).To(Succeed())
+			Expect(reports).To(Equal([]report{{3, -1}}))
+		})
+
+		It("should remove the partial file when the download fails", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       test.NewStreamErrorReader([]byte("partial"), errors.New("connection reset")),
+			}}
+
+			err := response.SaveToFileWithProgress(path, onProgress)
+			Expect(err).To(MatchError(ContainSubstring("connection reset")))
+			_, statErr := os.Stat(path)
+			Expect(os.IsNotExist(statErr)).To(BeTrue())
+		})
+
+		It("should keep the partial file with WithKeepPartialFile", func() {
+			response := &core.Response{Response: &http.Response{
+				StatusCode: 200,
+				Body:       test.NewStreamErrorReader([]byte("partial"), errors.New("connection reset")),
+			}}
+
+			err := response.SaveToFileWithProgress(path, onProgress, core.WithKeepPartialFile())
+			Expect(err).To(HaveOccurred())
+			data, readErr := os.ReadFile(path)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("partial"))
+		})
+	})
+
+	Context("CaptureBody", func() {
+		It("should capture the body prefix without altering the stream", func() {
+			body := io.NopCloser(strings.NewReader("0123456789abcdef"))
+			resp := &core.Response{Response: &http.Response{StatusCode: 200, Body: body}}
+			resp.CaptureBody(6)
+			Expect(resp.CapturedBody()).To(BeEmpty())
+
+			var streamed strings.Builder
+			err := resp.StreamChunks(func(chunk []byte) {
+				streamed.Write(chunk)
+			}, core.WithBufferSize(4))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(streamed.String()).To(Equal("0123456789abcdef"))
+			Expect(string(resp.CapturedBody())).To(Equal("012345"))
+			Expect(resp.CloseBody()).To(Succeed())
+		})
+
+		It("should return nil when capturing is not enabled", func() {
+			resp := &core.Response{Response: &http.Response{Body: io.NopCloser(strings.NewReader("x"))}}
+			Expect(resp.CapturedBody()).To(BeNil())
+		})
+	})
+	Context("Buffered responses", func() {
+		It("should allow JSON, String and Bytes on the same response", func() {
+			resp := core.NewBufferedResponse(&http.Response{StatusCode: 200}, []byte(`{"message":"hello"}`))
+			Expect(resp.IsBuffered()).To(BeTrue())
+			Expect(resp.ContentLength).To(Equal(int64(19)))
+
+			var result map[string]string
+			Expect(resp.JSON(&result)).To(Succeed())
+			Expect(result).To(HaveKeyWithValue("message", "hello"))
+
+			str, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(str).To(Equal(`{"message":"hello"}`))
+
+			data, err := resp.Bytes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"message":"hello"}`))
+			Expect(resp.BodyString()).To(Equal(`{"message":"hello"}`))
+		})
+
+		It("should report unbuffered responses", func() {
+			resp := &core.Response{Response: &http.Response{Body: io.NopCloser(strings.NewReader("x"))}}
+			Expect(resp.IsBuffered()).To(BeFalse())
+			Expect(resp.BodyString()).To(BeEmpty())
+		})
+	})
 })
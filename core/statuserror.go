@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultStatusErrorBodyLimit is the number of response body bytes NewStatusError keeps.
+const DefaultStatusErrorBodyLimit = 64 << 10
+
+// StatusError represents an error due to an unexpected HTTP status code.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	// Header holds a copy of the response headers.
+	Header http.Header
+	// Body holds the leading bytes of the response body, up to the limit it was created with.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d (%s) for %s", e.StatusCode, e.Status, e.URL)
+}
+
+// NewStatusError creates a new error for unexpected status codes, keeping up to
+// DefaultStatusErrorBodyLimit bytes of the body. URL is left empty when the response does
+// not carry its request, as with buffered responses.
+func NewStatusError(resp *Response) *StatusError {
+	return NewStatusErrorWithLimit(resp, DefaultStatusErrorBodyLimit)
+}
+
+// NewStatusErrorWithLimit is like NewStatusError but keeps up to limit bytes of the body, or
+// none when limit is zero or less. The response body stays readable in full: buffered bodies
+// are left as they are, and the bytes read from streamed ones are put back in front of the rest.
+func NewStatusErrorWithLimit(resp *Response, limit int64) *StatusError {
+	statusErr := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		statusErr.URL = resp.Request.URL.String()
+	}
+	if resp.Header != nil {
+		statusErr.Header = resp.Header.Clone()
+	}
+	if limit > 0 {
+		statusErr.Body = resp.peekBody(limit)
+	}
+	return statusErr
+}
+
+// peekBody returns up to limit leading bytes of the body without consuming them.
+func (r *Response) peekBody(limit int64) []byte {
+	if r.buffered != nil {
+		if int64(len(r.buffered)) > limit {
+			return append([]byte(nil), r.buffered[:limit]...)
+		}
+		return append([]byte(nil), r.buffered...)
+	}
+	if r.Response == nil || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	prefix, _ := io.ReadAll(io.LimitReader(r.Body, limit))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	return prefix
+}
@@ -1,10 +1,11 @@
 package core
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
-	"net/url"
 	"time"
 
 	"golang.org/x/net/http2"
@@ -110,12 +111,9 @@ func NewTransport(config TransportConfig) (*http.Transport, error) {
 
 	// Configure proxy if provided
 	if config.ProxyURL != "" {
-		proxyURL, err := http.DefaultTransport.(*http.Transport).Proxy(
-			&http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}})
-		if err != nil {
-			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		if err := configureProxy(tr, config.ProxyURL); err != nil {
+			return nil, err
 		}
-		tr.Proxy = http.ProxyURL(proxyURL)
 	}
 
 	// Enable HTTP/2 for this transport
@@ -128,6 +126,39 @@ func NewTransport(config TransportConfig) (*http.Transport, error) {
 	return tr, nil
 }
 
+// configureProxy routes tr through the proxy at rawURL by setting tr.Proxy; net/http speaks
+// to http, https and socks5 proxies itself, so the dialer is left untouched.
+func configureProxy(tr *http.Transport, rawURL string) error {
+	proxyURL, err := parseProxyURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+		tr.Proxy = http.ProxyURL(proxyURL)
+	default:
+		return fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", rawURL, proxyURL.Scheme)
+	}
+	return nil
+}
+
+// NewUnixSocketTransport creates an http.Transport that dials the UNIX domain socket at socketPath
+// for every request, as needed to reach local daemons such as Docker. The host of request URLs is
+// not used for dialing but is still sent in the Host header, so URLs like
+// "http://unix/v1.41/containers/json" work. Proxies are never used.
+func NewUnixSocketTransport(socketPath string) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
 // TLSTransport is a wrapper around http.Transport that is configured for TLS and HTTP/2.
 type TLSTransport struct {
 	Transport *http.Transport
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"github.com/jzx17/gofetch/core"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -123,3 +126,91 @@ var _ = Describe("TLSTransport", func() {
 		Expect(err.Error()).To(ContainSubstring("connect"))
 	})
 })
+
+var _ = Describe("NewTransport", func() {
+	It("should send requests through an HTTP proxy", func() {
+		proxied := make(chan string, 1)
+		proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied <- r.URL.String()
+			_, _ = io.WriteString(w, "via proxy")
+		}))
+		defer proxyServer.Close()
+
+		config := core.DefaultTransportConfig()
+		config.ProxyURL = proxyServer.URL
+		tr, err := core.NewTransport(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := (&http.Client{Transport: tr}).Get("http://upstream.invalid/path")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("via proxy"))
+		Expect(proxied).To(Receive(Equal("http://upstream.invalid/path")))
+	})
+
+	It("should dial a socks5 proxy", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		greeting := make(chan byte, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			version := make([]byte, 1)
+			if _, err := io.ReadFull(conn, version); err == nil {
+				greeting <- version[0]
+			}
+		}()
+
+		config := core.DefaultTransportConfig()
+		config.ProxyURL = "socks5://" + listener.Addr().String()
+		tr, err := core.NewTransport(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = (&http.Client{Transport: tr, Timeout: 5 * time.Second}).Get("http://upstream.invalid/")
+		Expect(err).To(HaveOccurred())
+		Expect(greeting).To(Receive(Equal(byte(5))))
+	})
+
+	It("should reject invalid proxy URLs", func() {
+		for _, proxyURL := range []string{"ftp://proxy.example.com", "proxy.example.com:8080", "http://%zz"} {
+			config := core.DefaultTransportConfig()
+			config.ProxyURL = proxyURL
+			_, err := core.NewTransport(config)
+			Expect(err).To(MatchError(ContainSubstring("invalid proxy URL")), proxyURL)
+		}
+	})
+})
+
+var _ = Describe("NewUnixSocketTransport", func() {
+	It("should route requests over the socket and keep the URL host in the Host header", func() {
+		// Socket paths are limited to about 100 bytes, so avoid the long per-test directory
+		dir, err := os.MkdirTemp("", "gofetch")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		socketPath := filepath.Join(dir, "daemon.sock")
+
+		listener, err := net.Listen("unix", socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %s", r.Host, r.URL.Path)
+		})}
+		go func() { _ = server.Serve(listener) }()
+		defer server.Close()
+
+		client := &http.Client{Transport: core.NewUnixSocketTransport(socketPath)}
+		resp, err := client.Get("http://unix/v1.41/containers/json")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("unix /v1.41/containers/json"))
+	})
+})
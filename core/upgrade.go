@@ -0,0 +1,40 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrNotUpgraded is returned by UpgradedConn for responses that did not switch protocols.
+var ErrNotUpgraded = errors.New("response did not switch protocols")
+
+// WithUpgrade asks the server to switch the connection to protocol, e.g. "websocket", by
+// setting the Connection and Upgrade headers. Protocol-specific handshake headers, such as
+// Sec-WebSocket-Key, are left to the caller.
+func (r *Request) WithUpgrade(protocol string) *Request {
+	r.WithHeader("Connection", "Upgrade")
+	r.WithHeader("Upgrade", protocol)
+	return r
+}
+
+// IsSwitchingProtocols reports whether the server accepted a connection upgrade with 101.
+func (r *Response) IsSwitchingProtocols() bool {
+	return r.Response != nil && r.StatusCode == http.StatusSwitchingProtocols
+}
+
+// UpgradedConn returns the raw connection of a 101 Switching Protocols response, for speaking
+// the upgraded protocol. Reads include any bytes the server sent right after its handshake.
+// Closing the connection, or the response body, closes the underlying network connection.
+// A middleware that wraps the response body hides the connection and makes this fail.
+func (r *Response) UpgradedConn() (io.ReadWriteCloser, error) {
+	if !r.IsSwitchingProtocols() {
+		return nil, ErrNotUpgraded
+	}
+	conn, ok := r.Body.(io.ReadWriteCloser)
+	if !ok {
+		return nil, fmt.Errorf("upgraded response body %T does not expose the connection", r.Body)
+	}
+	return conn, nil
+}
@@ -0,0 +1,50 @@
+package gofetch
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type requestSequenceKey struct{}
+
+// WithRequestCounter enables a client-level monotonic counter. Every request sent through
+// the client is assigned the next sequence number, starting at 1. The number is carried by the
+// context seen by middlewares and the transport, and not stored on the Request, so one Request
+// may be sent concurrently.
+func WithRequestCounter() Option {
+	return func(c *Client) {
+		c.counter = new(atomic.Uint64)
+	}
+}
+
+// RequestCount returns the number of sequence numbers assigned so far.
+// It returns 0 if the client was not configured with WithRequestCounter.
+func (c *Client) RequestCount() uint64 {
+	if c.counter == nil {
+		return 0
+	}
+	return c.counter.Load()
+}
+
+// assignSequence assigns the next sequence number and returns a context carrying it.
+func (c *Client) assignSequence(ctx context.Context) context.Context {
+	if c.counter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestSequenceKey{}, c.counter.Add(1))
+}
+
+// RequestSequenceFromContext returns the sequence number assigned to the request that owns ctx.
+// Middlewares can call it with the *http.Request context.
+func RequestSequenceFromContext(ctx context.Context) (uint64, bool) {
+	seq, ok := ctx.Value(requestSequenceKey{}).(uint64)
+	return seq, ok
+}
+
+// RequestSequence returns the sequence number assigned to the request that produced resp.
+func RequestSequence(resp *Response) (uint64, bool) {
+	if resp == nil || resp.Response == nil || resp.Request == nil {
+		return 0, false
+	}
+	return RequestSequenceFromContext(resp.Request.Context())
+}
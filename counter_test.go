@@ -0,0 +1,95 @@
+package gofetch_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request Counter", func() {
+	var mockTransport core.RoundTripFunc
+
+	BeforeEach(func() {
+		mockTransport = func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("ok")),
+				Request:    req,
+			}, nil
+		}
+	})
+
+	It("should not assign sequence numbers unless enabled", func() {
+		client := gofetch.NewClient(gofetch.WithTransport(mockTransport))
+		req := core.NewRequest("GET", "http://example.com")
+
+		resp, err := client.Do(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, ok := gofetch.RequestSequence(resp)
+		Expect(ok).To(BeFalse())
+		Expect(client.RequestCount()).To(BeZero())
+	})
+
+	It("should assign sequential numbers without duplicates across concurrent requests", func() {
+		var mu sync.Mutex
+		seen := map[uint64]int{}
+		recorder := gofetch.CreateMiddleware("sequence-recorder", nil,
+			func(next gofetch.RoundTripFunc) gofetch.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					seq, ok := gofetch.RequestSequenceFromContext(req.Context())
+					Expect(ok).To(BeTrue())
+					mu.Lock()
+					seen[seq]++
+					mu.Unlock()
+					return next(req)
+				}
+			})
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(mockTransport),
+			gofetch.WithMiddlewares(recorder),
+			gofetch.WithRequestCounter(),
+		)
+
+		// One Request sent concurrently must still get a distinct number per call
+		const total = 50
+		req := core.NewRequest("GET", "http://example.com")
+		responses := make([]*gofetch.Response, total)
+		var wg sync.WaitGroup
+		for i := 0; i < total; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				resp, err := client.Do(context.Background(), req)
+				Expect(err).NotTo(HaveOccurred())
+				responses[i] = resp
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(client.RequestCount()).To(Equal(uint64(total)))
+		Expect(seen).To(HaveLen(total))
+		for seq := uint64(1); seq <= total; seq++ {
+			Expect(seen[seq]).To(Equal(1))
+		}
+
+		assigned := map[uint64]bool{}
+		for _, resp := range responses {
+			seq, ok := gofetch.RequestSequence(resp)
+			Expect(ok).To(BeTrue())
+			assigned[seq] = true
+		}
+		Expect(assigned).To(HaveLen(total))
+	})
+})
@@ -0,0 +1,80 @@
+package gofetch
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithDefaultQueryParams adds query parameters to every request sent by the client,
+// such as an api_key or version. Parameters already present on a request take precedence.
+func WithDefaultQueryParams(params map[string]string) Option {
+	return func(c *Client) {
+		if c.defaultQueryParams == nil {
+			c.defaultQueryParams = make(map[string]string, len(params))
+		}
+		for k, v := range params {
+			c.defaultQueryParams[k] = v
+		}
+	}
+}
+
+// WithDefaultHeaders sets headers, such as User-Agent or Accept, on every request sent by
+// the client. Headers already set on a request take precedence, so a multipart Content-Type
+// with its boundary is never replaced. Cookies from a cookie jar are added alongside a
+// default Cookie header.
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		if c.defaultHeaders == nil {
+			c.defaultHeaders = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			c.defaultHeaders[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+}
+
+// applyDefaults adds the client's defaults to an outgoing request without overriding its own values.
+func (c *Client) applyDefaults(httpReq *http.Request) {
+	c.resolveBaseURL(httpReq)
+	for k, v := range c.defaultHeaders {
+		if _, ok := httpReq.Header[k]; !ok {
+			httpReq.Header.Set(k, v)
+		}
+	}
+	if len(c.defaultQueryParams) > 0 {
+		missing := url.Values{}
+		for k, v := range c.defaultQueryParams {
+			missing.Set(k, v)
+		}
+		addMissingQuery(httpReq.URL, missing)
+	}
+}
+
+// resolveBaseURL resolves a relative request URL against the base set with WithBaseURL,
+// carrying over the query parameters of the base.
+func (c *Client) resolveBaseURL(httpReq *http.Request) {
+	if c.baseURL == nil || httpReq.URL.IsAbs() {
+		return
+	}
+	httpReq.URL = c.baseURL.ResolveReference(httpReq.URL)
+	addMissingQuery(httpReq.URL, c.baseURL.Query())
+}
+
+// addMissingQuery appends the params that u does not already carry. Only the missing
+// parameters are appended so the request's own encoding, which may be canonical for
+// signing, is left intact.
+func addMissingQuery(u *url.URL, params url.Values) {
+	q := u.Query()
+	missing := url.Values{}
+	for k, vs := range params {
+		if _, ok := q[k]; !ok {
+			missing[k] = vs
+		}
+	}
+	if len(missing) > 0 {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += missing.Encode()
+	}
+}
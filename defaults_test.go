@@ -0,0 +1,197 @@
+package gofetch_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client Defaults", func() {
+	var (
+		lastQuery     url.Values
+		lastURL       string
+		lastHeader    http.Header
+		mockTransport core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		lastQuery = nil
+		mockTransport = func(req *http.Request) (*http.Response, error) {
+			lastQuery = req.URL.Query()
+			lastURL = req.URL.String()
+			lastHeader = req.Header.Clone()
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("ok")),
+			}, nil
+		}
+	})
+
+	Context("WithDefaultQueryParams", func() {
+		It("should add defaults to requests that do not set them", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultQueryParams(map[string]string{"api_key": "secret", "version": "2"}),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com/items").WithQueryParam("page", "3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastQuery.Get("api_key")).To(Equal("secret"))
+			Expect(lastQuery.Get("version")).To(Equal("2"))
+			Expect(lastQuery.Get("page")).To(Equal("3"))
+		})
+
+		It("should let per-request values override defaults", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultQueryParams(map[string]string{"version": "2"}),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com").WithQueryParam("version", "3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastQuery["version"]).To(Equal([]string{"3"}))
+
+			_, err = client.Do(context.Background(), core.NewRequest("GET", "http://example.com?version=4"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastQuery["version"]).To(Equal([]string{"4"}))
+		})
+
+		It("should apply defaults to streamed requests", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultQueryParams(map[string]string{"api_key": "secret"}),
+			)
+
+			resp, err := client.DoStream(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.CloseBody()
+			Expect(lastQuery.Get("api_key")).To(Equal("secret"))
+		})
+	})
+
+	Context("WithBaseURL", func() {
+		It("should resolve relative request URLs against the base", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithBaseURL("https://api.example.com"),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "/v1/users"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastURL).To(Equal("https://api.example.com/v1/users"))
+
+			_, err = client.Get(context.Background(), "/v1/teams", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastURL).To(Equal("https://api.example.com/v1/teams"))
+
+			resp, err := client.DoStream(context.Background(), core.NewRequest("GET", "/v1/stream"))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.CloseBody()
+			Expect(lastURL).To(Equal("https://api.example.com/v1/stream"))
+		})
+
+		It("should replace the base path with an absolute request path", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithBaseURL("https://api.example.com/v2/"),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "/users/42"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastURL).To(Equal("https://api.example.com/users/42"))
+		})
+
+		It("should send absolute request URLs as is", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithBaseURL("https://api.example.com?api_key=secret"),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://other.example.com/health"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastURL).To(Equal("http://other.example.com/health"))
+		})
+
+		It("should merge base and request query params, preferring the request", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithBaseURL("https://api.example.com/?api_key=secret&version=1"),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "/v1/users?page=2").WithQueryParam("version", "2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastQuery).To(Equal(url.Values{
+				"api_key": {"secret"},
+				"page":    {"2"},
+				"version": {"2"},
+			}))
+		})
+
+		It("should panic on an invalid base URL", func() {
+			for _, base := range []string{"://bad", "/relative/only", "api.example.com"} {
+				Expect(func() { gofetch.WithBaseURL(base) }).To(Panic(), base)
+			}
+		})
+	})
+
+	Context("WithDefaultHeaders", func() {
+		It("should add default headers unless the request sets them", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultHeaders(map[string]string{"user-agent": "myapp/1.0", "Accept": "application/json"}),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastHeader.Get("User-Agent")).To(Equal("myapp/1.0"))
+			Expect(lastHeader.Get("Accept")).To(Equal("application/json"))
+
+			resp, err := client.DoStream(context.Background(), core.NewRequest("GET", "http://example.com").WithHeader("Accept", "text/csv"))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.CloseBody()
+			Expect(lastHeader.Get("User-Agent")).To(Equal("myapp/1.0"))
+			Expect(lastHeader.Values("Accept")).To(Equal([]string{"text/csv"}))
+		})
+
+		It("should keep the multipart Content-Type boundary", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultHeaders(map[string]string{"Content-Type": "application/json"}),
+			)
+
+			req := core.NewRequest("POST", "http://example.com").
+				WithMultipartForm(map[string]string{"name": "gofetch"}, nil)
+			_, err := client.Do(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastHeader.Get("Content-Type")).To(HavePrefix("multipart/form-data; boundary="))
+		})
+
+		It("should compose with a cookie jar", func() {
+			jar, err := cookiejar.New(nil)
+			Expect(err).NotTo(HaveOccurred())
+			u, _ := url.Parse("http://example.com")
+			jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "abc"}})
+
+			client := gofetch.NewClient(
+				gofetch.WithHTTPClient(&http.Client{Jar: jar}),
+				gofetch.WithTransport(mockTransport),
+				gofetch.WithDefaultHeaders(map[string]string{"User-Agent": "myapp/1.0", "Cookie": "theme=dark"}),
+			)
+
+			_, err = client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastHeader.Get("User-Agent")).To(Equal("myapp/1.0"))
+			Expect(lastHeader.Get("Cookie")).To(Equal("theme=dark; session=abc"))
+		})
+	})
+})
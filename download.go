@@ -0,0 +1,185 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DownloadResumable downloads url to path, resuming a previous partial download. The validator
+// of the download, its strong ETag or else its Last-Modified date, is kept next to the file in
+// path+".validator". When path already holds data and a validator was kept, only the remaining
+// bytes are requested, with a Range header guarded by If-Range so a resource that changed since
+// is sent whole. A 200 response rewrites the file from scratch, as does a partial file without
+// a validator, since it cannot be known to match the resource. A 206 response must start
+// exactly where the file ends, as stated by its Content-Range. A 416 response whose
+// Content-Range total equals the file size means the file is already complete. Bodies are
+// requested without content encoding so byte offsets match the file. A failed download leaves
+// the partial file and its validator in place, ready to be resumed by calling again; a
+// completed one removes the validator.
+func (c *Client) DownloadResumable(ctx context.Context, url, path string) error {
+	validatorPath := path + ".validator"
+
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	var validator string
+	if offset > 0 {
+		data, err := os.ReadFile(validatorPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", validatorPath, err)
+		}
+		validator = strings.TrimSpace(string(data))
+	}
+
+	req := NewRequest("GET", url).WithHeader("Accept-Encoding", "identity")
+	if validator != "" {
+		req.WithHeader("Range", fmt.Sprintf("bytes=%d-", offset)).WithHeader("If-Range", validator)
+	}
+
+	resp, err := c.DoStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if err := saveValidator(validatorPath, resp); err != nil {
+			_ = resp.CloseBody()
+			return err
+		}
+		err = resp.SaveToFile(path)
+	case resp.StatusCode == http.StatusPartialContent && validator != "":
+		err = appendRange(resp, path, offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && validator != "" && rangeTotal(resp) == offset:
+		err = resp.CloseBody()
+	default:
+		statusErr := NewStatusError(resp)
+		_ = resp.CloseBody()
+		return statusErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(validatorPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", validatorPath, err)
+	}
+	return nil
+}
+
+// saveValidator stores the validator of a full response at path, before its body is written, so
+// an interrupted download can be resumed. A response without a usable validator removes any
+// stale one instead: weak ETags are not allowed in If-Range.
+func saveValidator(path string, resp *Response) error {
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	if validator == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(validator), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// appendRange appends a 206 response body to the partial file at path after checking that
+// its Content-Range continues at offset.
+func appendRange(resp *Response, path string, offset int64) (err error) {
+	defer func() {
+		if closeErr := resp.CloseBody(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	start, end, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return err
+	}
+	if start != offset || (total >= 0 && end != total-1) {
+		return fmt.Errorf("unexpected Content-Range %q for a download resumed at byte %d",
+			resp.Header.Get("Content-Range"), offset)
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength != end-start+1 {
+		return fmt.Errorf("response Content-Length %d does not match Content-Range %q",
+			resp.ContentLength, resp.Header.Get("Content-Range"))
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file %s: %w", path, closeErr)
+		}
+	}()
+
+	written, err := io.Copy(f, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to save response to file %s: %w", path, err)
+	}
+	if written != end-start+1 {
+		return fmt.Errorf("received %d bytes for Content-Range %q", written, resp.Header.Get("Content-Range"))
+	}
+	return nil
+}
+
+// rangeTotal returns the complete length announced by the Content-Range of resp, or -1.
+func rangeTotal(resp *Response) int64 {
+	_, _, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// parseContentRange parses a "bytes start-end/total" or "bytes */total" Content-Range value.
+// start and end are -1 for the unsatisfied form and total is -1 when given as "*".
+func parseContentRange(value string) (start, end, total int64, err error) {
+	invalid := fmt.Errorf("invalid Content-Range %q", value)
+
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+	rangePart, totalPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+
+	total = -1
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil || total < 0 {
+			return 0, 0, 0, invalid
+		}
+	}
+	if rangePart == "*" {
+		return -1, -1, total, nil
+	}
+
+	first, last, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, 0, invalid
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, invalid
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start || (total >= 0 && end >= total) {
+		return 0, 0, 0, invalid
+	}
+	return start, end, total, nil
+}
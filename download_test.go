@@ -0,0 +1,174 @@
+package gofetch_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jzx17/gofetch"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DownloadResumable", func() {
+	const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	var (
+		path    string
+		ranges  []string
+		ifRange []string
+		handler http.HandlerFunc
+		server  *httptest.Server
+		client  *gofetch.Client
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "artifact.bin")
+		ranges = nil
+		ifRange = nil
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "artifact.bin", time.Time{}, strings.NewReader(content))
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			ifRange = append(ifRange, r.Header.Get("If-Range"))
+			handler(w, r)
+		}))
+		client = gofetch.NewClient()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	expectFile := func(expected string) {
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(expected))
+	}
+
+	// savePartial leaves a partial download of data with the given validator
+	savePartial := func(data, validator string) {
+		Expect(os.WriteFile(path, []byte(data), 0o644)).To(Succeed())
+		Expect(os.WriteFile(path+".validator", []byte(validator), 0o644)).To(Succeed())
+	}
+
+	It("should download the whole file when nothing was saved yet", func() {
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		Expect(ranges).To(Equal([]string{""}))
+		expectFile(content)
+		Expect(path + ".validator").NotTo(BeAnExistingFile())
+	})
+
+	It("should request and append only the missing bytes", func() {
+		savePartial(content[:10], `"v1"`)
+
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		Expect(ranges).To(Equal([]string{"bytes=10-"}))
+		Expect(ifRange).To(Equal([]string{`"v1"`}))
+		expectFile(content)
+		Expect(path + ".validator").NotTo(BeAnExistingFile())
+	})
+
+	It("should start over when the resource changed since the partial download", func() {
+		savePartial("stale data", `"v0"`)
+
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		Expect(ranges).To(Equal([]string{"bytes=10-"}))
+		expectFile(content)
+	})
+
+	It("should start over when the partial file has no validator", func() {
+		Expect(os.WriteFile(path, []byte("stale data"), 0o644)).To(Succeed())
+
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		Expect(ranges).To(Equal([]string{""}))
+		expectFile(content)
+	})
+
+	It("should start over when the server ignores the Range header", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, content)
+		}
+		savePartial("stale", `"v1"`)
+
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		expectFile(content)
+	})
+
+	It("should treat an unsatisfiable range at the end of the file as complete", func() {
+		savePartial(content, `"v1"`)
+
+		Expect(client.DownloadResumable(context.Background(), server.URL, path)).To(Succeed())
+		Expect(ranges).To(Equal([]string{"bytes=36-"}))
+		expectFile(content)
+	})
+
+	It("should reject a Content-Range that does not continue the partial file", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", "bytes 0-35/36")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = io.WriteString(w, content)
+		}
+		savePartial(content[:10], `"v1"`)
+
+		err := client.DownloadResumable(context.Background(), server.URL, path)
+		Expect(err).To(MatchError(ContainSubstring("unexpected Content-Range")))
+		expectFile(content[:10])
+	})
+
+	It("should keep the partial file when the body is cut short", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", "bytes 10-35/36")
+			w.Header().Set("Content-Length", "26")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = io.WriteString(w, content[10:20])
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}
+		savePartial(content[:10], `"v1"`)
+
+		err := client.DownloadResumable(context.Background(), server.URL, path)
+		Expect(err).To(HaveOccurred())
+		data, readErr := os.ReadFile(path)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(bytes.HasPrefix([]byte(content), data)).To(BeTrue())
+	})
+
+	It("should keep the validator of a first download that is cut short", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "36")
+			_, _ = io.WriteString(w, content[:10])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}
+
+		err := client.DownloadResumable(context.Background(), server.URL, path)
+		Expect(err).To(HaveOccurred())
+		data, readErr := os.ReadFile(path)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(bytes.HasPrefix([]byte(content), data)).To(BeTrue())
+		validator, readErr := os.ReadFile(path + ".validator")
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(string(validator)).To(Equal(`"v1"`))
+	})
+
+	It("should report error statuses as a StatusError", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}
+
+		err := client.DownloadResumable(context.Background(), server.URL, path)
+		Expect(gofetch.IsStatusError(err, http.StatusNotFound)).To(BeTrue())
+	})
+})
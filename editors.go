@@ -0,0 +1,28 @@
+package gofetch
+
+import (
+	"net/http"
+)
+
+// RequestEditorFunc mutates a fully built *http.Request just before it is sent.
+type RequestEditorFunc func(req *http.Request) error
+
+// WithRequestEditorFunc registers editors that run, in order, on every fully built
+// *http.Request right before it is handed to the transport. They can set anything net/http
+// supports that the Request builder does not expose, such as Host, Trailer or GetBody.
+// An editor error aborts the call with a RequestError.
+func WithRequestEditorFunc(editors ...RequestEditorFunc) Option {
+	return func(c *Client) {
+		c.requestEditors = append(c.requestEditors, editors...)
+	}
+}
+
+// applyEditors runs the client's request editors, stopping at the first error.
+func (c *Client) applyEditors(httpReq *http.Request) error {
+	for _, edit := range c.requestEditors {
+		if err := edit(httpReq); err != nil {
+			return NewRequestError("edit request", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gofetch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request Editors", func() {
+	var (
+		server   *httptest.Server
+		seenHost string
+	)
+
+	BeforeEach(func() {
+		seenHost = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenHost = r.Host
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should apply editors to the built request", func() {
+		client := gofetch.NewClient(gofetch.WithRequestEditorFunc(func(req *http.Request) error {
+			req.Host = "api.internal.example"
+			return nil
+		}))
+
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(seenHost).To(Equal("api.internal.example"))
+	})
+
+	It("should run editors in order", func() {
+		var order []int
+		client := gofetch.NewClient(gofetch.WithRequestEditorFunc(
+			func(req *http.Request) error { order = append(order, 1); return nil },
+			func(req *http.Request) error { order = append(order, 2); return nil },
+		))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+		Expect(order).To(Equal([]int{1, 2}))
+	})
+
+	It("should abort with a RequestError when an editor fails", func() {
+		editErr := errors.New("missing signature")
+		client := gofetch.NewClient(gofetch.WithRequestEditorFunc(func(req *http.Request) error {
+			return editErr
+		}))
+
+		_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(errors.Is(err, editErr)).To(BeTrue())
+
+		var clientErr *gofetch.ClientError
+		Expect(errors.As(err, &clientErr)).To(BeTrue())
+		Expect(clientErr.Phase).To(Equal("request"))
+		Expect(seenHost).To(BeEmpty())
+	})
+})
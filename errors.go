@@ -3,6 +3,8 @@ package gofetch
 import (
 	"errors"
 	"fmt"
+
+	"github.com/jzx17/gofetch/core"
 )
 
 // ClientError represents an error that occurred during request execution.
@@ -51,23 +53,33 @@ func NewResponseError(msg string, cause error) error {
 }
 
 // StatusError represents an error due to an unexpected HTTP status code.
-type StatusError struct {
-	StatusCode int
-	Status     string
-	URL        string
+type StatusError = core.StatusError
+
+// DefaultStatusErrorBodyLimit is the number of response body bytes NewStatusError keeps.
+const DefaultStatusErrorBodyLimit = core.DefaultStatusErrorBodyLimit
+
+// NewStatusError creates a new error for unexpected status codes, keeping the response headers
+// and up to DefaultStatusErrorBodyLimit bytes of the body.
+var NewStatusError = core.NewStatusError
+
+// NewStatusErrorWithLimit is like NewStatusError but keeps up to limit bytes of the body.
+var NewStatusErrorWithLimit = core.NewStatusErrorWithLimit
+
+// TruncatedResponseError reports a response body that ended before the number of bytes
+// declared by its Content-Length, usually because the server or a proxy cut the connection.
+type TruncatedResponseError struct {
+	ExpectedBytes int64
+	ReceivedBytes int64
+	Err           error // underlying read error, such as io.ErrUnexpectedEOF; may be nil
 }
 
-func (e *StatusError) Error() string {
-	return fmt.Sprintf("unexpected status code %d (%s) for %s", e.StatusCode, e.Status, e.URL)
+func (e *TruncatedResponseError) Error() string {
+	return fmt.Sprintf("truncated response body: received %d of %d bytes declared by Content-Length",
+		e.ReceivedBytes, e.ExpectedBytes)
 }
 
-// NewStatusError creates a new error for unexpected status codes.
-func NewStatusError(resp *Response) *StatusError {
-	return &StatusError{
-		StatusCode: resp.StatusCode,
-		Status:     resp.Status,
-		URL:        resp.Request.URL.String(),
-	}
+func (e *TruncatedResponseError) Unwrap() error {
+	return e.Err
 }
 
 // IsStatusError checks if an error is a StatusError with a specific code.
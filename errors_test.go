@@ -9,7 +9,9 @@ import (
 	"github.com/jzx17/gofetch/core"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -178,6 +180,68 @@ var _ = Describe("Error Handling", func() {
 		Expect(err.Error()).To(ContainSubstring("response"))
 		Expect(err.Error()).To(ContainSubstring("read failed"))
 	})
+
+	Context("Truncated responses", func() {
+		shortTransport := func(body io.Reader, declared int64) core.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    200,
+					Status:        "200 OK",
+					ContentLength: declared,
+					Body:          io.NopCloser(body),
+				}, nil
+			}
+		}
+
+		It("should report a body shorter than its Content-Length", func() {
+			client := gofetch.NewClient(gofetch.WithTransport(shortTransport(strings.NewReader("short"), 10)))
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+
+			var truncated *gofetch.TruncatedResponseError
+			Expect(errors.As(err, &truncated)).To(BeTrue())
+			Expect(truncated.ExpectedBytes).To(Equal(int64(10)))
+			Expect(truncated.ReceivedBytes).To(Equal(int64(5)))
+			Expect(err.Error()).To(ContainSubstring("received 5 of 10 bytes"))
+		})
+
+		It("should keep the underlying unexpected EOF", func() {
+			body := io.MultiReader(strings.NewReader("short"), &errorReader{err: io.ErrUnexpectedEOF})
+			client := gofetch.NewClient(gofetch.WithTransport(shortTransport(body, 10)))
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+
+			var truncated *gofetch.TruncatedResponseError
+			Expect(errors.As(err, &truncated)).To(BeTrue())
+			Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+		})
+
+		It("should detect a server that closes the connection early", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, buf, err := w.(http.Hijacker).Hijack()
+				Expect(err).NotTo(HaveOccurred())
+				_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\npartial")
+				_ = buf.Flush()
+				_ = conn.Close()
+			}))
+			defer server.Close()
+
+			_, err := gofetch.NewClient().Do(context.Background(), core.NewRequest("GET", server.URL))
+
+			var truncated *gofetch.TruncatedResponseError
+			Expect(errors.As(err, &truncated)).To(BeTrue())
+			Expect(truncated.ExpectedBytes).To(Equal(int64(100)))
+			Expect(truncated.ReceivedBytes).To(Equal(int64(7)))
+		})
+
+		It("should accept a body matching its Content-Length", func() {
+			client := gofetch.NewClient(gofetch.WithTransport(shortTransport(strings.NewReader("exact"), 5)))
+
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.String()).To(Equal("exact"))
+		})
+	})
 })
 
 // errorReader is a helper that returns an error when read
@@ -0,0 +1,78 @@
+package gofetch
+
+import (
+	"net/http"
+	"sync"
+)
+
+// hostConcurrencyLimiter gates the number of in-flight requests per host with one
+// ConcurrencyLimitedTransport per host. The transports outlive any one middleware chain, so
+// slots are shared across chains rebuilt by Use and its siblings.
+type hostConcurrencyLimiter struct {
+	limits       map[string]int
+	defaultLimit int
+
+	mu         sync.Mutex
+	next       http.RoundTripper
+	transports map[string]*ConcurrencyLimitedTransport
+}
+
+func newHostConcurrencyLimiter(limits map[string]int, defaultLimit int) *hostConcurrencyLimiter {
+	copied := make(map[string]int, len(limits))
+	for host, n := range limits {
+		copied[host] = n
+	}
+	return &hostConcurrencyLimiter{
+		limits:       copied,
+		defaultLimit: defaultLimit,
+		transports:   make(map[string]*ConcurrencyLimitedTransport),
+	}
+}
+
+// transport returns the limited transport for the request's host, or nil if the host is unlimited.
+func (l *hostConcurrencyLimiter) transport(req *http.Request) *ConcurrencyLimitedTransport {
+	key := req.URL.Host
+	limit, ok := l.limits[key]
+	if !ok {
+		key = req.URL.Hostname()
+		limit, ok = l.limits[key]
+	}
+	if !ok {
+		key = req.URL.Host
+		limit = l.defaultLimit
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, exists := l.transports[key]
+	if !exists {
+		t = NewConcurrencyLimitedTransport(RoundTripFunc(l.forward), limit)
+		l.transports[key] = t
+	}
+	return t
+}
+
+// forward sends req through the RoundTripper most recently passed to wrap.
+func (l *hostConcurrencyLimiter) forward(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	next := l.next
+	l.mu.Unlock()
+	return next.RoundTrip(req)
+}
+
+// wrap returns a RoundTripper that holds a host slot until the response body is closed.
+func (l *hostConcurrencyLimiter) wrap(next http.RoundTripper) http.RoundTripper {
+	l.mu.Lock()
+	l.next = next
+	l.mu.Unlock()
+
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if t := l.transport(req); t != nil {
+			return t.RoundTrip(req)
+		}
+		return next.RoundTrip(req)
+	})
+}
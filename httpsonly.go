@@ -0,0 +1,38 @@
+package gofetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrInsecureScheme is reported when WithHTTPSOnly blocks a request or redirect whose URL is not https.
+var ErrInsecureScheme = errors.New("refusing to send request over a non-https scheme")
+
+// checkScheme rejects u when the client only allows https.
+func (c *Client) checkScheme(u *url.URL) error {
+	if !c.httpsOnly || u == nil || u.Scheme == "https" {
+		return nil
+	}
+	return NewRequestError("enforce https", fmt.Errorf("%w: %s", ErrInsecureScheme, u.Redacted()))
+}
+
+// enforceHTTPSRedirects makes client refuse redirects to non-https URLs, in addition to any
+// redirect policy it already has. The existing policy runs first, so a policy that returns
+// http.ErrUseLastResponse still hands the redirect response to the caller.
+func enforceHTTPSRedirects(client *http.Client) {
+	checkRedirect := client.CheckRedirect
+	if checkRedirect == nil {
+		checkRedirect = defaultRedirectPolicy
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via); err != nil {
+			return err
+		}
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: redirect to %s", ErrInsecureScheme, req.URL.Redacted())
+		}
+		return nil
+	}
+}
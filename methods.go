@@ -40,6 +40,13 @@ func (c *Client) Head(ctx context.Context, url string, headers map[string]string
 	return c.Do(ctx, req)
 }
 
+// Options is a convenience method for sending OPTIONS requests, such as CORS preflights.
+// Inspect the result with Response.AllowedMethods and Response.CORSHeaders.
+func (c *Client) Options(ctx context.Context, url string, headers map[string]string) (*Response, error) {
+	req := NewRequest("OPTIONS", url).WithHeaders(headers)
+	return c.Do(ctx, req)
+}
+
 // PostJSON is a convenience method for sending POST requests with JSON body.
 func (c *Client) PostJSON(ctx context.Context, url string, data interface{}, headers map[string]string) (*Response, error) {
 	req := NewRequest("POST", url).WithJSONBody(data).WithHeaders(headers)
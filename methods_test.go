@@ -44,6 +44,11 @@ var _ = Describe("Convenience Methods", func() {
 			case http.MethodHead:
 				// HEAD should have empty body
 				w.Header().Set("X-Test", "HEAD test")
+			case http.MethodOptions:
+				w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+				w.WriteHeader(http.StatusNoContent)
 			default:
 				w.WriteHeader(http.StatusMethodNotAllowed)
 			}
@@ -158,6 +163,17 @@ var _ = Describe("Convenience Methods", func() {
 		Expect(responseBody).To(BeEmpty())
 	})
 
+	It("should perform OPTIONS convenience method", func() {
+		resp, err := client.Options(ctx, testServer.URL, map[string]string{"Origin": "https://app.example.com"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(resp.AllowedMethods()).To(Equal([]string{"GET", "HEAD", "OPTIONS"}))
+		cors := resp.CORSHeaders()
+		Expect(cors.AllowOrigin).To(Equal("*"))
+		Expect(cors.AllowMethods).To(Equal([]string{"GET", "POST"}))
+	})
+
 	It("should perform PostJSON convenience method", func() {
 		headers := map[string]string{"X-Test-Header": "test-value"}
 		data := map[string]interface{}{
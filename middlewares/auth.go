@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+var _ ConfigurableMiddleware = (*authMiddleware)(nil)
+
+// AuthOptions configures the authentication middleware
+type AuthOptions struct {
+	// TokenSource fetches a bearer token and its expiry. A zero expiry means the token is
+	// used until the server rejects it with a 401.
+	TokenSource func(ctx context.Context) (string, time.Time, error)
+	// ExpiryLeeway refreshes tokens this long before they expire, to absorb clock skew
+	ExpiryLeeway time.Duration
+}
+
+// authMiddleware injects cached bearer tokens and refreshes them on expiry or rejection
+type authMiddleware struct {
+	BaseMiddleware
+	options AuthOptions
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// AuthMiddleware creates a middleware that sets an "Authorization: Bearer" header from
+// options.TokenSource. The token is cached until it expires and refreshed on demand; refreshes
+// are serialized, so concurrent requests share a single fetch. When a response is a 401, the
+// token is refreshed once and the request retried once with the new token. Requests with a body
+// are only retried if the body can be replayed through GetBody.
+func AuthMiddleware(options AuthOptions) ConfigurableMiddleware {
+	mw := &authMiddleware{options: options}
+
+	mw.BaseMiddleware = BaseMiddleware{
+		Identifier: MiddlewareIdentifier{
+			Name:    "auth",
+			Options: options,
+		},
+		Wrapper: mw.roundTrip,
+	}
+
+	return mw
+}
+
+func (m *authMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		token, err := m.currentToken(req.Context(), "")
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(withBearer(req, token))
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		// Rejected: force one refresh, unless another request already replaced the token
+		refreshed, err := m.currentToken(req.Context(), token)
+		if err != nil {
+			DrainAndClose(resp)
+			return nil, err
+		}
+
+		retry := withBearer(req, refreshed)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				DrainAndClose(resp)
+				return nil, fmt.Errorf("failed to get request body for auth retry: %w", err)
+			}
+			retry.Body = body
+		}
+		DrainAndClose(resp)
+		return next(retry)
+	}
+}
+
+// currentToken returns the cached token, fetching a new one if none is cached, it is about to
+// expire, or it equals rejected, the token a server just refused.
+func (m *authMiddleware) currentToken(ctx context.Context, rejected string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	valid := m.token != "" && m.token != rejected &&
+		(m.expiry.IsZero() || time.Now().Add(m.options.ExpiryLeeway).Before(m.expiry))
+	if valid {
+		return m.token, nil
+	}
+
+	if m.options.TokenSource == nil {
+		return "", fmt.Errorf("auth middleware has no token source")
+	}
+	token, expiry, err := m.options.TokenSource(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain auth token: %w", err)
+	}
+	m.token, m.expiry = token, expiry
+	return token, nil
+}
+
+// withBearer returns a copy of req carrying token, leaving the caller's request untouched.
+func withBearer(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
@@ -0,0 +1,169 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuthMiddleware", func() {
+	var (
+		fetches int32
+		ttl     time.Duration
+		source  func(ctx context.Context) (string, time.Time, error)
+	)
+
+	BeforeEach(func() {
+		fetches = 0
+		ttl = time.Hour
+		source = func(ctx context.Context) (string, time.Time, error) {
+			n := atomic.AddInt32(&fetches, 1)
+			return fmt.Sprintf("token-%d", n), time.Now().Add(ttl), nil
+		}
+	})
+
+	// transportAccepting answers 401 unless the request carries one of the accepted tokens
+	transportAccepting := func(seen *[]string, accepted ...string) core.RoundTripFunc {
+		var mu sync.Mutex
+		return func(req *http.Request) (*http.Response, error) {
+			auth := req.Header.Get("Authorization")
+			mu.Lock()
+			*seen = append(*seen, auth)
+			mu.Unlock()
+			status := http.StatusUnauthorized
+			for _, token := range accepted {
+				if auth == "Bearer "+token {
+					status = http.StatusOK
+				}
+			}
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+	}
+
+	get := func(rt core.RoundTripFunc) *http.Response {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should be named auth", func() {
+		mw := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source})
+		Expect(mw.GetIdentifier().Name).To(Equal("auth"))
+	})
+
+	It("should cache the token until it expires", func() {
+		ttl = 50 * time.Millisecond
+		var seen []string
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source}).
+			Wrap(transportAccepting(&seen, "token-1", "token-2"))
+
+		get(rt)
+		get(rt)
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+
+		time.Sleep(70 * time.Millisecond)
+		get(rt)
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+		Expect(seen).To(Equal([]string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}))
+	})
+
+	It("should refresh early within the expiry leeway", func() {
+		var seen []string
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source, ExpiryLeeway: 2 * time.Hour}).
+			Wrap(transportAccepting(&seen, "token-1", "token-2"))
+
+		get(rt)
+		get(rt)
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+	})
+
+	It("should refresh and retry once on a 401", func() {
+		var seen []string
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source}).
+			Wrap(transportAccepting(&seen, "token-2"))
+
+		resp := get(rt)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(seen).To(Equal([]string{"Bearer token-1", "Bearer token-2"}))
+	})
+
+	It("should return a second 401 without retrying again", func() {
+		var seen []string
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source}).
+			Wrap(transportAccepting(&seen))
+
+		resp := get(rt)
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(seen).To(HaveLen(2))
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(2)))
+	})
+
+	It("should replay the request body on the retry", func() {
+		var bodies []string
+		attempts := 0
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source}).
+			Wrap(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				data, _ := io.ReadAll(req.Body)
+				bodies = append(bodies, string(data))
+				status := http.StatusOK
+				if attempts == 1 {
+					status = http.StatusUnauthorized
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})
+
+		req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("payload"))
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(bodies).To(Equal([]string{"payload", "payload"}))
+		Expect(req.Header.Get("Authorization")).To(BeEmpty())
+	})
+
+	It("should fetch a single token for concurrent requests", func() {
+		var seen []string
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{TokenSource: source}).
+			Wrap(transportAccepting(&seen, "token-1"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(get(rt).StatusCode).To(Equal(http.StatusOK))
+			}()
+		}
+		wg.Wait()
+		Expect(atomic.LoadInt32(&fetches)).To(Equal(int32(1)))
+	})
+
+	It("should fail the request when the token source fails", func() {
+		rt := middlewares.AuthMiddleware(middlewares.AuthOptions{
+			TokenSource: func(ctx context.Context) (string, time.Time, error) {
+				return "", time.Time{}, errors.New("idp unavailable")
+			},
+		}).Wrap(func(req *http.Request) (*http.Response, error) {
+			Fail("transport should not be called")
+			return nil, nil
+		})
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		_, err := rt(req)
+		Expect(err).To(MatchError(ContainSubstring("idp unavailable")))
+	})
+})
@@ -10,6 +10,15 @@ type MiddlewareIdentifier struct {
 	Options interface{} // Configuration options for the middleware
 }
 
+// OptionsCloner is implemented by middleware options that hold slices or maps, including the
+// retry strategies. GetIdentifier returns a copy of such options that shares none of them, so
+// callers may modify what they get back without affecting a middleware that is serving
+// requests concurrently. Funcs and interface values in options, such as a MetricsRecorder or
+// a TokenSource, are shared rather than copied.
+type OptionsCloner interface {
+	CloneOptions() interface{}
+}
+
 // ConfigurableMiddleware is an interface that middleware can implement to be configurable
 type ConfigurableMiddleware interface {
 	GetIdentifier() MiddlewareIdentifier
@@ -23,8 +32,14 @@ type BaseMiddleware struct {
 	Wrapper    Middleware
 }
 
+// GetIdentifier returns the identifier of the middleware, with a snapshot of its options
+// when they implement OptionsCloner.
 func (m *BaseMiddleware) GetIdentifier() MiddlewareIdentifier {
-	return m.Identifier
+	identifier := m.Identifier
+	if cloner, ok := identifier.Options.(OptionsCloner); ok {
+		identifier.Options = cloner.CloneOptions()
+	}
+	return identifier
 }
 
 func (m *BaseMiddleware) Wrap(next core.RoundTripFunc) core.RoundTripFunc {
@@ -1,9 +1,11 @@
 package middlewares_test
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jzx17/gofetch/core"
 	"github.com/jzx17/gofetch/middlewares"
@@ -59,4 +61,72 @@ var _ = Describe("Middleware", func() {
 			Expect(resp.Header.Get("X-Test")).To(Equal("AB"))
 		})
 	})
+
+	Describe("GetIdentifier", func() {
+		final := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+		})
+
+		logAuthorization := func(mw middlewares.ConfigurableMiddleware, buf *bytes.Buffer) string {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			_, err := mw.Wrap(final)(req)
+			Expect(err).NotTo(HaveOccurred())
+			return buf.String()
+		}
+
+		It("should return a snapshot of options that can be modified freely", func() {
+			var buf bytes.Buffer
+			options := middlewares.DefaultLoggingOptions()
+			options.Writer = &buf
+			options.Level = middlewares.LogLevelDebug
+			mw := middlewares.LoggingMiddleware(options)
+
+			snapshot := mw.GetIdentifier().Options.(middlewares.LoggingOptions)
+			snapshot.HeadersToRedact[0] = "X-Other"
+
+			Expect(mw.GetIdentifier().Options.(middlewares.LoggingOptions).HeadersToRedact[0]).To(Equal("Authorization"))
+			Expect(logAuthorization(mw, &buf)).To(ContainSubstring("Authorization: [REDACTED]"))
+		})
+
+		It("should not share slices with the options passed to the constructor", func() {
+			var buf bytes.Buffer
+			options := middlewares.DefaultLoggingOptions()
+			options.Writer = &buf
+			options.Level = middlewares.LogLevelDebug
+			mw := middlewares.LoggingMiddleware(options)
+
+			options.HeadersToRedact[0] = "X-Other"
+
+			Expect(logAuthorization(mw, &buf)).To(ContainSubstring("Authorization: [REDACTED]"))
+		})
+
+		It("should copy maps and slices of other options", func() {
+			decoders := map[string]core.ContentDecoder{"br": nil}
+			mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{Decoders: decoders})
+			delete(mw.GetIdentifier().Options.(middlewares.DecompressionOptions).Decoders, "br")
+			decoders["zstd"] = nil
+			Expect(mw.GetIdentifier().Options.(middlewares.DecompressionOptions).Decoders).To(HaveLen(1))
+
+			trace := middlewares.TraceMiddleware(middlewares.DefaultTraceOptions())
+			trace.GetIdentifier().Options.(middlewares.TraceOptions).HeadersToRedact[0] = "X-Other"
+			Expect(trace.GetIdentifier().Options.(middlewares.TraceOptions).HeadersToRedact[0]).To(Equal("Authorization"))
+		})
+
+		It("should snapshot retry strategies", func() {
+			strategy := middlewares.NewRateLimitResetStrategy(middlewares.NewConstantDelayStrategy(time.Millisecond, 3), 0)
+			retry := middlewares.RetryMiddleware(strategy)
+
+			snapshot := retry.GetIdentifier().Options.(*middlewares.RateLimitResetStrategy)
+			fallback := snapshot.Fallback.(*middlewares.ConstantDelayStrategy)
+			fallback.RetryableStatuses[0] = http.StatusTeapot
+			fallback.MaxAttempts = 10
+			snapshot.MaxWait = time.Hour
+
+			Expect(strategy.MaxWait).To(BeZero())
+			live := strategy.Fallback.(*middlewares.ConstantDelayStrategy)
+			Expect(live.RetryableStatuses).To(Equal(middlewares.RetryableStatusCodes()))
+			Expect(live.MaxAttempts).To(Equal(3))
+		})
+	})
 })
@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// bufferedResponse is a response whose body has been read into memory, so it can be handed
+// out any number of times. Every response built from it gets its own headers and body reader.
+type bufferedResponse struct {
+	status     string
+	statusCode int
+	proto      string
+	header     http.Header
+	trailer    http.Header
+	body       []byte
+}
+
+// newBufferedResponse reads and closes the body of resp, leaving resp readable from the
+// buffered copy.
+func newBufferedResponse(resp *http.Response) (*bufferedResponse, error) {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return &bufferedResponse{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		header:     resp.Header.Clone(),
+		trailer:    resp.Trailer.Clone(),
+		body:       body,
+	}, nil
+}
+
+// response builds a fresh *http.Response with its own copy of headers and body.
+func (b *bufferedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        b.status,
+		StatusCode:    b.statusCode,
+		Proto:         b.proto,
+		Header:        b.header.Clone(),
+		Trailer:       b.trailer.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(b.body)),
+		ContentLength: int64(len(b.body)),
+		Request:       req,
+	}
+}
+
+// lruCache is a map that evicts its least recently used entry once it holds more than
+// maxEntries. A maxEntries of zero or less leaves it unbounded. It is safe for concurrent use.
+type lruCache[V any] struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruItem[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUCache[V any](maxEntries int) *lruCache[V] {
+	return &lruCache[V]{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// get returns the value stored under key and marks it as recently used.
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruItem[V]).value, true
+}
+
+// set stores value under key, evicting the least recently used entries beyond maxEntries.
+func (c *lruCache[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruItem[V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruItem[V]{key: key, value: value})
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruItem[V]).key)
+	}
+}
+
+// remove deletes the value stored under key.
+func (c *lruCache[V]) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
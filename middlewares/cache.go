@@ -0,0 +1,278 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+var _ ConfigurableMiddleware = (*cacheMiddleware)(nil)
+var _ CacheStore = (*MemoryCacheStore)(nil)
+
+// CacheEntry is a buffered response held by a CacheStore
+type CacheEntry struct {
+	Status     string
+	StatusCode int
+	Proto      string
+	Header     http.Header
+	Body       []byte
+	// Expires is when the entry stops being fresh and must be revalidated
+	Expires time.Time
+}
+
+// CacheStore holds cached responses by key. Implementations must be safe for concurrent use;
+// a store that fails to read, such as an unreachable Redis, reports a miss.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// CacheOptions configures the HTTP cache middleware
+type CacheOptions struct {
+	// KeyFunc derives the cache key of a request; defaults to the full URL plus a hash of the
+	// Authorization and Cookie headers, if any
+	KeyFunc func(req *http.Request) string
+}
+
+// cacheMiddleware caches GET responses following their Cache-Control headers
+type cacheMiddleware struct {
+	BaseMiddleware
+	store   CacheStore
+	options CacheOptions
+}
+
+// CacheMiddleware creates a middleware that caches successful GET responses in store, honoring
+// Cache-Control. Responses are fresh for their max-age and served without calling the next
+// handler. The cache is shared between callers, so no-store and private responses, responses
+// with a Vary header and, unless marked public, s-maxage or must-revalidate, responses to
+// requests with an Authorization header are never cached. Stale entries, including no-cache ones, that
+// carry an ETag or Last-Modified are revalidated with a conditional request and served from the
+// cache on 304 Not Modified. Requests that set their own conditional or Range headers bypass the
+// cache.
+func CacheMiddleware(store CacheStore, options CacheOptions) ConfigurableMiddleware {
+	if options.KeyFunc == nil {
+		options.KeyFunc = credentialKey
+	}
+
+	mw := &cacheMiddleware{
+		store:   store,
+		options: options,
+	}
+
+	mw.BaseMiddleware = BaseMiddleware{
+		Identifier: MiddlewareIdentifier{
+			Name:    "cache",
+			Options: options,
+		},
+		Wrapper: mw.roundTrip,
+	}
+
+	return mw
+}
+
+func (m *cacheMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if !cacheableRequest(req) {
+			return next(req)
+		}
+
+		key := m.options.KeyFunc(req)
+		entry, ok := m.store.Get(key)
+		if ok && time.Now().Before(entry.Expires) {
+			return entry.response(req), nil
+		}
+		if ok && !entry.hasValidators() {
+			m.store.Delete(key)
+			ok = false
+		}
+		if !ok {
+			resp, err := next(req)
+			if err != nil {
+				return resp, err
+			}
+			return m.save(req, key, resp)
+		}
+
+		resp, err := next(entry.conditionalRequest(req))
+		if err != nil {
+			return resp, err
+		}
+		if resp.StatusCode != http.StatusNotModified {
+			return m.save(req, key, resp)
+		}
+		_ = resp.Body.Close()
+
+		refreshed := entry.revalidated(resp.Header)
+		if directives := parseCacheControl(refreshed.Header); !storable(req, refreshed.Header, directives) {
+			m.store.Delete(key)
+		} else {
+			refreshed.Expires = directives.expires(time.Now())
+			m.store.Set(key, refreshed)
+		}
+		return refreshed.response(req), nil
+	}
+}
+
+// save buffers a cacheable response into the store and returns it readable from the buffer.
+func (m *cacheMiddleware) save(req *http.Request, key string, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	directives := parseCacheControl(resp.Header)
+	if !storable(req, resp.Header, directives) {
+		m.store.Delete(key)
+		return resp, nil
+	}
+	expires := directives.expires(time.Now())
+	if !time.Now().Before(expires) && resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		// Neither fresh nor revalidatable, so a cached copy could never be served
+		return resp, nil
+	}
+
+	buffered, err := newBufferedResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	m.store.Set(key, &CacheEntry{
+		Status:     buffered.status,
+		StatusCode: buffered.statusCode,
+		Proto:      buffered.proto,
+		Header:     buffered.header,
+		Body:       buffered.body,
+		Expires:    expires,
+	})
+	return resp, nil
+}
+
+// cacheableRequest reports whether req may be served from or stored in the cache.
+func cacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return !parseCacheControl(req.Header).has("no-store")
+}
+
+// storable reports whether a response with header and directives to req may be kept in a cache
+// shared between callers.
+func storable(req *http.Request, header http.Header, directives cacheControl) bool {
+	if directives.has("no-store") || directives.has("private") || header.Get("Vary") != "" {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" {
+		return directives.has("public") || directives.has("s-maxage") || directives.has("must-revalidate")
+	}
+	return true
+}
+
+// hasValidators reports whether the entry can be revalidated with a conditional request.
+func (e *CacheEntry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// conditionalRequest clones req with the validators of the entry.
+func (e *CacheEntry) conditionalRequest(req *http.Request) *http.Request {
+	conditional := req.Clone(req.Context())
+	if etag := e.Header.Get("ETag"); etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		conditional.Header.Set("If-Modified-Since", lastModified)
+	}
+	return conditional
+}
+
+// revalidated copies the entry with the headers of a 304 response merged in.
+func (e *CacheEntry) revalidated(header http.Header) *CacheEntry {
+	refreshed := *e
+	refreshed.Header = e.Header.Clone()
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		refreshed.Header[name] = append([]string(nil), values...)
+	}
+	return &refreshed
+}
+
+// response builds a fresh *http.Response with its own copy of headers and body.
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	buffered := &bufferedResponse{
+		status:     e.Status,
+		statusCode: e.StatusCode,
+		proto:      e.Proto,
+		header:     e.Header,
+		body:       e.Body,
+	}
+	return buffered.response(req)
+}
+
+// cacheControl holds parsed Cache-Control directives keyed by lowercase name
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	directives := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return directives
+}
+
+func (d cacheControl) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// expires returns when a response received at now stops being fresh. Responses without a
+// max-age, or marked no-cache, are stale immediately.
+func (d cacheControl) expires(now time.Time) time.Time {
+	if d.has("no-cache") {
+		return now
+	}
+	seconds, err := strconv.Atoi(d["max-age"])
+	if err != nil || seconds <= 0 {
+		return now
+	}
+	return now.Add(time.Duration(seconds) * time.Second)
+}
+
+// MemoryCacheStore is an in-memory CacheStore that evicts the least recently used entry
+// once it holds more than its maximum number of entries.
+type MemoryCacheStore struct {
+	entries *lruCache[*CacheEntry]
+}
+
+// NewMemoryCacheStore creates an in-memory store holding at most maxEntries responses.
+// A maxEntries of zero or less leaves the store unbounded.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{entries: newLRUCache[*CacheEntry](maxEntries)}
+}
+
+// Get returns the entry stored under key.
+func (s *MemoryCacheStore) Get(key string) (*CacheEntry, bool) {
+	return s.entries.get(key)
+}
+
+// Set stores entry under key, evicting the least recently used entries beyond the limit.
+func (s *MemoryCacheStore) Set(key string, entry *CacheEntry) {
+	s.entries.set(key, entry)
+}
+
+// Delete removes the entry stored under key.
+func (s *MemoryCacheStore) Delete(key string) {
+	s.entries.remove(key)
+}
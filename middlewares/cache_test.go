@@ -0,0 +1,245 @@
+package middlewares_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CacheMiddleware", func() {
+	var (
+		calls        int
+		cacheControl string
+		etag         string
+		lastModified string
+		vary         string
+		conditional  []*http.Request
+		transport    core.RoundTripFunc
+		store        *middlewares.MemoryCacheStore
+	)
+
+	BeforeEach(func() {
+		calls = 0
+		cacheControl = "max-age=60"
+		etag = ""
+		lastModified = ""
+		vary = ""
+		conditional = nil
+		store = middlewares.NewMemoryCacheStore(10)
+		transport = func(req *http.Request) (*http.Response, error) {
+			calls++
+			header := http.Header{"Cache-Control": {cacheControl}}
+			if etag != "" {
+				header.Set("ETag", etag)
+			}
+			if lastModified != "" {
+				header.Set("Last-Modified", lastModified)
+			}
+			if vary != "" {
+				header.Set("Vary", vary)
+			}
+			if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+				conditional = append(conditional, req)
+				etagMatches := etag != "" && req.Header.Get("If-None-Match") == etag
+				unmodified := lastModified != "" && req.Header.Get("If-Modified-Since") == lastModified
+				if etagMatches || unmodified {
+					return &http.Response{
+						StatusCode: http.StatusNotModified,
+						Header:     header,
+						Body:       http.NoBody,
+					}, nil
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(fmt.Sprintf("version %d", calls))),
+			}, nil
+		}
+	})
+
+	get := func(rt core.RoundTripFunc, url string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(data)
+	}
+
+	It("should serve fresh responses without calling next", func() {
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		_, first := get(rt, "http://example.com/a")
+		resp, second := get(rt, "http://example.com/a")
+
+		Expect(first).To(Equal("version 1"))
+		Expect(second).To(Equal("version 1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should never cache no-store responses", func() {
+		cacheControl = "no-store"
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		_, second := get(rt, "http://example.com/a")
+
+		Expect(second).To(Equal("version 2"))
+		_, ok := store.Get("http://example.com/a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should never cache private responses", func() {
+		cacheControl = "private, max-age=60"
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		_, second := get(rt, "http://example.com/a")
+
+		Expect(second).To(Equal("version 2"))
+		_, ok := store.Get("http://example.com/a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should never cache responses that vary on request headers", func() {
+		vary = "Authorization"
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{
+			KeyFunc: func(req *http.Request) string { return req.URL.String() },
+		}).Wrap(transport)
+
+		send := func(token string) string {
+			req, err := http.NewRequest("GET", "http://example.com/me", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := rt(req)
+			Expect(err).NotTo(HaveOccurred())
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		cacheControl = "public, max-age=60"
+		Expect(send("alice")).To(Equal("version 1"))
+		Expect(send("bob")).To(Equal("version 2"))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should cache responses to authenticated requests only when the server allows it", func() {
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+		send := func() string {
+			req, err := http.NewRequest("GET", "http://example.com/me", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer alice")
+			resp, err := rt(req)
+			Expect(err).NotTo(HaveOccurred())
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		send()
+		Expect(send()).To(Equal("version 2"))
+
+		cacheControl = "public, max-age=60"
+		Expect(send()).To(Equal("version 3"))
+		Expect(send()).To(Equal("version 3"))
+	})
+
+	It("should revalidate stale entries with If-None-Match and serve the cached body on 304", func() {
+		cacheControl = "max-age=0"
+		etag = `"v1"`
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		resp, second := get(rt, "http://example.com/a")
+
+		Expect(calls).To(Equal(2))
+		Expect(conditional).To(HaveLen(1))
+		Expect(conditional[0].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(second).To(Equal("version 1"))
+	})
+
+	It("should revalidate with If-Modified-Since and replace the entry when it changed", func() {
+		cacheControl = "no-cache"
+		lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		lastModified = "Tue, 03 Jan 2006 15:04:05 GMT"
+		_, second := get(rt, "http://example.com/a")
+		_, third := get(rt, "http://example.com/a")
+
+		Expect(conditional).To(HaveLen(2))
+		Expect(conditional[0].Header.Get("If-Modified-Since")).To(Equal("Mon, 02 Jan 2006 15:04:05 GMT"))
+		Expect(second).To(Equal("version 2"))
+		Expect(third).To(Equal("version 2"))
+	})
+
+	It("should promote a revalidated entry to fresh using the 304 headers", func() {
+		cacheControl = "max-age=0"
+		etag = `"v1"`
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		cacheControl = "max-age=60"
+		get(rt, "http://example.com/a")
+		_, third := get(rt, "http://example.com/a")
+
+		Expect(calls).To(Equal(2))
+		Expect(third).To(Equal("version 1"))
+	})
+
+	It("should bypass the cache for non-GET requests", func() {
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{}).Wrap(transport)
+
+		for i := 0; i < 2; i++ {
+			req, err := http.NewRequest("POST", "http://example.com/a", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rt(req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should use KeyFunc to derive cache keys", func() {
+		rt := middlewares.CacheMiddleware(store, middlewares.CacheOptions{
+			KeyFunc: func(req *http.Request) string { return req.URL.Path },
+		}).Wrap(transport)
+
+		get(rt, "http://example.com/a?page=1")
+		_, second := get(rt, "http://example.com/a?page=2")
+
+		Expect(second).To(Equal("version 1"))
+		_, ok := store.Get("/a")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should evict the least recently used entry from a MemoryCacheStore", func() {
+		small := middlewares.NewMemoryCacheStore(2)
+		small.Set("a", &middlewares.CacheEntry{})
+		small.Set("b", &middlewares.CacheEntry{})
+		_, _ = small.Get("a")
+		small.Set("c", &middlewares.CacheEntry{})
+
+		_, okA := small.Get("a")
+		_, okB := small.Get("b")
+		Expect(okA).To(BeTrue())
+		Expect(okB).To(BeFalse())
+
+		small.Delete("a")
+		_, okA = small.Get("a")
+		Expect(okA).To(BeFalse())
+	})
+})
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// ErrorClass is the outcome of classifying a response or transport error
+type ErrorClass int
+
+const (
+	// ErrorClassSuccess marks a successful exchange
+	ErrorClassSuccess ErrorClass = iota
+	// ErrorClassRetryable marks a failure that is worth retrying
+	ErrorClassRetryable
+	// ErrorClassFatal marks a failure that must not be retried
+	ErrorClassFatal
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassSuccess:
+		return "success"
+	case ErrorClassRetryable:
+		return "retryable"
+	case ErrorClassFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// Failed reports whether the class marks a failed exchange, e.g. one a circuit breaker counts.
+func (c ErrorClass) Failed() bool {
+	return c == ErrorClassRetryable || c == ErrorClassFatal
+}
+
+// ErrorClassifier decides whether an exchange succeeded. resp is nil when err is set.
+// Middlewares that consult a classifier buffer the response body first and restore it
+// afterwards, so the classifier may read it, e.g. to detect a 200 with an error envelope.
+type ErrorClassifier func(resp *http.Response, err error) ErrorClass
+
+type errorClassifierKey struct{}
+
+// WithErrorClassifierContext attaches classifier to ctx for middlewares further down the chain.
+func WithErrorClassifierContext(ctx context.Context, classifier ErrorClassifier) context.Context {
+	return context.WithValue(ctx, errorClassifierKey{}, classifier)
+}
+
+// ErrorClassifierFromContext returns the classifier attached to ctx, or nil.
+func ErrorClassifierFromContext(ctx context.Context) ErrorClassifier {
+	classifier, _ := ctx.Value(errorClassifierKey{}).(ErrorClassifier)
+	return classifier
+}
+
+// ClassifyExchange judges an exchange with the classifier attached to req's context, so that
+// middlewares other than retry, such as a circuit breaker counting failures, share the client's
+// definition of failure. The response body, up to maxBody bytes, is buffered for the classifier
+// and left readable from the start. It reports false when no classifier is attached, or when
+// the response is streamed or larger than maxBody, leaving the caller to its own judgement.
+func ClassifyExchange(req *http.Request, resp *http.Response, err error, maxBody int64) (ErrorClass, bool, error) {
+	classifier := ErrorClassifierFromContext(req.Context())
+	if classifier == nil {
+		return ErrorClassSuccess, false, nil
+	}
+	return classifyExchange(classifier, req, resp, err, maxBody)
+}
+
+// classifyExchange runs classifier against a response whose body has been buffered, restoring
+// the body afterwards. It reports false if the body could not be buffered.
+func classifyExchange(classifier ErrorClassifier, req *http.Request, resp *http.Response, err error, maxBody int64) (ErrorClass, bool, error) {
+	if err != nil {
+		return classifier(resp, err), true, nil
+	}
+	if IsStreamingRequest(req.Context()) {
+		return ErrorClassSuccess, false, nil
+	}
+	body, ok, bufErr := bufferResponseBody(resp, maxBody)
+	if bufErr != nil || !ok {
+		return ErrorClassSuccess, false, bufErr
+	}
+	class := classifier(resp, nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return class, true, nil
+}
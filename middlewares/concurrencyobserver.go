@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// ConcurrencyPhase tells whether a ConcurrencyObserver is called as a request starts or ends
+type ConcurrencyPhase int
+
+const (
+	// ConcurrencyPhaseStart is reported once a request enters the middleware
+	ConcurrencyPhaseStart ConcurrencyPhase = iota
+	// ConcurrencyPhaseEnd is reported once a request fails or its response body is closed
+	ConcurrencyPhaseEnd
+)
+
+func (p ConcurrencyPhase) String() string {
+	if p == ConcurrencyPhaseStart {
+		return "start"
+	}
+	return "end"
+}
+
+// ConcurrencyObserver receives the number of requests in flight, including the current one
+// at ConcurrencyPhaseStart and excluding it at ConcurrencyPhaseEnd. It is called concurrently
+// from every request and must not block.
+type ConcurrencyObserver func(phase ConcurrencyPhase, inFlight int64)
+
+// ConcurrencyObserverMiddleware creates a middleware that counts the requests in flight below it
+// and reports the count to observe as each one starts and ends. A request stays in flight until
+// it fails or its response body is closed, so slots held by a concurrency-limited transport are
+// reflected. Place it before rate limiting or concurrency middlewares to include the time
+// requests spend queued in them.
+func ConcurrencyObserverMiddleware(observe ConcurrencyObserver) ConfigurableMiddleware {
+	var inFlight atomic.Int64
+
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if observe == nil {
+				return next(req)
+			}
+
+			observe(ConcurrencyPhaseStart, inFlight.Add(1))
+			var once sync.Once
+			finish := func() {
+				once.Do(func() {
+					observe(ConcurrencyPhaseEnd, inFlight.Add(-1))
+				})
+			}
+
+			resp, err := next(req)
+			if err != nil || resp == nil || resp.Body == nil {
+				finish()
+				return resp, err
+			}
+			resp.Body = &finishOnCloseBody{ReadCloser: resp.Body, finish: finish}
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("concurrency-observer", nil, wrapper)
+}
+
+// finishOnCloseBody runs finish once the body is closed.
+type finishOnCloseBody struct {
+	io.ReadCloser
+	finish func()
+}
+
+func (b *finishOnCloseBody) Close() error {
+	defer b.finish()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,98 @@
+package middlewares_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConcurrencyObserverMiddleware", func() {
+	type observation struct {
+		phase    middlewares.ConcurrencyPhase
+		inFlight int64
+	}
+
+	var (
+		mu           sync.Mutex
+		observations []observation
+		observe      middlewares.ConcurrencyObserver
+	)
+
+	BeforeEach(func() {
+		observations = nil
+		observe = func(phase middlewares.ConcurrencyPhase, inFlight int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			observations = append(observations, observation{phase, inFlight})
+		}
+	})
+
+	observed := func(phase middlewares.ConcurrencyPhase) []int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		var counts []int64
+		for _, o := range observations {
+			if o.phase == phase {
+				counts = append(counts, o.inFlight)
+			}
+		}
+		return counts
+	}
+
+	It("should rise and fall with concurrent requests until their bodies are closed", func() {
+		const workers = 5
+		release := make(chan struct{})
+		transport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		})
+		rt := middlewares.ConcurrencyObserverMiddleware(observe).Wrap(transport)
+
+		responses := make(chan *http.Response, workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer GinkgoRecover()
+				req, _ := http.NewRequest("GET", "http://example.com", nil)
+				resp, err := rt(req)
+				Expect(err).NotTo(HaveOccurred())
+				responses <- resp
+			}()
+		}
+
+		Eventually(func() []int64 { return observed(middlewares.ConcurrencyPhaseStart) }).
+			Should(ConsistOf(int64(1), int64(2), int64(3), int64(4), int64(5)))
+		close(release)
+
+		for i := 0; i < workers; i++ {
+			resp := <-responses
+			Expect(observed(middlewares.ConcurrencyPhaseEnd)).To(HaveLen(i))
+			Expect(resp.Body.Close()).To(Succeed())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+
+		Expect(observed(middlewares.ConcurrencyPhaseEnd)).To(Equal([]int64{4, 3, 2, 1, 0}))
+	})
+
+	It("should end requests that fail without a response", func() {
+		transport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("boom")
+		})
+		rt := middlewares.ConcurrencyObserverMiddleware(observe).Wrap(transport)
+
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		_, err := rt(req)
+		Expect(err).To(MatchError("boom"))
+		Expect(observations).To(Equal([]observation{
+			{middlewares.ConcurrencyPhaseStart, 1},
+			{middlewares.ConcurrencyPhaseEnd, 0},
+		}))
+	})
+})
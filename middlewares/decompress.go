@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// DecompressionOptions configures the decompression middleware
+type DecompressionOptions struct {
+	// Decoders adds decoders for encodings beyond gzip and deflate, keyed by lowercase
+	// Content-Encoding name, e.g. "br" backed by a brotli package.
+	Decoders map[string]core.ContentDecoder
+}
+
+// CloneOptions returns a copy of the options that shares no maps with o.
+func (o DecompressionOptions) CloneOptions() interface{} {
+	return o.clone()
+}
+
+func (o DecompressionOptions) clone() DecompressionOptions {
+	if o.Decoders != nil {
+		decoders := make(map[string]core.ContentDecoder, len(o.Decoders))
+		for encoding, decoder := range o.Decoders {
+			decoders[encoding] = decoder
+		}
+		o.Decoders = decoders
+	}
+	return o
+}
+
+// DecompressionMiddleware creates a middleware that decodes response bodies according to their
+// Content-Encoding header, including chained encodings such as "deflate, gzip". Go's transport
+// only decodes gzip when it negotiated compression itself; this covers responses to a manually
+// set Accept-Encoding too, so downstream readers always see decompressed data. Responses using an
+// encoding without a decoder are passed through unchanged.
+func DecompressionMiddleware(options DecompressionOptions) ConfigurableMiddleware {
+	options = options.clone()
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+
+			if err := core.DecodeContentEncoding(resp, options.Decoders); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("decompression", options, wrapper)
+}
@@ -0,0 +1,138 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecompressionMiddleware", func() {
+	gzipData := func(data []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	zlibData := func(data []byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	flateData := func(data []byte) []byte {
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		_, _ = fw.Write(data)
+		_ = fw.Close()
+		return buf.Bytes()
+	}
+
+	transportReturning := func(body []byte, encodings ...string) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			for _, encoding := range encodings {
+				header.Add("Content-Encoding", encoding)
+			}
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        header,
+				Body:          io.NopCloser(bytes.NewReader(body)),
+				ContentLength: int64(len(body)),
+			}, nil
+		}
+	}
+
+	roundTrip := func(mw middlewares.ConfigurableMiddleware, next core.RoundTripFunc) (*http.Response, string) {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		resp, err := mw.Wrap(next)(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	plain := []byte("hello, compressed world")
+
+	It("should decode a gzip body and remove the encoding headers", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+		resp, body := roundTrip(mw, transportReturning(gzipData(plain), "gzip"))
+
+		Expect(body).To(Equal(string(plain)))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(resp.ContentLength).To(Equal(int64(-1)))
+		Expect(resp.Uncompressed).To(BeTrue())
+	})
+
+	It("should decode zlib-wrapped and raw deflate bodies", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+
+		_, body := roundTrip(mw, transportReturning(zlibData(plain), "deflate"))
+		Expect(body).To(Equal(string(plain)))
+
+		_, body = roundTrip(mw, transportReturning(flateData(plain), "deflate"))
+		Expect(body).To(Equal(string(plain)))
+	})
+
+	It("should decode brotli with a supplied decoder", func() {
+		// Stands in for a brotli package, which is not a dependency of this module
+		fakeBrotli := func(body io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, body)), nil
+		}
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{
+			Decoders: map[string]core.ContentDecoder{"br": fakeBrotli},
+		})
+
+		encoded := base64.StdEncoding.EncodeToString(plain)
+		_, body := roundTrip(mw, transportReturning([]byte(encoded), "br"))
+		Expect(body).To(Equal(string(plain)))
+	})
+
+	It("should undo chained encodings in reverse order", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+
+		_, body := roundTrip(mw, transportReturning(gzipData(zlibData(plain)), "deflate, gzip"))
+		Expect(body).To(Equal(string(plain)))
+
+		_, body = roundTrip(mw, transportReturning(zlibData(gzipData(plain)), "gzip", "deflate"))
+		Expect(body).To(Equal(string(plain)))
+	})
+
+	It("should pass through an encoding it cannot decode", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+		resp, body := roundTrip(mw, transportReturning(gzipData(plain), "gzip, br"))
+
+		Expect(body).To(Equal(string(gzipData(plain))))
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip, br"))
+	})
+
+	It("should fail on a corrupt gzip body", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		_, err := mw.Wrap(transportReturning([]byte("not gzip"), "gzip"))(req)
+		Expect(err).To(MatchError(ContainSubstring("failed to decompress gzip response")))
+	})
+
+	It("should leave unencoded bodies alone", func() {
+		mw := middlewares.DecompressionMiddleware(middlewares.DecompressionOptions{})
+		resp, body := roundTrip(mw, transportReturning(plain))
+
+		Expect(body).To(Equal(string(plain)))
+		Expect(resp.ContentLength).To(Equal(int64(len(plain))))
+	})
+})
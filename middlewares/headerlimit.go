@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// HeaderCountError is returned when a response carries more header fields than allowed
+type HeaderCountError struct {
+	Count int
+	Max   int
+}
+
+func (e *HeaderCountError) Error() string {
+	return fmt.Sprintf("response has %d header fields, exceeding the maximum of %d", e.Count, e.Max)
+}
+
+// HeaderCountMiddleware creates a middleware that rejects responses with more than maxFields
+// header fields. net/http bounds the total header size but not the number of fields, so this
+// guards against header-count amplification. Each value of a repeated header counts as a
+// separate field. Rejected responses are drained and closed and reported as a
+// ResponseValidationError wrapping a HeaderCountError. A maxFields of zero or less disables the check.
+func HeaderCountMiddleware(maxFields int) ConfigurableMiddleware {
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || maxFields <= 0 {
+				return resp, err
+			}
+
+			if count := headerFieldCount(resp.Header); count > maxFields {
+				DrainAndClose(resp)
+				return nil, &ResponseValidationError{
+					StatusCode: resp.StatusCode,
+					Err:        &HeaderCountError{Count: count, Max: maxFields},
+				}
+			}
+
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("header-count", maxFields, wrapper)
+}
+
+func headerFieldCount(header http.Header) int {
+	count := 0
+	for _, values := range header {
+		count += len(values)
+	}
+	return count
+}
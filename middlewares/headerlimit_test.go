@@ -0,0 +1,52 @@
+package middlewares_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HeaderCountMiddleware", func() {
+	respondWithHeaders := func(n int, body *trackingBody) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			for i := 0; i < n; i++ {
+				header.Add(fmt.Sprintf("X-Field-%d", i%10), "v")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: body}, nil
+		}
+	}
+
+	It("should reject a response with too many header fields", func() {
+		body := &trackingBody{Reader: strings.NewReader("payload")}
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		resp, err := middlewares.HeaderCountMiddleware(50).Wrap(respondWithHeaders(500, body))(req)
+		Expect(resp).To(BeNil())
+
+		var countErr *middlewares.HeaderCountError
+		Expect(errors.As(err, &countErr)).To(BeTrue())
+		Expect(countErr.Count).To(Equal(500))
+		Expect(countErr.Max).To(Equal(50))
+
+		var validationErr *middlewares.ResponseValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(body.closed).To(BeTrue())
+	})
+
+	It("should pass a response within the limit", func() {
+		body := &trackingBody{Reader: strings.NewReader("payload")}
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		resp, err := middlewares.HeaderCountMiddleware(50).Wrap(respondWithHeaders(50, body))(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body.closed).To(BeFalse())
+	})
+})
@@ -0,0 +1,138 @@
+package middlewares
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+var _ ConfigurableMiddleware = (*idempotencyMiddleware)(nil)
+
+// IdempotencyOptions configures the idempotency key deduplication middleware
+type IdempotencyOptions struct {
+	// Header names the request header carrying the key; defaults to Idempotency-Key
+	Header string
+	// TTL is how long the response for a key is replayed
+	TTL time.Duration
+	// MaxEntries bounds the number of remembered keys; the least recently used is evicted first
+	MaxEntries int
+}
+
+// DefaultIdempotencyOptions returns default idempotency options
+func DefaultIdempotencyOptions() IdempotencyOptions {
+	return IdempotencyOptions{
+		Header:     "Idempotency-Key",
+		TTL:        time.Minute,
+		MaxEntries: 1000,
+	}
+}
+
+// idempotencyCall is an in-flight execution that duplicate submissions wait on
+type idempotencyCall struct {
+	done  chan struct{}
+	entry *cachedResponse
+	err   error
+}
+
+// idempotencyMiddleware replays responses keyed by idempotency key
+type idempotencyMiddleware struct {
+	BaseMiddleware
+	responseStore
+	options IdempotencyOptions
+
+	inflightMu sync.Mutex
+	inflight   map[string]*idempotencyCall
+}
+
+// IdempotencyMiddleware creates a middleware that deduplicates requests carrying an
+// idempotency key. The first request with a key is executed and, unless it fails or returns
+// a 5xx status, its response is replayed for TTL to later requests with the same key, whatever
+// their method or URL. Duplicates sent while the first is in flight wait for its outcome.
+// Requests without the header pass through.
+func IdempotencyMiddleware(options IdempotencyOptions) ConfigurableMiddleware {
+	defaults := DefaultIdempotencyOptions()
+	if options.Header == "" {
+		options.Header = defaults.Header
+	}
+	if options.TTL <= 0 {
+		options.TTL = defaults.TTL
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaults.MaxEntries
+	}
+
+	mw := &idempotencyMiddleware{
+		responseStore: newResponseStore(options.MaxEntries),
+		options:       options,
+		inflight:      make(map[string]*idempotencyCall),
+	}
+
+	mw.BaseMiddleware = BaseMiddleware{
+		Identifier: MiddlewareIdentifier{
+			Name:    "idempotency",
+			Options: options,
+		},
+		Wrapper: mw.roundTrip,
+	}
+
+	return mw
+}
+
+func (m *idempotencyMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		key := req.Header.Get(m.options.Header)
+		if key == "" {
+			return next(req)
+		}
+
+		if entry := m.get(key); entry != nil {
+			return entry.response(req), nil
+		}
+
+		m.inflightMu.Lock()
+		if call, ok := m.inflight[key]; ok {
+			m.inflightMu.Unlock()
+			select {
+			case <-call.done:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+			if call.err != nil {
+				return nil, call.err
+			}
+			return call.entry.response(req), nil
+		}
+		// The first request may have stored its response and left inflight since the check above.
+		if entry := m.get(key); entry != nil {
+			m.inflightMu.Unlock()
+			return entry.response(req), nil
+		}
+		call := &idempotencyCall{done: make(chan struct{})}
+		m.inflight[key] = call
+		m.inflightMu.Unlock()
+
+		defer func() {
+			m.inflightMu.Lock()
+			delete(m.inflight, key)
+			m.inflightMu.Unlock()
+			close(call.done)
+		}()
+
+		resp, err := next(req)
+		if err != nil {
+			call.err = err
+			return nil, err
+		}
+
+		call.entry, call.err = newCachedResponse(resp, m.options.TTL)
+		if call.err != nil {
+			return nil, call.err
+		}
+		if resp.StatusCode < 500 {
+			m.put(key, call.entry)
+		}
+		return resp, nil
+	}
+}
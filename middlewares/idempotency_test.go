@@ -0,0 +1,124 @@
+package middlewares_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IdempotencyMiddleware", func() {
+	var (
+		calls     int32
+		status    int
+		transport core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		calls = 0
+		status = http.StatusCreated
+		transport = func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"X-Call": {fmt.Sprint(n)}},
+				Body:       io.NopCloser(strings.NewReader("created order " + fmt.Sprint(n))),
+			}, nil
+		}
+	})
+
+	submit := func(rt core.RoundTripFunc, key string) (*http.Response, string) {
+		req, err := http.NewRequest("POST", "http://example.com/orders", strings.NewReader(`{"item":1}`))
+		Expect(err).NotTo(HaveOccurred())
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(data)
+	}
+
+	It("should execute a key once within the TTL", func() {
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{TTL: time.Minute}).Wrap(transport)
+
+		first, firstBody := submit(rt, "key-1")
+		second, secondBody := submit(rt, "key-1")
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+		Expect(secondBody).To(Equal(firstBody))
+		Expect(second.StatusCode).To(Equal(http.StatusCreated))
+		Expect(second.Header.Get("X-Call")).To(Equal(first.Header.Get("X-Call")))
+	})
+
+	It("should execute distinct keys separately", func() {
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{}).Wrap(transport)
+
+		_, first := submit(rt, "key-1")
+		_, second := submit(rt, "key-2")
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+		Expect(first).NotTo(Equal(second))
+	})
+
+	It("should pass through requests without a key", func() {
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{}).Wrap(transport)
+
+		submit(rt, "")
+		submit(rt, "")
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+
+	It("should re-execute after the TTL expires", func() {
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{TTL: 30 * time.Millisecond}).Wrap(transport)
+
+		submit(rt, "key-1")
+		time.Sleep(50 * time.Millisecond)
+		submit(rt, "key-1")
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+
+	It("should share one execution between concurrent duplicates", func() {
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{}).Wrap(transport)
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 5)
+		for i := range bodies {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, bodies[i] = submit(rt, "key-1")
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+		for _, body := range bodies {
+			Expect(body).To(Equal("created order 1"))
+		}
+	})
+
+	It("should not remember server errors", func() {
+		status = http.StatusServiceUnavailable
+		rt := middlewares.IdempotencyMiddleware(middlewares.IdempotencyOptions{}).Wrap(transport)
+
+		submit(rt, "key-1")
+		submit(rt, "key-1")
+
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+})
@@ -68,8 +68,20 @@ func DefaultLoggingOptions() LoggingOptions {
 	}
 }
 
-// LoggingMiddleware creates a middleware that logs requests and responses
+// CloneOptions returns a copy of the options that shares no slices with o.
+func (o LoggingOptions) CloneOptions() interface{} {
+	return o.clone()
+}
+
+func (o LoggingOptions) clone() LoggingOptions {
+	o.HeadersToRedact = append([]string(nil), o.HeadersToRedact...)
+	return o
+}
+
+// LoggingMiddleware creates a middleware that logs requests and responses. options is copied,
+// so changing it afterwards does not affect the middleware.
 func LoggingMiddleware(options LoggingOptions) ConfigurableMiddleware {
+	options = options.clone()
 	if options.Writer == nil {
 		options.Writer = os.Stderr
 	}
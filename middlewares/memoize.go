@@ -0,0 +1,150 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+var _ ConfigurableMiddleware = (*responseCacheMiddleware)(nil)
+
+// ResponseCacheOptions configures the short-lived response memoization middleware
+type ResponseCacheOptions struct {
+	// TTL is how long a successful GET response is reused
+	TTL time.Duration
+	// MaxEntries bounds the cache size; the least recently used entry is evicted first
+	MaxEntries int
+}
+
+// DefaultResponseCacheOptions returns default response cache options
+func DefaultResponseCacheOptions() ResponseCacheOptions {
+	return ResponseCacheOptions{
+		TTL:        time.Second,
+		MaxEntries: 100,
+	}
+}
+
+// cachedResponse is a buffered response that is reused until it expires
+type cachedResponse struct {
+	*bufferedResponse
+	expires time.Time
+}
+
+// responseStore is a TTL and LRU bounded store of buffered responses
+type responseStore struct {
+	entries *lruCache[*cachedResponse]
+}
+
+func newResponseStore(maxEntries int) responseStore {
+	return responseStore{entries: newLRUCache[*cachedResponse](maxEntries)}
+}
+
+// responseCacheMiddleware memoizes successful GET responses keyed by URL and credentials
+type responseCacheMiddleware struct {
+	BaseMiddleware
+	responseStore
+	options ResponseCacheOptions
+}
+
+// ResponseCacheMiddleware creates a middleware that memoizes successful GET responses by URL
+// for a short TTL, regardless of Cache-Control. Requests carrying Authorization or Cookie
+// headers are cached per credentials, so a response is never replayed to another user.
+func ResponseCacheMiddleware(options ResponseCacheOptions) ConfigurableMiddleware {
+	if options.TTL <= 0 {
+		options.TTL = DefaultResponseCacheOptions().TTL
+	}
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = DefaultResponseCacheOptions().MaxEntries
+	}
+
+	mw := &responseCacheMiddleware{
+		responseStore: newResponseStore(options.MaxEntries),
+		options:       options,
+	}
+
+	mw.BaseMiddleware = BaseMiddleware{
+		Identifier: MiddlewareIdentifier{
+			Name:    "response-cache",
+			Options: options,
+		},
+		Wrapper: mw.roundTrip,
+	}
+
+	return mw
+}
+
+func (m *responseCacheMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet {
+			return next(req)
+		}
+
+		key := credentialKey(req)
+		if entry := m.get(key); entry != nil {
+			return entry.response(req), nil
+		}
+
+		resp, err := next(req)
+		if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, err
+		}
+
+		entry, err := newCachedResponse(resp, m.options.TTL)
+		if err != nil {
+			return nil, err
+		}
+		m.put(key, entry)
+		return resp, nil
+	}
+}
+
+// credentialKey identifies a request by its URL and, when it carries any, a hash of its
+// Authorization and Cookie headers, so the credentials themselves are not kept in memory.
+func credentialKey(req *http.Request) string {
+	auth := req.Header.Values("Authorization")
+	cookies := req.Header.Values("Cookie")
+	if len(auth) == 0 && len(cookies) == 0 {
+		return req.URL.String()
+	}
+
+	h := sha256.New()
+	for _, v := range auth {
+		h.Write([]byte(v + "\n"))
+	}
+	h.Write([]byte{0})
+	for _, v := range cookies {
+		h.Write([]byte(v + "\n"))
+	}
+	return req.URL.String() + "\x00" + hex.EncodeToString(h.Sum(nil))
+}
+
+// newCachedResponse buffers resp into an entry that expires after ttl, leaving resp readable
+// from the buffered copy.
+func newCachedResponse(resp *http.Response, ttl time.Duration) (*cachedResponse, error) {
+	buffered, err := newBufferedResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedResponse{bufferedResponse: buffered, expires: time.Now().Add(ttl)}, nil
+}
+
+// get returns a live entry for key, dropping it if expired.
+func (s *responseStore) get(key string) *cachedResponse {
+	entry, ok := s.entries.get(key)
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		s.entries.remove(key)
+		return nil
+	}
+	return entry
+}
+
+// put stores entry under key, evicting the least recently used entries beyond MaxEntries.
+func (s *responseStore) put(key string, entry *cachedResponse) {
+	s.entries.set(key, entry)
+}
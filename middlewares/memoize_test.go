@@ -0,0 +1,158 @@
+package middlewares_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResponseCacheMiddleware", func() {
+	var (
+		hits      map[string]int
+		transport core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		hits = map[string]int{}
+		transport = func(req *http.Request) (*http.Response, error) {
+			hits[req.URL.String()]++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Hit": {fmt.Sprint(hits[req.URL.String()])}},
+				Body:       io.NopCloser(strings.NewReader("body for " + req.URL.Path)),
+			}, nil
+		}
+	})
+
+	get := func(rt core.RoundTripFunc, url string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(data)
+	}
+
+	It("should serve a repeated GET from cache within the TTL", func() {
+		rt := middlewares.ResponseCacheMiddleware(middlewares.ResponseCacheOptions{
+			TTL:        time.Minute,
+			MaxEntries: 10,
+		}).Wrap(transport)
+
+		_, first := get(rt, "http://example.com/a")
+		resp, second := get(rt, "http://example.com/a")
+
+		Expect(first).To(Equal("body for /a"))
+		Expect(second).To(Equal(first))
+		Expect(resp.Header.Get("X-Hit")).To(Equal("1"))
+		Expect(hits["http://example.com/a"]).To(Equal(1))
+	})
+
+	It("should refetch after the TTL expires", func() {
+		rt := middlewares.ResponseCacheMiddleware(middlewares.ResponseCacheOptions{
+			TTL:        20 * time.Millisecond,
+			MaxEntries: 10,
+		}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		time.Sleep(40 * time.Millisecond)
+		resp, _ := get(rt, "http://example.com/a")
+
+		Expect(resp.Header.Get("X-Hit")).To(Equal("2"))
+		Expect(hits["http://example.com/a"]).To(Equal(2))
+	})
+
+	It("should evict the least recently used entry beyond MaxEntries", func() {
+		rt := middlewares.ResponseCacheMiddleware(middlewares.ResponseCacheOptions{
+			TTL:        time.Minute,
+			MaxEntries: 2,
+		}).Wrap(transport)
+
+		get(rt, "http://example.com/a")
+		get(rt, "http://example.com/b")
+		get(rt, "http://example.com/a") // a is now most recently used
+		get(rt, "http://example.com/c") // evicts b
+
+		get(rt, "http://example.com/a")
+		get(rt, "http://example.com/b")
+
+		Expect(hits["http://example.com/a"]).To(Equal(1))
+		Expect(hits["http://example.com/b"]).To(Equal(2))
+	})
+
+	It("should give each caller an independent copy", func() {
+		rt := middlewares.ResponseCacheMiddleware(middlewares.DefaultResponseCacheOptions()).Wrap(transport)
+
+		first, _ := get(rt, "http://example.com/a")
+		first.Header.Set("X-Hit", "mutated")
+		second, body := get(rt, "http://example.com/a")
+
+		Expect(second.Header.Get("X-Hit")).To(Equal("1"))
+		Expect(body).To(Equal("body for /a"))
+	})
+
+	It("should not cache non-GET requests or unsuccessful responses", func() {
+		status := http.StatusInternalServerError
+		failing := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			hits[req.Method]++
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		})
+		rt := middlewares.ResponseCacheMiddleware(middlewares.DefaultResponseCacheOptions()).Wrap(failing)
+
+		for i := 0; i < 2; i++ {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			_, err := rt(req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(hits["GET"]).To(Equal(2))
+
+		status = http.StatusOK
+		for i := 0; i < 2; i++ {
+			req, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("x"))
+			_, err := rt(req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(hits["POST"]).To(Equal(2))
+	})
+
+	It("should not share responses between callers with different credentials", func() {
+		var tokens []string
+		authTransport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			tokens = append(tokens, req.Header.Get("Authorization"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("data for " + req.Header.Get("Authorization"))),
+			}, nil
+		})
+		rt := middlewares.ResponseCacheMiddleware(middlewares.DefaultResponseCacheOptions()).Wrap(authTransport)
+
+		send := func(token string) string {
+			req, err := http.NewRequest("GET", "http://example.com/me", nil)
+			Expect(err).NotTo(HaveOccurred())
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := rt(req)
+			Expect(err).NotTo(HaveOccurred())
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		Expect(send("alice")).To(Equal("data for Bearer alice"))
+		Expect(send("bob")).To(Equal("data for Bearer bob"))
+		Expect(send("")).To(Equal("data for "))
+		Expect(send("alice")).To(Equal("data for Bearer alice"))
+		Expect(tokens).To(Equal([]string{"Bearer alice", "Bearer bob", ""}))
+	})
+})
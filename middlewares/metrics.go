@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// Metric names reported by the metrics middleware
+const (
+	// MetricRequestsTotal counts completed round trips, labeled by method, host and status class
+	MetricRequestsTotal = "http_client_requests_total"
+	// MetricTransportErrorsTotal counts round trips that failed without a response, labeled by method and host
+	MetricTransportErrorsTotal = "http_client_transport_errors_total"
+	// MetricRequestsInFlight gauges round trips awaiting a response, labeled by method and host
+	MetricRequestsInFlight = "http_client_requests_in_flight"
+	// MetricRequestDurationSeconds observes round trip latency in seconds, labeled like MetricRequestsTotal
+	MetricRequestDurationSeconds = "http_client_request_duration_seconds"
+)
+
+// Label names attached to metrics
+const (
+	MetricLabelMethod      = "method"
+	MetricLabelHost        = "host"
+	MetricLabelStatusClass = "status_class"
+)
+
+// StatusClassError is the status class label of round trips that failed with a transport error
+const StatusClassError = "error"
+
+// MetricsRecorder receives the measurements of the metrics middleware. Implement it as an
+// adapter over a metrics library, such as Prometheus counter, gauge and histogram vectors
+// keyed by name. Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+	AddGauge(name string, delta float64, labels map[string]string)
+}
+
+// MetricsOptions configures the metrics middleware
+type MetricsOptions struct {
+	Recorder MetricsRecorder
+}
+
+// MetricsMiddleware creates a middleware that reports request counts, in-flight requests and
+// latency to options.Recorder, labeled by method, host and status class ("2xx" through "5xx").
+// Latency is measured around the rest of the chain, up to the arrival of the response headers.
+// Transport errors are counted under MetricTransportErrorsTotal and observed with the "error"
+// status class, so they are never mixed up with HTTP error statuses. Every attempt of a retried
+// request is reported when the middleware runs inside the retry middleware.
+func MetricsMiddleware(options MetricsOptions) ConfigurableMiddleware {
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if options.Recorder == nil {
+				return next(req)
+			}
+
+			labels := map[string]string{
+				MetricLabelMethod: req.Method,
+				MetricLabelHost:   req.URL.Host,
+			}
+			options.Recorder.AddGauge(MetricRequestsInFlight, 1, labels)
+			start := time.Now()
+			resp, err := next(req)
+			duration := time.Since(start)
+			options.Recorder.AddGauge(MetricRequestsInFlight, -1, labels)
+
+			if err != nil {
+				options.Recorder.IncCounter(MetricTransportErrorsTotal, labels)
+			}
+			statusLabels := map[string]string{
+				MetricLabelMethod:      req.Method,
+				MetricLabelHost:        req.URL.Host,
+				MetricLabelStatusClass: statusClass(resp, err),
+			}
+			options.Recorder.IncCounter(MetricRequestsTotal, statusLabels)
+			options.Recorder.ObserveHistogram(MetricRequestDurationSeconds, duration.Seconds(), statusLabels)
+			return resp, err
+		}
+	}
+
+	return CreateMiddleware("metrics", options, wrapper)
+}
+
+// statusClass returns the status class label of a round trip, e.g. "2xx", or "error".
+func statusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return StatusClassError
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
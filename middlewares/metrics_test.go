@@ -0,0 +1,149 @@
+package middlewares_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRecorder keeps metrics in memory, keyed by name and class label
+type fakeRecorder struct {
+	mu         sync.Mutex
+	counters   map[string]int
+	gauges     map[string]float64
+	histograms map[string][]float64
+	inFlight   []float64
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{
+		counters:   map[string]int{},
+		gauges:     map[string]float64{},
+		histograms: map[string][]float64{},
+	}
+}
+
+func metricKey(name string, labels map[string]string) string {
+	return name + "{" + labels["method"] + " " + labels["host"] + " " + labels["status_class"] + "}"
+}
+
+func (r *fakeRecorder) IncCounter(name string, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[metricKey(name, labels)]++
+}
+
+func (r *fakeRecorder) ObserveHistogram(name string, value float64, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := metricKey(name, labels)
+	r.histograms[key] = append(r.histograms[key], value)
+}
+
+func (r *fakeRecorder) AddGauge(name string, delta float64, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := metricKey(name, labels)
+	r.gauges[key] += delta
+	r.inFlight = append(r.inFlight, r.gauges[key])
+}
+
+var _ = Describe("MetricsMiddleware", func() {
+	var recorder *fakeRecorder
+
+	respondWith := func(status int, delay time.Duration) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			time.Sleep(delay)
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}
+	}
+
+	BeforeEach(func() {
+		recorder = newFakeRecorder()
+	})
+
+	It("should count requests and observe latency by status class", func() {
+		rt := middlewares.MetricsMiddleware(middlewares.MetricsOptions{Recorder: recorder}).
+			Wrap(respondWith(http.StatusOK, 20*time.Millisecond))
+		req, _ := http.NewRequest("GET", "http://example.com/a", nil)
+
+		_, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.counters).To(Equal(map[string]int{
+			"http_client_requests_total{GET example.com 2xx}": 1,
+		}))
+		latencies := recorder.histograms["http_client_request_duration_seconds{GET example.com 2xx}"]
+		Expect(latencies).To(HaveLen(1))
+		Expect(latencies[0]).To(BeNumerically(">=", 0.02))
+		Expect(recorder.inFlight).To(Equal([]float64{1, 0}))
+	})
+
+	It("should label HTTP error statuses by class without counting transport errors", func() {
+		rt := middlewares.MetricsMiddleware(middlewares.MetricsOptions{Recorder: recorder}).
+			Wrap(respondWith(http.StatusServiceUnavailable, 0))
+		req, _ := http.NewRequest("POST", "http://example.com:8080/a", nil)
+
+		_, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.counters).To(Equal(map[string]int{
+			"http_client_requests_total{POST example.com:8080 5xx}": 1,
+		}))
+	})
+
+	It("should count transport errors separately", func() {
+		failing := func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}
+		rt := middlewares.MetricsMiddleware(middlewares.MetricsOptions{Recorder: recorder}).Wrap(failing)
+		req, _ := http.NewRequest("GET", "http://example.com/a", nil)
+
+		_, err := rt(req)
+		Expect(err).To(HaveOccurred())
+
+		Expect(recorder.counters).To(Equal(map[string]int{
+			"http_client_transport_errors_total{GET example.com }": 1,
+			"http_client_requests_total{GET example.com error}":    1,
+		}))
+		Expect(recorder.histograms).To(HaveKey("http_client_request_duration_seconds{GET example.com error}"))
+		Expect(recorder.gauges["http_client_requests_in_flight{GET example.com }"]).To(BeZero())
+	})
+
+	It("should track concurrent requests in flight", func() {
+		release := make(chan struct{})
+		blocking := func(req *http.Request) (*http.Response, error) {
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}
+		rt := middlewares.MetricsMiddleware(middlewares.MetricsOptions{Recorder: recorder}).Wrap(blocking)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", "http://example.com/a", nil)
+				_, _ = rt(req)
+			}()
+		}
+		Eventually(func() float64 {
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			return recorder.gauges["http_client_requests_in_flight{GET example.com }"]
+		}).Should(Equal(3.0))
+
+		close(release)
+		wg.Wait()
+		Expect(recorder.gauges["http_client_requests_in_flight{GET example.com }"]).To(BeZero())
+	})
+})
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// DefaultPropagator propagates W3C Trace Context (traceparent and tracestate) and W3C Baggage
+func DefaultPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// PropagationMiddleware creates a middleware that injects the trace context and baggage of
+// the request context into the request headers with propagator, defaulting to
+// DefaultPropagator, so context flows across services without a tracing middleware.
+// Headers already on the request are preserved: members of an existing baggage header are
+// kept alongside those of the context, which win on duplicate keys, and explicitly set
+// traceparent or tracestate headers are left untouched.
+func PropagationMiddleware(propagator propagation.TextMapPropagator) ConfigurableMiddleware {
+	if propagator == nil {
+		propagator = DefaultPropagator()
+	}
+
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := mergeBaggage(req)
+			carrier := propagation.MapCarrier{}
+			propagator.Inject(ctx, carrier)
+			if len(carrier) == 0 {
+				return next(req)
+			}
+
+			propagated := req.Clone(req.Context())
+			for key, value := range carrier {
+				if http.CanonicalHeaderKey(key) != "Baggage" && propagated.Header.Get(key) != "" {
+					continue
+				}
+				propagated.Header.Set(key, value)
+			}
+			return next(propagated)
+		}
+	}
+
+	return CreateMiddleware("propagation", nil, wrapper)
+}
+
+// mergeBaggage returns the request context with the members of the request's baggage header
+// added to its baggage, keeping the context's value for keys present in both.
+func mergeBaggage(req *http.Request) context.Context {
+	ctx := req.Context()
+	existing, err := baggage.Parse(req.Header.Get("Baggage"))
+	if err != nil || existing.Len() == 0 {
+		return ctx
+	}
+	for _, member := range baggage.FromContext(ctx).Members() {
+		if merged, err := existing.SetMember(member); err == nil {
+			existing = merged
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, existing)
+}
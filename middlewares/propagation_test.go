@@ -0,0 +1,116 @@
+package middlewares_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PropagationMiddleware", func() {
+	var sent *http.Request
+
+	transport := func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	}
+
+	withBaggage := func(ctx context.Context, header string) context.Context {
+		bag, err := baggage.Parse(header)
+		Expect(err).NotTo(HaveOccurred())
+		return baggage.ContextWithBaggage(ctx, bag)
+	}
+
+	baggageMembers := func(header string) map[string]string {
+		bag, err := baggage.Parse(header)
+		Expect(err).NotTo(HaveOccurred())
+		members := map[string]string{}
+		for _, member := range bag.Members() {
+			members[member.Key()] = member.Value()
+		}
+		return members
+	}
+
+	BeforeEach(func() {
+		sent = nil
+	})
+
+	It("should serialize context baggage into the baggage header", func() {
+		rt := middlewares.PropagationMiddleware(nil).Wrap(transport)
+		ctx := withBaggage(context.Background(), "userId=alice,tenant=acme%20corp")
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+
+		_, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(baggageMembers(sent.Header.Get("Baggage"))).To(Equal(map[string]string{
+			"userId": "alice",
+			"tenant": "acme corp",
+		}))
+		Expect(sent.Header.Get("Baggage")).To(ContainSubstring("tenant=acme%20corp"))
+		Expect(req.Header.Get("Baggage")).To(BeEmpty())
+	})
+
+	It("should inject traceparent and tracestate from the span context", func() {
+		rt := middlewares.PropagationMiddleware(nil).Wrap(transport)
+		state, err := trace.ParseTraceState("vendor=opaque")
+		Expect(err).NotTo(HaveOccurred())
+		spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: trace.FlagsSampled,
+			TraceState: state,
+			Remote:     true,
+		})
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), spanCtx)
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+
+		_, err = rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sent.Header.Get("Traceparent")).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+		Expect(sent.Header.Get("Tracestate")).To(Equal("vendor=opaque"))
+	})
+
+	It("should preserve headers already on the request", func() {
+		rt := middlewares.PropagationMiddleware(nil).Wrap(transport)
+		spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1},
+			SpanID:  trace.SpanID{1},
+		})
+		ctx := trace.ContextWithRemoteSpanContext(withBaggage(context.Background(), "userId=alice"), spanCtx)
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+		req.Header.Set("X-Custom", "kept")
+		req.Header.Set("Baggage", "region=eu,userId=bob")
+		req.Header.Set("Tracestate", "explicit=1")
+
+		_, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sent.Header.Get("X-Custom")).To(Equal("kept"))
+		Expect(sent.Header.Get("Tracestate")).To(Equal("explicit=1"))
+		Expect(baggageMembers(sent.Header.Get("Baggage"))).To(Equal(map[string]string{
+			"region": "eu",
+			"userId": "alice",
+		}))
+	})
+
+	It("should leave requests without trace context or baggage untouched", func() {
+		rt := middlewares.PropagationMiddleware(nil).Wrap(transport)
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		_, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sent).To(BeIdenticalTo(req))
+		Expect(sent.Header).To(BeEmpty())
+	})
+})
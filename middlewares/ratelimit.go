@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"sync"
 	"time"
@@ -29,8 +30,38 @@ type RateLimitOptions struct {
 	WaitOnLimit bool
 	// MaxWaitTime is the maximum time to wait when limit is reached
 	MaxWaitTime time.Duration
+	// FairQueue serves waiting requests in arrival order. Each waiter takes a ticket for the
+	// next free send slot instead of racing for tokens once its timer fires, which keeps tail
+	// latency predictable under high concurrency.
+	FairQueue bool
+	// PerHost tracks a separate token bucket for every req.URL.Host, so each host gets its own
+	// RequestsPerSecond and Burst allotment
+	PerHost bool
+	// HostIdleTimeout is how long a per-host bucket may go unused before it is dropped;
+	// defaults to DefaultHostIdleTimeout. A dropped bucket had refilled completely long before.
+	HostIdleTimeout time.Duration
+	// Adaptive adjusts the rate to the server, AIMD-style: every 429 response halves the
+	// effective rate, down to MinRPS, and every RateIncreaseAfter consecutive other responses
+	// raise it by RateIncreaseStep, up to MaxRPS. The rate starts at RequestsPerSecond.
+	Adaptive bool
+	// MinRPS is the lowest adaptive rate; defaults to a tenth of RequestsPerSecond
+	MinRPS float64
+	// MaxRPS is the highest adaptive rate; defaults to RequestsPerSecond
+	MaxRPS float64
+	// RateIncreaseStep is added to the adaptive rate on sustained success; defaults to a tenth
+	// of RequestsPerSecond
+	RateIncreaseStep float64
+	// RateIncreaseAfter is the number of consecutive non-429 responses that raise the adaptive
+	// rate; defaults to DefaultRateIncreaseAfter
+	RateIncreaseAfter int
 }
 
+// DefaultRateIncreaseAfter is how many consecutive non-429 responses raise an adaptive rate by default
+const DefaultRateIncreaseAfter = 10
+
+// DefaultHostIdleTimeout is how long an unused per-host bucket is kept by default
+const DefaultHostIdleTimeout = 5 * time.Minute
+
 // DefaultRateLimitOptions returns default rate limit options
 func DefaultRateLimitOptions() RateLimitOptions {
 	return RateLimitOptions{
@@ -41,14 +72,41 @@ func DefaultRateLimitOptions() RateLimitOptions {
 	}
 }
 
+// tokenBucket holds the tokens available to one rate limit scope
+type tokenBucket struct {
+	mu            sync.Mutex
+	tokens        float64
+	lastTimestamp time.Time
+}
+
+func newTokenBucket(burst int) *tokenBucket {
+	return &tokenBucket{
+		tokens:        float64(burst),
+		lastTimestamp: time.Now(),
+	}
+}
+
 // rateLimitMiddleware implements client-side rate limiting
 type rateLimitMiddleware struct {
 	BaseMiddleware
 	options RateLimitOptions
 
-	mu            sync.Mutex
-	tokens        float64
-	lastTimestamp time.Time
+	bucket *tokenBucket
+
+	// rate is the effective requests per second; it only changes in Adaptive mode
+	rateMu    sync.Mutex
+	rate      float64
+	successes int
+
+	hostsMu   sync.Mutex
+	hosts     map[string]*hostBucket
+	lastSweep time.Time
+}
+
+// hostBucket is a per-host bucket with the time it was last handed out
+type hostBucket struct {
+	*tokenBucket
+	lastUsed time.Time
 }
 
 // RateLimitMiddleware creates a middleware that implements client-side rate limiting
@@ -62,11 +120,34 @@ func RateLimitMiddleware(options RateLimitOptions) ConfigurableMiddleware {
 	if options.MaxWaitTime < 0 {
 		options.MaxWaitTime = DefaultRateLimitOptions().MaxWaitTime
 	}
+	if options.HostIdleTimeout <= 0 {
+		options.HostIdleTimeout = DefaultHostIdleTimeout
+	}
+	if options.Adaptive {
+		if options.MinRPS <= 0 {
+			options.MinRPS = options.RequestsPerSecond / 10
+		}
+		if options.MaxRPS <= 0 {
+			options.MaxRPS = options.RequestsPerSecond
+		}
+		if options.RateIncreaseStep <= 0 {
+			options.RateIncreaseStep = options.RequestsPerSecond / 10
+		}
+		if options.RateIncreaseAfter <= 0 {
+			options.RateIncreaseAfter = DefaultRateIncreaseAfter
+		}
+	}
 
 	mw := &rateLimitMiddleware{
-		options:       options,
-		tokens:        float64(options.Burst),
-		lastTimestamp: time.Now(),
+		options:   options,
+		bucket:    newTokenBucket(options.Burst),
+		rate:      options.RequestsPerSecond,
+		hosts:     make(map[string]*hostBucket),
+		lastSweep: time.Now(),
+	}
+
+	if options.Adaptive {
+		mw.rate = math.Min(math.Max(mw.rate, options.MinRPS), options.MaxRPS)
 	}
 
 	mw.BaseMiddleware = BaseMiddleware{
@@ -80,35 +161,114 @@ func RateLimitMiddleware(options RateLimitOptions) ConfigurableMiddleware {
 	return mw
 }
 
-func (m *rateLimitMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
-	return func(req *http.Request) (*http.Response, error) {
-		m.mu.Lock()
-
-		// Update tokens based on time elapsed
-		now := time.Now()
-		elapsed := now.Sub(m.lastTimestamp).Seconds()
-		m.lastTimestamp = now
-
-		// Add tokens for time elapsed (up to burst limit)
-		m.tokens += elapsed * m.options.RequestsPerSecond
-		maxTokens := float64(m.options.Burst)
-		if maxTokens < 1 {
-			maxTokens = 1
+// bucketFor returns the bucket that req draws tokens from. Per-host buckets are created on
+// first use, and buckets idle for longer than HostIdleTimeout are swept at most once per timeout.
+func (m *rateLimitMiddleware) bucketFor(req *http.Request) *tokenBucket {
+	if !m.options.PerHost {
+		return m.bucket
+	}
+
+	m.hostsMu.Lock()
+	defer m.hostsMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= m.options.HostIdleTimeout {
+		for host, b := range m.hosts {
+			if now.Sub(b.lastUsed) >= m.options.HostIdleTimeout {
+				delete(m.hosts, host)
+			}
 		}
-		if m.tokens > maxTokens {
-			m.tokens = maxTokens
+		m.lastSweep = now
+	}
+
+	b, ok := m.hosts[req.URL.Host]
+	if !ok {
+		b = &hostBucket{tokenBucket: newTokenBucket(m.options.Burst)}
+		m.hosts[req.URL.Host] = b
+	}
+	b.lastUsed = now
+	return b.tokenBucket
+}
+
+// CurrentRate returns the effective requests per second. It equals RequestsPerSecond unless
+// Adaptive is set. The middleware returned by RateLimitMiddleware implements
+// interface{ CurrentRate() float64 }.
+func (m *rateLimitMiddleware) CurrentRate() float64 {
+	m.rateMu.Lock()
+	defer m.rateMu.Unlock()
+	return m.rate
+}
+
+// adapt halves the rate on a 429 and raises it after enough consecutive other responses.
+// Failed round trips leave it unchanged.
+func (m *rateLimitMiddleware) adapt(resp *http.Response, err error) {
+	if err != nil || resp == nil {
+		return
+	}
+
+	m.rateMu.Lock()
+	defer m.rateMu.Unlock()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		m.rate = math.Max(m.rate/2, m.options.MinRPS)
+		m.successes = 0
+		return
+	}
+	m.successes++
+	if m.successes >= m.options.RateIncreaseAfter {
+		m.rate = math.Min(m.rate+m.options.RateIncreaseStep, m.options.MaxRPS)
+		m.successes = 0
+	}
+}
+
+// refill adds the tokens earned at rate since the last update, up to the burst limit.
+// Callers hold b.mu.
+func (b *tokenBucket) refill(rate float64, options RateLimitOptions) {
+	// Update tokens based on time elapsed
+	now := time.Now()
+	elapsed := now.Sub(b.lastTimestamp).Seconds()
+	b.lastTimestamp = now
+
+	// Add tokens for time elapsed (up to burst limit)
+	b.tokens += elapsed * rate
+	maxTokens := float64(options.Burst)
+	if maxTokens < 1 {
+		maxTokens = 1
+	}
+	if b.tokens > maxTokens {
+		b.tokens = maxTokens
+	}
+}
+
+func (m *rateLimitMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	if m.options.Adaptive {
+		limited := next
+		next = func(req *http.Request) (*http.Response, error) {
+			resp, err := limited(req)
+			m.adapt(resp, err)
+			return resp, err
 		}
+	}
+	if m.options.FairQueue {
+		return m.fairRoundTrip(next)
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		b := m.bucketFor(req)
+		b.mu.Lock()
+		rate := m.CurrentRate()
+		b.refill(rate, m.options)
 
 		// Check if we have enough tokens
-		if m.tokens < 1.0 {
+		if b.tokens < 1.0 {
 			// Calculate wait time to get a token
-			waitTime := time.Duration((1.0 - m.tokens) * float64(time.Second) / m.options.RequestsPerSecond)
+			waitTime := time.Duration((1.0 - b.tokens) * float64(time.Second) / rate)
 
 			if !m.options.WaitOnLimit || waitTime > m.options.MaxWaitTime {
 				// Return error if we're not waiting or wait time exceeds max
-				m.mu.Unlock()
+				b.mu.Unlock()
 				return nil, &RateLimitExceededError{
-					Limit:      m.options.RequestsPerSecond,
+					Limit:      rate,
 					RetryAfter: waitTime,
 				}
 			}
@@ -118,29 +278,75 @@ func (m *rateLimitMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripF
 			defer timer.Stop()
 
 			// Release lock while waiting
-			m.mu.Unlock()
+			b.mu.Unlock()
 			select {
 			case <-req.Context().Done():
 				return nil, req.Context().Err()
 			case <-timer.C:
 				// Reacquire lock after waiting
-				m.mu.Lock()
+				b.mu.Lock()
 			}
 
 			// Update timestamp and token count after waiting
-			m.lastTimestamp = time.Now()
-			m.tokens = 0
+			b.lastTimestamp = time.Now()
+			b.tokens = 0
 		}
 
 		// Consume token
-		m.tokens--
-		m.mu.Unlock()
+		b.tokens--
+		b.mu.Unlock()
 
 		// Execute the request
 		return next(req)
 	}
 }
 
+// fairRoundTrip hands out tickets: a token is consumed up front, letting the balance go
+// negative, and the debt decides how long the ticket holder waits. Tickets are issued under
+// the lock in arrival order and each maps to a later send slot, so waiters proceed FIFO.
+func (m *rateLimitMiddleware) fairRoundTrip(next core.RoundTripFunc) core.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		b := m.bucketFor(req)
+		b.mu.Lock()
+		rate := m.CurrentRate()
+		b.refill(rate, m.options)
+
+		b.tokens--
+		var waitTime time.Duration
+		if b.tokens < 0 {
+			waitTime = time.Duration(-b.tokens * float64(time.Second) / rate)
+		}
+
+		if waitTime > 0 && (!m.options.WaitOnLimit || waitTime > m.options.MaxWaitTime) {
+			// Give the ticket back, nobody waits on it
+			b.tokens++
+			b.mu.Unlock()
+			return nil, &RateLimitExceededError{
+				Limit:      rate,
+				RetryAfter: waitTime,
+			}
+		}
+		b.mu.Unlock()
+
+		if waitTime > 0 {
+			timer := time.NewTimer(waitTime)
+			defer timer.Stop()
+
+			select {
+			case <-req.Context().Done():
+				// Return the unused slot
+				b.mu.Lock()
+				b.tokens++
+				b.mu.Unlock()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+		}
+
+		return next(req)
+	}
+}
+
 // WithRequestsPerSecond sets the maximum number of requests per second
 func WithRequestsPerSecond(rps float64) func(*RateLimitOptions) {
 	return func(o *RateLimitOptions) {
@@ -169,6 +375,29 @@ func WithMaxWaitTime(duration time.Duration) func(*RateLimitOptions) {
 	}
 }
 
+// WithPerHost configures whether each host gets its own token bucket
+func WithPerHost(perHost bool) func(*RateLimitOptions) {
+	return func(o *RateLimitOptions) {
+		o.PerHost = perHost
+	}
+}
+
+// WithAdaptiveRate enables AIMD rate adaptation to 429 responses between minRPS and maxRPS
+func WithAdaptiveRate(minRPS, maxRPS float64) func(*RateLimitOptions) {
+	return func(o *RateLimitOptions) {
+		o.Adaptive = true
+		o.MinRPS = minRPS
+		o.MaxRPS = maxRPS
+	}
+}
+
+// WithFairQueue configures whether waiting requests are served in arrival order
+func WithFairQueue(fair bool) func(*RateLimitOptions) {
+	return func(o *RateLimitOptions) {
+		o.FairQueue = fair
+	}
+}
+
 // NewRateLimitMiddleware creates a rate limit middleware with custom options
 func NewRateLimitMiddleware(optFuncs ...func(*RateLimitOptions)) ConfigurableMiddleware {
 	options := DefaultRateLimitOptions()
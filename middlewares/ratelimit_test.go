@@ -2,7 +2,10 @@ package middlewares_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -266,6 +269,13 @@ var _ = Describe("RateLimit Middleware", func() {
 			Expect(actualOptions.MaxWaitTime).To(Equal(defaults.MaxWaitTime))
 		})
 
+		It("should configure per-host buckets via functional options", func() {
+			mw := middlewares.NewRateLimitMiddleware(middlewares.WithPerHost(true))
+			configured := mw.GetIdentifier().Options.(middlewares.RateLimitOptions)
+			Expect(configured.PerHost).To(BeTrue())
+			Expect(configured.HostIdleTimeout).To(Equal(middlewares.DefaultHostIdleTimeout))
+		})
+
 		It("should properly configure via functional options", func() {
 			middleware = middlewares.NewRateLimitMiddleware(
 				middlewares.WithRequestsPerSecond(42),
@@ -285,6 +295,264 @@ var _ = Describe("RateLimit Middleware", func() {
 		})
 	})
 
+	Describe("Fair queuing", func() {
+		It("should complete waiting requests in arrival order", func() {
+			middleware = middlewares.NewRateLimitMiddleware(
+				middlewares.WithRequestsPerSecond(50),
+				middlewares.WithBurst(1),
+				middlewares.WithFairQueue(true),
+			)
+			wrappedFunc := middleware.Wrap(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200}, nil
+			})
+
+			const n = 10
+			var mu sync.Mutex
+			var completed []int
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+					req, _ := http.NewRequest("GET", "https://example.com/"+strconv.Itoa(id), nil)
+					_, err := wrappedFunc(req)
+					Expect(err).NotTo(HaveOccurred())
+					mu.Lock()
+					completed = append(completed, id)
+					mu.Unlock()
+				}(i)
+				// Stagger arrivals so the arrival order is known
+				time.Sleep(2 * time.Millisecond)
+			}
+			wg.Wait()
+
+			// Allow for an occasional swap of neighbours under scheduler jitter
+			inOrder := 0
+			for i := 1; i < n; i++ {
+				if completed[i] > completed[i-1] {
+					inOrder++
+				}
+			}
+			Expect(inOrder).To(BeNumerically(">=", n-2))
+			Expect(completed[0]).To(Equal(0))
+		})
+
+		It("should space requests at the configured rate", func() {
+			options.RequestsPerSecond = 20
+			options.Burst = 1
+			options.FairQueue = true
+			middleware = middlewares.RateLimitMiddleware(options)
+			wrappedFunc := middleware.Wrap(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200}, nil
+			})
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := wrappedFunc(request)
+					Expect(err).NotTo(HaveOccurred())
+				}()
+			}
+			wg.Wait()
+
+			// The first request uses the burst token, the other four wait 50ms each in turn
+			Expect(time.Since(start)).To(BeNumerically(">=", 190*time.Millisecond))
+		})
+
+		It("should reject a ticket that would wait longer than MaxWaitTime", func() {
+			options.RequestsPerSecond = 1
+			options.Burst = 1
+			options.FairQueue = true
+			options.MaxWaitTime = 100 * time.Millisecond
+			middleware = middlewares.RateLimitMiddleware(options)
+			wrappedFunc := middleware.Wrap(mockRoundTripper)
+
+			_, err := wrappedFunc(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = wrappedFunc(request)
+			var limitErr *middlewares.RateLimitExceededError
+			Expect(errors.As(err, &limitErr)).To(BeTrue())
+			Expect(nextCalled).To(Equal(1))
+		})
+	})
+
+	Describe("Per-host buckets", func() {
+		hostRequest := func(host string) *http.Request {
+			req, err := http.NewRequest("GET", "https://"+host+"/test", nil)
+			Expect(err).NotTo(HaveOccurred())
+			return req
+		}
+
+		It("should give every host its own allotment", func() {
+			options.RequestsPerSecond = 1
+			options.Burst = 5
+			options.WaitOnLimit = false
+			options.PerHost = true
+			rt := middlewares.RateLimitMiddleware(options).Wrap(mockRoundTripper)
+
+			allowed := map[string]int{}
+			for i := 0; i < 20; i++ {
+				for _, host := range []string{"api-a.com", "api-b.com"} {
+					if _, err := rt(hostRequest(host)); err == nil {
+						allowed[host]++
+					}
+				}
+			}
+
+			Expect(allowed).To(Equal(map[string]int{"api-a.com": 5, "api-b.com": 5}))
+		})
+
+		It("should share one bucket across hosts by default", func() {
+			options.RequestsPerSecond = 1
+			options.Burst = 5
+			options.WaitOnLimit = false
+			rt := middlewares.RateLimitMiddleware(options).Wrap(mockRoundTripper)
+
+			allowed := 0
+			for i := 0; i < 20; i++ {
+				for _, host := range []string{"api-a.com", "api-b.com"} {
+					if _, err := rt(hostRequest(host)); err == nil {
+						allowed++
+					}
+				}
+			}
+
+			Expect(allowed).To(Equal(5))
+		})
+
+		It("should drop buckets that stay idle past HostIdleTimeout", func() {
+			options.RequestsPerSecond = 0.001
+			options.Burst = 1
+			options.WaitOnLimit = false
+			options.PerHost = true
+			options.HostIdleTimeout = 20 * time.Millisecond
+			rt := middlewares.RateLimitMiddleware(options).Wrap(mockRoundTripper)
+
+			_, err := rt(hostRequest("api-a.com"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rt(hostRequest("api-a.com"))
+			Expect(err).To(HaveOccurred())
+
+			time.Sleep(40 * time.Millisecond)
+			// Any lookup sweeps idle buckets, after which api-a.com starts with a fresh one
+			_, err = rt(hostRequest("api-b.com"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rt(hostRequest("api-a.com"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Adaptive rate", func() {
+		type rateReporter interface {
+			CurrentRate() float64
+		}
+
+		var status int
+
+		BeforeEach(func() {
+			status = http.StatusOK
+			options.RequestsPerSecond = 1000
+			options.Burst = 1000
+			options.Adaptive = true
+			options.MinRPS = 100
+			options.MaxRPS = 1000
+			options.RateIncreaseStep = 50
+			options.RateIncreaseAfter = 3
+			mockRoundTripper = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: status}, nil
+			}
+		})
+
+		It("should halve the rate on every 429 down to MinRPS", func() {
+			mw := middlewares.RateLimitMiddleware(options)
+			rt := mw.Wrap(mockRoundTripper)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(1000.0))
+
+			status = http.StatusTooManyRequests
+			expected := []float64{500, 250, 125, 100, 100}
+			for _, rate := range expected {
+				_, err := rt(request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mw.(rateReporter).CurrentRate()).To(Equal(rate))
+			}
+		})
+
+		It("should ramp the rate up by a fixed step after consecutive successes", func() {
+			mw := middlewares.RateLimitMiddleware(options)
+			rt := mw.Wrap(mockRoundTripper)
+
+			status = http.StatusTooManyRequests
+			_, _ = rt(request)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(500.0))
+
+			status = http.StatusOK
+			for i := 0; i < 2; i++ {
+				_, _ = rt(request)
+			}
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(500.0))
+			_, _ = rt(request)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(550.0))
+
+			// A 429 resets the success streak
+			for i := 0; i < 2; i++ {
+				_, _ = rt(request)
+			}
+			status = http.StatusTooManyRequests
+			_, _ = rt(request)
+			status = http.StatusOK
+			_, _ = rt(request)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(275.0))
+		})
+
+		It("should never exceed MaxRPS", func() {
+			options.RequestsPerSecond = 2000
+			mw := middlewares.RateLimitMiddleware(options)
+			rt := mw.Wrap(mockRoundTripper)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(1000.0))
+
+			for i := 0; i < 30; i++ {
+				_, _ = rt(request)
+			}
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(1000.0))
+		})
+
+		It("should throttle requests at the reduced rate", func() {
+			options.RequestsPerSecond = 20
+			options.Burst = 1
+			options.MinRPS = 5
+			options.MaxRPS = 20
+			options.WaitOnLimit = true
+			options.MaxWaitTime = time.Second
+			mw := middlewares.RateLimitMiddleware(options)
+			rt := mw.Wrap(mockRoundTripper)
+
+			status = http.StatusTooManyRequests
+			_, _ = rt(request)
+			_, _ = rt(request)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(5.0))
+
+			status = http.StatusOK
+			start := time.Now()
+			_, err := rt(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+		})
+
+		It("should keep the configured rate when not adaptive", func() {
+			options.Adaptive = false
+			mw := middlewares.RateLimitMiddleware(options)
+			rt := mw.Wrap(mockRoundTripper)
+
+			status = http.StatusTooManyRequests
+			_, _ = rt(request)
+			Expect(mw.(rateReporter).CurrentRate()).To(Equal(1000.0))
+		})
+	})
+
 	Describe("Error details", func() {
 		It("should provide useful error information", func() {
 			options.RequestsPerSecond = 1
@@ -30,10 +30,16 @@ func (e *RetryError) Unwrap() error {
 }
 
 type TimeoutError struct {
-	Err error
+	// Phase is the stage of the request that was in progress when the timeout hit.
+	// It is empty if the phase could not be determined.
+	Phase TimeoutPhase
+	Err   error
 }
 
 func (e *TimeoutError) Error() string {
+	if e.Phase != "" {
+		return fmt.Sprintf("request timed out during %s: %v", e.Phase, e.Err)
+	}
 	return fmt.Sprintf("request timed out: %v", e.Err)
 }
 
@@ -41,6 +47,10 @@ func (e *TimeoutError) Unwrap() error {
 	return e.Err
 }
 
+// defaultMaxInspectBody is how many response body bytes are buffered, by default, for an
+// error classifier or the WithAfterResponseRetry hook.
+const defaultMaxInspectBody = 1 << 20
+
 var bodyPool = sync.Pool{
 	New: func() interface{} {
 		return bytes.NewBuffer(make([]byte, 0, 4096))
@@ -51,12 +61,58 @@ var bodyPool = sync.Pool{
 type retryMiddleware struct {
 	BaseMiddleware
 	strategy RetryStrategy
+	// afterResponse lets the application request a retry based on the buffered response body
+	afterResponse func(resp *http.Response, body []byte) bool
+	// maxInspectBody caps how many body bytes are buffered for inspection (0 = unlimited)
+	maxInspectBody int64
+	// classifier overrides the strategy's retry decision when set
+	classifier ErrorClassifier
+}
+
+// RetryOption configures optional behavior of the retry middleware
+type RetryOption func(*retryMiddleware)
+
+// WithAfterResponseRetry registers a hook that is called with every response the strategy
+// would not retry, along with its fully buffered body. Returning true retries the request,
+// e.g. for APIs that answer 200 with a {"status":"pending"} envelope. Body-based retries
+// use the strategy's delay and are bounded by its MaxAttempts.
+func WithAfterResponseRetry(fn func(resp *http.Response, body []byte) bool) RetryOption {
+	return func(m *retryMiddleware) {
+		m.afterResponse = fn
+	}
 }
 
-// RetryMiddleware returns a middleware that retries a request according to the provided strategy
-func RetryMiddleware(strategy RetryStrategy) ConfigurableMiddleware {
+// WithMaxBodyForAutoRetry caps the number of body bytes buffered for the WithAfterResponseRetry
+// hook and for an error classifier. Responses larger than maxBytes are passed through without
+// the body check, so large streamed downloads are not read twice. It defaults to 1 MiB; a value
+// of 0 or less means no cap.
+func WithMaxBodyForAutoRetry(maxBytes int64) RetryOption {
+	return func(m *retryMiddleware) {
+		m.maxInspectBody = maxBytes
+	}
+}
+
+// WithErrorClassifier makes the retry middleware retry exactly the attempts that classifier
+// reports as ErrorClassRetryable, up to the strategy's MaxAttempts; the strategy still supplies
+// the delays. It takes precedence over a classifier set on the client. The classifier sees the
+// response body buffered up to WithMaxBodyForAutoRetry; larger bodies, streaming requests and
+// the last attempt are left to the strategy without buffering.
+func WithErrorClassifier(classifier ErrorClassifier) RetryOption {
+	return func(m *retryMiddleware) {
+		m.classifier = classifier
+	}
+}
+
+// RetryMiddleware returns a middleware that retries a request according to the provided strategy.
+// It panics if an ExponentialBackoffStrategy sets both Jitter and JitterFraction.
+func RetryMiddleware(strategy RetryStrategy, opts ...RetryOption) ConfigurableMiddleware {
+	validateStrategy(strategy)
 	mw := &retryMiddleware{
-		strategy: strategy,
+		strategy:       strategy,
+		maxInspectBody: defaultMaxInspectBody,
+	}
+	for _, opt := range opts {
+		opt(mw)
 	}
 
 	// Initialize the embedded BaseMiddleware fields.
@@ -87,10 +143,36 @@ func ExponentialRetryMiddleware(
 	return RetryMiddleware(strategy)
 }
 
+// ExponentialRetryMiddlewareWithJitter is like ExponentialRetryMiddleware but randomly shortens
+// each delay by up to jitterFraction (0 to 1) of its value, so delays stay within maxDelay.
+func ExponentialRetryMiddlewareWithJitter(
+	maxAttempts int,
+	initialDelay time.Duration,
+	maxDelay time.Duration,
+	factor float64,
+	jitterFraction float64,
+) ConfigurableMiddleware {
+	strategy := NewExponentialBackoffStrategy(initialDelay, maxDelay, factor, maxAttempts)
+	strategy.JitterFraction = jitterFraction
+	return RetryMiddleware(strategy)
+}
+
 func (m *retryMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc {
 	return func(req *http.Request) (*http.Response, error) {
+		// Prefer GetBody for a fresh body on each attempt, then seeking back a body that is
+		// itself an io.ReadSeekCloser; only buffer when neither is available.
+		useGetBody := req.Body != nil && req.GetBody != nil
+
+		var rewind *rewindableBody
+		if req.Body != nil && !useGetBody {
+			if body, ok := newRewindableBody(req.Body); ok {
+				rewind = body
+				defer rewind.release()
+			}
+		}
+
 		var buf *bytes.Buffer
-		if req.Body != nil {
+		if req.Body != nil && !useGetBody && rewind == nil {
 			buf = bodyPool.Get().(*bytes.Buffer)
 			defer bodyPool.Put(buf)
 			buf.Reset()
@@ -106,15 +188,32 @@ func (m *retryMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc
 		var err error
 		var attempt int
 
+		req, phase := TrackTimeoutPhase(req)
+
 		for {
 			if ctxErr := req.Context().Err(); ctxErr != nil {
 				return nil, ctxErr
 			}
 
-			// Clone the request for each attempt only if it exists
-			if buf != nil {
+			// Reset the body for each attempt only if it exists
+			switch {
+			case useGetBody:
+				if attempt > 0 {
+					body, bodyErr := req.GetBody()
+					if bodyErr != nil {
+						return nil, fmt.Errorf("failed to get request body for retry: %w", bodyErr)
+					}
+					req.Body = body
+				}
+			case rewind != nil:
+				body, rewindErr := rewind.attempt()
+				if rewindErr != nil {
+					return nil, fmt.Errorf("failed to rewind request body for retry: %w", rewindErr)
+				}
+				req.Body = body
+			case buf != nil:
 				req.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
-			} else {
+			default:
 				req.Body = nil
 			}
 
@@ -126,7 +225,11 @@ func (m *retryMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc
 			resp, err = next(req)
 
 			// Check if we should retry
-			if !m.strategy.ShouldRetry(attempt, resp, err) {
+			retry, inspectErr := m.shouldRetry(req, attempt, resp, err)
+			if inspectErr != nil {
+				return nil, inspectErr
+			}
+			if !retry {
 				break
 			}
 
@@ -151,7 +254,7 @@ func (m *retryMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc
 		// Check for network timeouts
 		var netErr net.Error
 		if errors.As(err, &netErr) && netErr.Timeout() {
-			err = &TimeoutError{Err: netErr}
+			err = &TimeoutError{Phase: phase(), Err: netErr}
 		}
 
 		// If we still have an error after all retries
@@ -163,6 +266,95 @@ func (m *retryMiddleware) roundTrip(next core.RoundTripFunc) core.RoundTripFunc
 	}
 }
 
+// inspectResponse buffers the response body, hands it to the afterResponse hook,
+// and restores the body so it can be read again by the caller.
+// Bodies larger than maxInspectBody skip the hook and are passed through unread.
+func (m *retryMiddleware) inspectResponse(req *http.Request, resp *http.Response) (bool, error) {
+	body, ok, err := m.bufferResponse(req, resp)
+	if err != nil || !ok {
+		return false, err
+	}
+	return m.afterResponse(resp, body), nil
+}
+
+// bufferResponse buffers the response body for inspection, up to maxInspectBody bytes.
+// Streaming responses are never buffered, since the caller reads them as they arrive.
+func (m *retryMiddleware) bufferResponse(req *http.Request, resp *http.Response) ([]byte, bool, error) {
+	if IsStreamingRequest(req.Context()) {
+		return nil, false, nil
+	}
+	return bufferResponseBody(resp, m.maxInspectBody)
+}
+
+// bufferResponseBody reads the response body into memory and replaces it with a replayable copy.
+// It reports false, leaving the body readable from the start, when the body exceeds maxBytes.
+// A maxBytes of 0 or less buffers the whole body.
+func bufferResponseBody(resp *http.Response, maxBytes int64) ([]byte, bool, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, true, nil
+	}
+
+	if maxBytes > 0 {
+		if resp.ContentLength > maxBytes {
+			return nil, false, nil
+		}
+
+		prefix, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, false, fmt.Errorf("failed to read response body for inspection: %w", err)
+		}
+		if int64(len(prefix)) > maxBytes {
+			// Too large to inspect: stitch the consumed prefix back in front of the rest.
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+			return nil, false, nil
+		}
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(prefix))
+		return prefix, true, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read response body for inspection: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true, nil
+}
+
+// shouldRetry decides whether the attempt that produced resp and err should be retried.
+// A classifier, if any, takes precedence over the strategy and the afterResponse hook.
+func (m *retryMiddleware) shouldRetry(req *http.Request, attempt int, resp *http.Response, err error) (bool, error) {
+	classifier := m.classifier
+	if classifier == nil {
+		classifier = ErrorClassifierFromContext(req.Context())
+	}
+	if classifier != nil {
+		if attempt >= maxAttempts(m.strategy) {
+			return false, nil
+		}
+		class, ok, classifyErr := classifyExchange(classifier, req, resp, err, m.maxInspectBody)
+		if classifyErr != nil {
+			return false, classifyErr
+		}
+		if ok {
+			return class == ErrorClassRetryable, nil
+		}
+	}
+
+	if m.strategy.ShouldRetry(attempt, resp, err) {
+		return true, nil
+	}
+	if err != nil || m.afterResponse == nil || attempt >= maxAttempts(m.strategy) {
+		return false, nil
+	}
+	return m.inspectResponse(req, resp)
+}
+
 // DrainAndClose reads the remaining data from resp.Body and closes it.
 func DrainAndClose(resp *http.Response) {
 	if resp.Body != nil {
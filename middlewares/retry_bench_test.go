@@ -0,0 +1,48 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jzx17/gofetch/middlewares"
+)
+
+// benchmarkRetryBody retries a 64 KiB request body once per operation; newBody supplies the
+// body, which is either seekable or a plain reader that must be buffered.
+func benchmarkRetryBody(b *testing.B, newBody func(data []byte) io.ReadCloser) {
+	data := bytes.Repeat([]byte("x"), 64<<10)
+	strategy := middlewares.NewConstantDelayStrategy(0, 1)
+	var calls int
+	rt := middlewares.RetryMiddleware(strategy).Wrap(func(req *http.Request) (*http.Response, error) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		calls++
+		if calls%2 == 1 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest("POST", "http://example.com", nil)
+		req.Body = newBody(data)
+		if _, err := rt(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRetrySeekableBody(b *testing.B) {
+	benchmarkRetryBody(b, func(data []byte) io.ReadCloser {
+		return &seekableBody{Reader: bytes.NewReader(data)}
+	})
+}
+
+func BenchmarkRetryBufferedBody(b *testing.B) {
+	benchmarkRetryBody(b, func(data []byte) io.ReadCloser {
+		return io.NopCloser(bytes.NewReader(data))
+	})
+}
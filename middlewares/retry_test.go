@@ -6,6 +6,8 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -228,6 +230,163 @@ var _ = Describe("Retry Middleware", func() {
 			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 			Expect(callCount).To(Equal(int32(2)))
 		})
+
+		It("should use GetBody instead of buffering when it is set", func() {
+			var getterCalls, callCount int32
+			getBody := func() (io.ReadCloser, error) {
+				atomic.AddInt32(&getterCalls, 1)
+				return io.NopCloser(bytes.NewBufferString("streamed body")), nil
+			}
+
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				data, err := io.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("streamed body"))
+				if atomic.AddInt32(&callCount, 1) < 3 {
+					return nil, &test.FakeNetError{Msg: "simulated network error"}
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString("success")),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("POST", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Body, _ = getBody()
+			req.GetBody = getBody
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(callCount).To(Equal(int32(3)))
+			// One body for the initial request, then one per retry
+			Expect(getterCalls).To(Equal(int32(3)))
+		})
+
+		It("should rewind a seekable body instead of buffering it", func() {
+			var callCount int32
+			var attempts []io.ReadCloser
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				attempts = append(attempts, req.Body)
+				data, err := io.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("seekable body"))
+				_ = req.Body.Close()
+				if atomic.AddInt32(&callCount, 1) < 3 {
+					return nil, &test.FakeNetError{Msg: "simulated network error"}
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString("success")),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			body := &seekableBody{Reader: bytes.NewReader([]byte("xxseekable body"))}
+			_, _ = body.Seek(2, io.SeekStart)
+			req, err := http.NewRequest("POST", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Body = body
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(callCount).To(Equal(int32(3)))
+			Expect(body.closes).To(Equal(1))
+
+			// Earlier attempts can no longer read the rewound body
+			_, err = attempts[0].Read(make([]byte, 1))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should rewind a file passed to http.NewRequest", func() {
+			file, err := os.CreateTemp(GinkgoT().TempDir(), "body")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = file.WriteString("file body")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = file.Seek(0, io.SeekStart)
+			Expect(err).NotTo(HaveOccurred())
+
+			var bodies []string
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				data, err := io.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				bodies = append(bodies, string(data))
+				_ = req.Body.Close()
+				if len(bodies) < 2 {
+					return nil, &test.FakeNetError{Msg: "simulated network error"}
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header)}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("POST", baseURL, file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(req.GetBody).To(BeNil())
+
+			_, err = wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(Equal([]string{"file body", "file body"}))
+		})
+
+		It("should close a rewound body only once the final attempt is done with it", func() {
+			var sent io.ReadCloser
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				sent = req.Body
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString("success")),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			body := &seekableBody{Reader: bytes.NewReader([]byte("payload"))}
+			req, err := http.NewRequest("POST", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Body = body
+
+			_, err = wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body.closes).To(BeZero())
+
+			// The transport closes the request body once it has been sent
+			data, err := io.ReadAll(sent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("payload"))
+			Expect(sent.Close()).To(Succeed())
+			Expect(body.closes).To(Equal(1))
+		})
+
+		It("should fail when GetBody returns an error on retry", func() {
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				return nil, &test.FakeNetError{Msg: "simulated network error"}
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("POST", baseURL, bytes.NewBufferString("body"))
+			Expect(err).NotTo(HaveOccurred())
+			req.GetBody = func() (io.ReadCloser, error) {
+				return nil, errors.New("source gone")
+			}
+
+			_, err = wrapped(req)
+			Expect(err).To(MatchError(ContainSubstring("source gone")))
+		})
 	})
 
 	// Test SimpleRetryMiddleware convenience function
@@ -365,4 +524,620 @@ var _ = Describe("Retry Middleware", func() {
 			Expect(callCount).To(Equal(int32(2)))
 		})
 	})
+	Context("with an after-response retry hook", func() {
+		var (
+			callCount int32
+			bodies    []string
+		)
+
+		BeforeEach(func() {
+			callCount = 0
+			bodies = nil
+		})
+
+		fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+			idx := atomic.AddInt32(&callCount, 1) - 1
+			body := bodies[len(bodies)-1]
+			if int(idx) < len(bodies) {
+				body = bodies[idx]
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}, nil
+		}
+
+		isPending := func(resp *http.Response, body []byte) bool {
+			return bytes.Contains(body, []byte(`"pending"`))
+		}
+
+		It("should retry a 200 response whose body reports pending", func() {
+			bodies = []string{`{"status":"pending"}`, `{"status":"done"}`}
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			mw := middlewares.RetryMiddleware(strategy, middlewares.WithAfterResponseRetry(isPending))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(2)))
+
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"status":"done"}`))
+		})
+
+		It("should not retry a 200 response whose body reports done", func() {
+			bodies = []string{`{"status":"done"}`}
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			mw := middlewares.RetryMiddleware(strategy, middlewares.WithAfterResponseRetry(isPending))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(1)))
+
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"status":"done"}`))
+		})
+
+		It("should stop retrying after the strategy's max attempts", func() {
+			bodies = []string{`{"status":"pending"}`}
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 2)
+			mw := middlewares.RetryMiddleware(strategy, middlewares.WithAfterResponseRetry(isPending))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(callCount).To(Equal(int32(3)))
+		})
+
+		It("should inspect and retry a small body under WithMaxBodyForAutoRetry", func() {
+			bodies = []string{`{"status":"pending"}`, `{"status":"done"}`}
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			mw := middlewares.RetryMiddleware(strategy,
+				middlewares.WithAfterResponseRetry(isPending),
+				middlewares.WithMaxBodyForAutoRetry(64))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(2)))
+		})
+
+		It("should pass a large body through without the body check", func() {
+			large := `{"status":"pending","padding":"` + string(bytes.Repeat([]byte("x"), 256)) + `"}`
+			bodies = []string{large}
+			hookCalled := false
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			mw := middlewares.RetryMiddleware(strategy,
+				middlewares.WithAfterResponseRetry(func(resp *http.Response, body []byte) bool {
+					hookCalled = true
+					return isPending(resp, body)
+				}),
+				middlewares.WithMaxBodyForAutoRetry(64))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(1)))
+			Expect(hookCalled).To(BeFalse())
+
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(large))
+		})
+
+		It("should skip the body check when Content-Length exceeds the cap", func() {
+			strategy := middlewares.NewConstantDelayStrategy(1*time.Millisecond, 3)
+			mw := middlewares.RetryMiddleware(strategy,
+				middlewares.WithAfterResponseRetry(isPending),
+				middlewares.WithMaxBodyForAutoRetry(8))
+			wrapped := mw.(roundTripperWrapper).Wrap(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&callCount, 1)
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					ContentLength: 20,
+					Body:          io.NopCloser(bytes.NewBufferString(`{"status":"pending"}`)),
+					Header:        make(http.Header),
+				}, nil
+			})
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(1)))
+		})
+	})
+	Context("with a rate-limit reset strategy", func() {
+		now := time.Unix(1700000000, 0)
+
+		limitedHeader := func(reset string) http.Header {
+			h := make(http.Header)
+			h.Set("X-RateLimit-Remaining", "0")
+			h.Set("X-RateLimit-Reset", reset)
+			return h
+		}
+
+		It("should compute the wait from an epoch-seconds reset", func() {
+			wait, ok := middlewares.RateLimitResetDelay(limitedHeader("1700000042"), now)
+			Expect(ok).To(BeTrue())
+			Expect(wait).To(Equal(42 * time.Second))
+		})
+
+		It("should compute the wait from a delta-seconds reset", func() {
+			wait, ok := middlewares.RateLimitResetDelay(limitedHeader("7"), now)
+			Expect(ok).To(BeTrue())
+			Expect(wait).To(Equal(7 * time.Second))
+		})
+
+		It("should not wait for a reset time in the past", func() {
+			wait, ok := middlewares.RateLimitResetDelay(limitedHeader("1699999990"), now)
+			Expect(ok).To(BeTrue())
+			Expect(wait).To(BeZero())
+		})
+
+		It("should ignore headers while requests remain", func() {
+			h := limitedHeader("7")
+			h.Set("X-RateLimit-Remaining", "12")
+			_, ok := middlewares.RateLimitResetDelay(h, now)
+			Expect(ok).To(BeFalse())
+
+			_, ok = middlewares.RateLimitResetDelay(make(http.Header), now)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should wait until the reset before retrying a 403 or 429", func() {
+			fallback := middlewares.NewConstantDelayStrategy(time.Second, 3)
+			strategy := middlewares.NewRateLimitResetStrategy(fallback, 0)
+
+			reset := time.Now().Add(30 * time.Second).Unix()
+			resp := &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     limitedHeader(strconv.FormatInt(reset, 10)),
+			}
+			Expect(strategy.ShouldRetry(0, resp, nil)).To(BeTrue())
+			Expect(strategy.NextDelay(1, resp, nil)).To(BeNumerically("~", 30*time.Second, time.Second))
+
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Header = limitedHeader("5")
+			Expect(strategy.NextDelay(1, resp, nil)).To(Equal(5 * time.Second))
+			Expect(strategy.ShouldRetry(3, resp, nil)).To(BeFalse())
+		})
+
+		It("should defer to the fallback without rate-limit headers", func() {
+			fallback := middlewares.NewConstantDelayStrategy(time.Second, 3)
+			strategy := middlewares.NewRateLimitResetStrategy(fallback, 0)
+
+			resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+			Expect(strategy.ShouldRetry(0, resp, nil)).To(BeTrue())
+			Expect(strategy.NextDelay(1, resp, nil)).To(Equal(time.Second))
+
+			resp.StatusCode = http.StatusForbidden
+			Expect(strategy.ShouldRetry(0, resp, nil)).To(BeFalse())
+		})
+
+		It("should not retry when the reset is beyond MaxWait", func() {
+			strategy := middlewares.NewRateLimitResetStrategy(middlewares.NewConstantDelayStrategy(time.Second, 3), time.Minute)
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: limitedHeader("3600")}
+			Expect(strategy.ShouldRetry(0, resp, nil)).To(BeFalse())
+		})
+
+		It("should retry through the middleware after the reset", func() {
+			var callCount int32
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&callCount, 1) == 1 {
+					return &http.Response{
+						StatusCode: http.StatusForbidden,
+						Body:       io.NopCloser(bytes.NewBufferString("rate limited")),
+						Header:     limitedHeader("0.01"),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString("ok")),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewRateLimitResetStrategy(middlewares.NewConstantDelayStrategy(time.Hour, 3), 0)
+			wrapped := middlewares.RetryMiddleware(strategy).(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(callCount).To(Equal(int32(2)))
+		})
+	})
+	Context("with a Retry-After header", func() {
+		retryAfter := func(value string) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {value}},
+			}
+		}
+
+		It("should wait the delta-seconds requested by the server", func() {
+			exponential := middlewares.NewExponentialBackoffStrategy(10*time.Millisecond, time.Minute, 2, 3)
+			constant := middlewares.NewConstantDelayStrategy(10*time.Millisecond, 3)
+
+			Expect(exponential.NextDelay(1, retryAfter("2"), nil)).To(Equal(2 * time.Second))
+			Expect(constant.NextDelay(1, retryAfter("2"), nil)).To(Equal(2 * time.Second))
+		})
+
+		It("should wait until an HTTP-date two seconds in the future", func() {
+			date := time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+			exponential := middlewares.NewExponentialBackoffStrategy(10*time.Millisecond, time.Minute, 2, 3)
+			constant := middlewares.NewConstantDelayStrategy(10*time.Millisecond, 3)
+
+			// HTTP-dates have one second resolution
+			Expect(exponential.NextDelay(1, retryAfter(date), nil)).To(BeNumerically("~", 2*time.Second, time.Second))
+			Expect(constant.NextDelay(1, retryAfter(date), nil)).To(BeNumerically("~", 2*time.Second, time.Second))
+		})
+
+		It("should cap the requested wait at MaxDelay", func() {
+			strategy := middlewares.NewExponentialBackoffStrategy(10*time.Millisecond, 500*time.Millisecond, 2, 3)
+
+			Expect(strategy.NextDelay(1, retryAfter("30"), nil)).To(Equal(500 * time.Millisecond))
+		})
+
+		It("should cap the wait requested from a constant strategy", func() {
+			strategy := middlewares.NewConstantDelayStrategy(10*time.Millisecond, 3)
+			Expect(strategy.NextDelay(1, retryAfter("86400"), nil)).To(Equal(middlewares.DefaultMaxRetryAfter))
+
+			strategy.MaxDelay = 500 * time.Millisecond
+			Expect(strategy.NextDelay(1, retryAfter("30"), nil)).To(Equal(500 * time.Millisecond))
+		})
+
+		It("should fall back to the computed delay when the header is unusable", func() {
+			strategy := middlewares.NewConstantDelayStrategy(10*time.Millisecond, 3)
+
+			Expect(strategy.NextDelay(1, retryAfter("soon"), nil)).To(Equal(10 * time.Millisecond))
+			Expect(strategy.NextDelay(1, retryAfter("-1"), nil)).To(Equal(10 * time.Millisecond))
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(10 * time.Millisecond))
+		})
+
+		It("should treat a past HTTP-date as no wait", func() {
+			date := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+			strategy := middlewares.NewConstantDelayStrategy(10*time.Millisecond, 3)
+
+			Expect(strategy.NextDelay(1, retryAfter(date), nil)).To(BeZero())
+		})
+
+		It("should wait the requested time between attempts", func() {
+			var calls int32
+			next := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					resp := retryAfter("1")
+					resp.Body = http.NoBody
+					return resp, nil
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			strategy := middlewares.NewExponentialBackoffStrategy(time.Millisecond, 5*time.Second, 2, 3)
+
+			req, err := http.NewRequest("GET", "http://example.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			start := time.Now()
+			resp, err := middlewares.RetryMiddleware(strategy).Wrap(next)(req)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+	})
+
+	Context("with exponential backoff", func() {
+		It("should grow delays by Factor from InitialDelay on the first retry", func() {
+			strategy := middlewares.NewExponentialBackoffStrategy(100*time.Millisecond, time.Second, 2, 5)
+
+			cases := []struct {
+				attempt int
+				delay   time.Duration
+			}{
+				{attempt: 1, delay: 100 * time.Millisecond},
+				{attempt: 2, delay: 200 * time.Millisecond},
+				{attempt: 3, delay: 400 * time.Millisecond},
+				{attempt: 4, delay: 800 * time.Millisecond},
+				{attempt: 5, delay: time.Second}, // 1600ms capped at MaxDelay
+			}
+			for _, c := range cases {
+				Expect(strategy.NextDelay(c.attempt, nil, nil)).To(Equal(c.delay), "attempt %d", c.attempt)
+			}
+		})
+
+		It("should clamp huge attempts to MaxDelay instead of overflowing", func() {
+			strategy := middlewares.NewExponentialBackoffStrategy(100*time.Millisecond, time.Minute, 2, 5)
+
+			Expect(strategy.NextDelay(64, nil, nil)).To(Equal(time.Minute))
+			Expect(strategy.NextDelay(10000, nil, nil)).To(Equal(time.Minute))
+		})
+
+		It("should wait InitialDelay before the first retry of a request", func() {
+			var calls int32
+			var times []time.Time
+			next := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				times = append(times, time.Now())
+				status := http.StatusServiceUnavailable
+				if atomic.AddInt32(&calls, 1) == 3 {
+					status = http.StatusOK
+				}
+				return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+			})
+			strategy := middlewares.NewExponentialBackoffStrategy(50*time.Millisecond, time.Second, 4, 3)
+
+			req, err := http.NewRequest("GET", "http://example.com", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = middlewares.RetryMiddleware(strategy).Wrap(next)(req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(times).To(HaveLen(3))
+			Expect(times[1].Sub(times[0])).To(BeNumerically("~", 50*time.Millisecond, 40*time.Millisecond))
+			Expect(times[2].Sub(times[1])).To(BeNumerically(">=", 200*time.Millisecond))
+		})
+	})
+
+	Context("with jittered exponential backoff", func() {
+		It("should jitter delays within bounds using the rand source", func() {
+			strategy := middlewares.NewExponentialBackoffStrategy(100*time.Millisecond, 300*time.Millisecond, 1, 5)
+			strategy.JitterFraction = 0.5
+
+			strategy.Rand = func() float64 { return 0 }
+			unjittered := strategy.NextDelay(1, nil, nil)
+
+			strategy.Rand = func() float64 { return 0.999999 }
+			shortest := strategy.NextDelay(1, nil, nil)
+			Expect(shortest).To(BeNumerically("~", unjittered/2, time.Millisecond))
+
+			strategy.Rand = nil
+			for attempt := 1; attempt <= 5; attempt++ {
+				capped := 300 * time.Millisecond
+				for i := 0; i < 50; i++ {
+					delay := strategy.NextDelay(attempt, nil, nil)
+					Expect(delay).To(BeNumerically("<=", capped))
+					Expect(delay).To(BeNumerically(">=", 0))
+				}
+			}
+		})
+
+		It("should respect maxDelay after jitter", func() {
+			strategy := middlewares.NewExponentialBackoffStrategy(time.Second, 2*time.Second, 2, 5)
+			strategy.JitterFraction = 0.25
+			strategy.Rand = func() float64 { return 0.5 }
+
+			Expect(strategy.NextDelay(4, nil, nil)).To(Equal(2*time.Second - 250*time.Millisecond))
+		})
+
+		It("should reject a strategy that sets both jitter mechanisms", func() {
+			strategy := middlewares.NewExponentialBackoffStrategyWithJitter(100*time.Millisecond, time.Second, 2, 3, middlewares.JitterFull)
+			strategy.JitterFraction = 0.5
+
+			Expect(func() { middlewares.RetryMiddleware(strategy) }).To(PanicWith(ContainSubstring("not both")))
+			Expect(func() {
+				middlewares.RetryMiddleware(middlewares.NewRateLimitResetStrategy(strategy, 0))
+			}).To(PanicWith(ContainSubstring("not both")))
+		})
+
+		It("should apply full and equal jitter modes to exponential delays", func() {
+			strategy := middlewares.NewExponentialBackoffStrategyWithJitter(100*time.Millisecond, time.Second, 1, 5, middlewares.JitterFull)
+			strategy.Rand = func() float64 { return 0 }
+			computed := middlewares.NewExponentialBackoffStrategy(100*time.Millisecond, time.Second, 1, 5).NextDelay(1, nil, nil)
+
+			Expect(strategy.NextDelay(1, nil, nil)).To(BeZero())
+			strategy.Rand = func() float64 { return 0.5 }
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(computed / 2))
+
+			strategy.Jitter = middlewares.JitterEqual
+			strategy.Rand = func() float64 { return 0 }
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(computed / 2))
+			strategy.Rand = func() float64 { return 0.5 }
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(computed/2 + computed/4))
+		})
+
+		It("should keep jittered delays within their bounds with the default source", func() {
+			full := middlewares.NewExponentialBackoffStrategyWithJitter(100*time.Millisecond, 300*time.Millisecond, 2, 5, middlewares.JitterFull)
+			equal := middlewares.NewExponentialBackoffStrategyWithJitter(100*time.Millisecond, 300*time.Millisecond, 2, 5, middlewares.JitterEqual)
+
+			distinct := map[time.Duration]bool{}
+			for i := 0; i < 50; i++ {
+				delay := full.NextDelay(3, nil, nil)
+				Expect(delay).To(BeNumerically(">=", 0))
+				Expect(delay).To(BeNumerically("<=", 300*time.Millisecond))
+				distinct[delay] = true
+
+				delay = equal.NextDelay(3, nil, nil)
+				Expect(delay).To(BeNumerically(">=", 150*time.Millisecond))
+				Expect(delay).To(BeNumerically("<=", 300*time.Millisecond))
+			}
+			Expect(len(distinct)).To(BeNumerically(">", 1))
+		})
+
+		It("should jitter constant delays", func() {
+			strategy := middlewares.NewConstantDelayStrategy(200*time.Millisecond, 3)
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(200 * time.Millisecond))
+
+			strategy.Jitter = middlewares.JitterFull
+			strategy.Rand = func() float64 { return 0.25 }
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(50 * time.Millisecond))
+
+			strategy.Jitter = middlewares.JitterEqual
+			Expect(strategy.NextDelay(1, nil, nil)).To(Equal(125 * time.Millisecond))
+		})
+
+		It("should configure jitter through ExponentialRetryMiddlewareWithJitter", func() {
+			mw := middlewares.ExponentialRetryMiddlewareWithJitter(3, time.Millisecond, 10*time.Millisecond, 2, 0.3)
+			strategy, ok := mw.GetIdentifier().Options.(*middlewares.ExponentialBackoffStrategy)
+			Expect(ok).To(BeTrue())
+			Expect(strategy.JitterFraction).To(Equal(0.3))
+			Expect(strategy.MaxDelay).To(Equal(10 * time.Millisecond))
+			Expect(strategy.MaxAttempts).To(Equal(3))
+		})
+	})
+	Context("with an error classifier", func() {
+		envelopeClassifier := func(resp *http.Response, err error) middlewares.ErrorClass {
+			if err != nil {
+				return middlewares.ErrorClassRetryable
+			}
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				return middlewares.ErrorClassFatal
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if bytes.Contains(body, []byte(`"error"`)) {
+				return middlewares.ErrorClassRetryable
+			}
+			return middlewares.ErrorClassSuccess
+		}
+
+		It("should classify a 200 with an error envelope as retryable", func() {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":"try again"}`)),
+			}
+			Expect(envelopeClassifier(resp, nil)).To(Equal(middlewares.ErrorClassRetryable))
+		})
+
+		It("should expose the context classifier to failure-counting middlewares", func() {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":"try again"}`)),
+			}
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok, err := middlewares.ClassifyExchange(req, resp, nil, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			req = req.WithContext(middlewares.WithErrorClassifierContext(context.Background(), envelopeClassifier))
+			class, ok, err := middlewares.ClassifyExchange(req, resp, nil, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(class.Failed()).To(BeTrue())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"error":"try again"}`))
+		})
+
+		It("should retry classified failures and restore the final body", func() {
+			var callCount int32
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				body := `{"error":"busy"}`
+				if atomic.AddInt32(&callCount, 1) == 3 {
+					body = `{"data":"ok"}`
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(body)),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(time.Millisecond, 5)
+			mw := middlewares.RetryMiddleware(strategy, middlewares.WithErrorClassifier(envelopeClassifier))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(3)))
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"data":"ok"}`))
+		})
+
+		It("should not retry a status the strategy would retry when classified fatal", func() {
+			var callCount int32
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&callCount, 1)
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(bytes.NewBufferString("down")),
+					Header:     make(http.Header),
+				}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(time.Millisecond, 5)
+			mw := middlewares.RetryMiddleware(strategy)
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			ctx := middlewares.WithErrorClassifierContext(context.Background(), envelopeClassifier)
+			req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(callCount).To(Equal(int32(1)))
+		})
+
+		It("should not buffer streaming responses or the last attempt", func() {
+			var classified int32
+			classifier := func(resp *http.Response, err error) middlewares.ErrorClass {
+				atomic.AddInt32(&classified, 1)
+				return middlewares.ErrorClassRetryable
+			}
+			var callCount int32
+			var lastBody *seekableBody
+			fakeRoundTrip := func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&callCount, 1)
+				lastBody = &seekableBody{Reader: bytes.NewReader([]byte("data: 1\n\n"))}
+				return &http.Response{StatusCode: http.StatusOK, Body: lastBody, Header: make(http.Header)}, nil
+			}
+
+			strategy := middlewares.NewConstantDelayStrategy(time.Millisecond, 1)
+			mw := middlewares.RetryMiddleware(strategy, middlewares.WithErrorClassifier(classifier))
+			wrapped := mw.(roundTripperWrapper).Wrap(fakeRoundTrip)
+
+			req, err := http.NewRequestWithContext(middlewares.MarkStreamingRequest(context.Background()), "GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body).To(BeIdenticalTo(lastBody))
+			Expect(callCount).To(Equal(int32(1)))
+			Expect(classified).To(BeZero())
+
+			req, err = http.NewRequest("GET", baseURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err = wrapped(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callCount).To(Equal(int32(3)))
+			Expect(classified).To(Equal(int32(1)))
+			Expect(resp.Body).To(BeIdenticalTo(lastBody))
+		})
+	})
 })
+
+// seekableBody is a request body that supports seeking and counts how often it is closed
+type seekableBody struct {
+	*bytes.Reader
+	closes int
+}
+
+func (b *seekableBody) Close() error {
+	b.closes++
+	return nil
+}
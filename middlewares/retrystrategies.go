@@ -2,8 +2,13 @@ package middlewares
 
 import (
 	"errors"
+	"math"
+	"math/rand"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,11 +21,75 @@ type RetryStrategy interface {
 	ShouldRetry(attempt int, resp *http.Response, err error) bool
 }
 
+// JitterMode selects how a backoff strategy randomizes its delays
+type JitterMode int
+
+const (
+	// JitterNone uses the computed delay as is
+	JitterNone JitterMode = iota
+	// JitterFull picks a random delay in [0, computed]
+	JitterFull
+	// JitterEqual picks a random delay in [computed/2, computed]
+	JitterEqual
+)
+
+// jitterRand is a per-strategy random source, so concurrent retries do not contend on a global one
+type jitterRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newJitterRand() *jitterRand {
+	return &jitterRand{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (r *jitterRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+// randomSource returns override when set, else the strategy's own source, else math/rand.
+func randomSource(override func() float64, own *jitterRand) func() float64 {
+	if override != nil {
+		return override
+	}
+	if own != nil {
+		return own.Float64
+	}
+	return rand.Float64
+}
+
+// applyJitter randomizes delay according to mode using random, which returns a value in [0, 1).
+func applyJitter(delay time.Duration, mode JitterMode, random func() float64) time.Duration {
+	switch mode {
+	case JitterFull:
+		return time.Duration(float64(delay) * random())
+	case JitterEqual:
+		half := delay / 2
+		return delay - half + time.Duration(float64(half)*random())
+	default:
+		return delay
+	}
+}
+
+// DefaultMaxRetryAfter caps the wait a Retry-After header can impose on a
+// ConstantDelayStrategy that does not set MaxDelay.
+const DefaultMaxRetryAfter = time.Minute
+
 // ConstantDelayStrategy implements a constant delay between retries
 type ConstantDelayStrategy struct {
 	Delay             time.Duration
 	MaxAttempts       int
 	RetryableStatuses []int
+	// MaxDelay caps the wait requested by a Retry-After header; defaults to DefaultMaxRetryAfter
+	MaxDelay time.Duration
+	// Jitter randomizes each delay to spread out retries from many clients
+	Jitter JitterMode
+	// Rand returns a random number in [0, 1) for jitter; defaults to a per-strategy source
+	Rand func() float64
+
+	rng *jitterRand
 }
 
 // NewConstantDelayStrategy creates a retry strategy with constant delay
@@ -29,12 +98,24 @@ func NewConstantDelayStrategy(delay time.Duration, maxAttempts int) *ConstantDel
 		Delay:             delay,
 		MaxAttempts:       maxAttempts,
 		RetryableStatuses: RetryableStatusCodes(),
+		rng:               newJitterRand(),
 	}
 }
 
-// NextDelay returns a constant delay regardless of attempt, response or error
-func (s *ConstantDelayStrategy) NextDelay(_ int, _ *http.Response, _ error) time.Duration {
-	return s.Delay
+// NextDelay returns the wait requested by a Retry-After header, capped at MaxDelay, or else a
+// constant delay randomized by Jitter
+func (s *ConstantDelayStrategy) NextDelay(_ int, resp *http.Response, _ error) time.Duration {
+	if wait, ok := parseRetryAfter(resp); ok {
+		maxDelay := s.MaxDelay
+		if maxDelay <= 0 {
+			maxDelay = DefaultMaxRetryAfter
+		}
+		if wait > maxDelay {
+			wait = maxDelay
+		}
+		return wait
+	}
+	return applyJitter(s.Delay, s.Jitter, randomSource(s.Rand, s.rng))
 }
 
 func (s *ConstantDelayStrategy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
@@ -64,6 +145,14 @@ func (s *ConstantDelayStrategy) ShouldRetry(attempt int, resp *http.Response, er
 	return false
 }
 
+// CloneOptions returns a copy of the strategy that shares no slices with s, as reported by
+// GetIdentifier of the retry middleware.
+func (s *ConstantDelayStrategy) CloneOptions() interface{} {
+	clone := *s
+	clone.RetryableStatuses = append([]int(nil), s.RetryableStatuses...)
+	return &clone
+}
+
 // ExponentialBackoffStrategy implements exponential backoff
 type ExponentialBackoffStrategy struct {
 	InitialDelay      time.Duration
@@ -71,6 +160,16 @@ type ExponentialBackoffStrategy struct {
 	Factor            float64
 	MaxAttempts       int
 	RetryableStatuses []int
+	// JitterFraction randomly shortens each delay by up to this fraction (0 to 1) to spread
+	// out retries from many clients. Zero disables jitter. It cannot be combined with Jitter.
+	JitterFraction float64
+	// Jitter randomizes each capped delay with full or equal jitter. It cannot be combined
+	// with JitterFraction.
+	Jitter JitterMode
+	// Rand returns a random number in [0, 1) for jitter; defaults to a per-strategy source
+	Rand func() float64
+
+	rng *jitterRand
 }
 
 // NewExponentialBackoffStrategy creates a retry strategy with exponential backoff
@@ -81,20 +180,60 @@ func NewExponentialBackoffStrategy(initialDelay, maxDelay time.Duration, factor
 		Factor:            factor,
 		MaxAttempts:       maxAttempts,
 		RetryableStatuses: RetryableStatusCodes(),
+		rng:               newJitterRand(),
 	}
 }
 
-// NextDelay calculates the next delay using exponential backoff
-func (s *ExponentialBackoffStrategy) NextDelay(attempt int, _ *http.Response, _ error) time.Duration {
-	// Calculate exponential delay
-	delay := s.InitialDelay * time.Duration(float64(int64(1)<<uint(attempt))*s.Factor)
+// NewExponentialBackoffStrategyWithJitter creates a retry strategy with exponential backoff
+// whose delays are randomized with the given jitter mode
+func NewExponentialBackoffStrategyWithJitter(initialDelay, maxDelay time.Duration, factor float64, maxAttempts int, jitter JitterMode) *ExponentialBackoffStrategy {
+	strategy := NewExponentialBackoffStrategy(initialDelay, maxDelay, factor, maxAttempts)
+	strategy.Jitter = jitter
+	return strategy
+}
+
+// NextDelay calculates the next delay as InitialDelay * Factor^(attempt-1), unless a Retry-After
+// header requests a specific wait; either is capped at MaxDelay
+func (s *ExponentialBackoffStrategy) NextDelay(attempt int, resp *http.Response, _ error) time.Duration {
+	if wait, ok := parseRetryAfter(resp); ok {
+		if wait > s.MaxDelay {
+			wait = s.MaxDelay
+		}
+		return wait
+	}
+
+	// The retry middleware numbers the first retry 1, which waits InitialDelay
+	if attempt < 1 {
+		attempt = 1
+	}
+	scaled := float64(s.InitialDelay) * math.Pow(s.Factor, float64(attempt-1))
+
+	// Cap at max delay before converting, so large attempts cannot overflow time.Duration
+	delay := s.MaxDelay
+	if scaled < float64(s.MaxDelay) {
+		delay = time.Duration(scaled)
+	}
 
-	// Cap at max delay
-	if delay > s.MaxDelay {
-		delay = s.MaxDelay
+	return s.jitter(delay)
+}
+
+// jitter applies the Jitter mode, or else scales delay to a random value in
+// [delay*(1-JitterFraction), delay], so a capped delay never exceeds MaxDelay. RetryMiddleware
+// rejects strategies that set both.
+func (s *ExponentialBackoffStrategy) jitter(delay time.Duration) time.Duration {
+	random := randomSource(s.Rand, s.rng)
+	if s.Jitter != JitterNone {
+		return applyJitter(delay, s.Jitter, random)
 	}
 
-	return delay
+	fraction := s.JitterFraction
+	if fraction <= 0 {
+		return delay
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return delay - time.Duration(float64(delay)*fraction*random())
 }
 
 func (s *ExponentialBackoffStrategy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
@@ -132,6 +271,168 @@ func (s *ExponentialBackoffStrategy) ShouldRetry(attempt int, resp *http.Respons
 	return false
 }
 
+// CloneOptions returns a copy of the strategy that shares no slices with s, as reported by
+// GetIdentifier of the retry middleware.
+func (s *ExponentialBackoffStrategy) CloneOptions() interface{} {
+	clone := *s
+	clone.RetryableStatuses = append([]int(nil), s.RetryableStatuses...)
+	return &clone
+}
+
+// maxRetryAfterSeconds keeps a delta-seconds Retry-After within time.Duration
+const maxRetryAfterSeconds = int64(math.MaxInt64 / time.Second)
+
+// parseRetryAfter reports how long resp asks the client to wait before retrying, from a
+// Retry-After header holding either delta-seconds or an HTTP-date. Dates in the past yield zero.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > maxRetryAfterSeconds {
+			seconds = maxRetryAfterSeconds
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// RateLimitResetStrategy waits for the rate-limit window to reset before retrying.
+// A 403 or 429 response carrying X-RateLimit-Remaining: 0 and a parseable X-RateLimit-Reset
+// is retried once the reset time has passed; everything else is delegated to Fallback.
+type RateLimitResetStrategy struct {
+	Fallback RetryStrategy
+	// MaxWait caps how long the strategy is willing to wait for a reset; a response whose
+	// reset lies further away is not retried. Zero means no cap.
+	MaxWait time.Duration
+}
+
+// NewRateLimitResetStrategy creates a strategy that honours X-RateLimit-Reset and falls back
+// to the given strategy for all other retry decisions.
+func NewRateLimitResetStrategy(fallback RetryStrategy, maxWait time.Duration) *RateLimitResetStrategy {
+	return &RateLimitResetStrategy{
+		Fallback: fallback,
+		MaxWait:  maxWait,
+	}
+}
+
+// NextDelay returns the time until the rate-limit reset, or the fallback delay
+func (s *RateLimitResetStrategy) NextDelay(attempt int, resp *http.Response, err error) time.Duration {
+	if wait, ok := s.resetWait(resp); ok {
+		return wait
+	}
+	return s.Fallback.NextDelay(attempt, resp, err)
+}
+
+func (s *RateLimitResetStrategy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
+	if wait, ok := s.resetWait(resp); ok {
+		if attempt >= maxAttempts(s.Fallback) {
+			return false
+		}
+		return s.MaxWait <= 0 || wait <= s.MaxWait
+	}
+	return s.Fallback.ShouldRetry(attempt, resp, err)
+}
+
+// CloneOptions returns a copy of the strategy whose fallback is cloned too when it supports it.
+func (s *RateLimitResetStrategy) CloneOptions() interface{} {
+	clone := *s
+	if cloner, ok := s.Fallback.(OptionsCloner); ok {
+		if fallback, ok := cloner.CloneOptions().(RetryStrategy); ok {
+			clone.Fallback = fallback
+		}
+	}
+	return &clone
+}
+
+func (s *RateLimitResetStrategy) resetWait(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	return RateLimitResetDelay(resp.Header, time.Now())
+}
+
+// epochThreshold separates delta-seconds from epoch-seconds reset values.
+// Any value above it (roughly one year) is treated as a Unix timestamp.
+const epochThreshold = 365 * 24 * 60 * 60
+
+// RateLimitResetDelay reports how long to wait, relative to now, for the rate limit described
+// by header to reset. It returns false unless X-RateLimit-Remaining is 0 and X-RateLimit-Reset
+// holds either a Unix timestamp in seconds or a number of seconds to wait.
+func RateLimitResetDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if strings.TrimSpace(header.Get("X-RateLimit-Remaining")) != "0" {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseFloat(strings.TrimSpace(header.Get("X-RateLimit-Reset")), 64)
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if reset > epochThreshold {
+		sec := int64(reset)
+		nsec := int64((reset - float64(sec)) * float64(time.Second))
+		wait = time.Unix(sec, nsec).Sub(now)
+	} else {
+		wait = time.Duration(reset * float64(time.Second))
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// defaultMaxAttempts bounds body-based retries for strategies that do not expose MaxAttempts
+const defaultMaxAttempts = 3
+
+// maxAttempts returns the attempt limit of the built-in strategies
+func maxAttempts(strategy RetryStrategy) int {
+	switch s := strategy.(type) {
+	case *ConstantDelayStrategy:
+		return s.MaxAttempts
+	case *ExponentialBackoffStrategy:
+		return s.MaxAttempts
+	case *RateLimitResetStrategy:
+		return maxAttempts(s.Fallback)
+	default:
+		return defaultMaxAttempts
+	}
+}
+
+// validateStrategy panics on a strategy, or the fallback of a RateLimitResetStrategy, that sets
+// both jitter mechanisms of ExponentialBackoffStrategy, since only one of them could apply.
+func validateStrategy(strategy RetryStrategy) {
+	switch s := strategy.(type) {
+	case *ExponentialBackoffStrategy:
+		if s.Jitter != JitterNone && s.JitterFraction > 0 {
+			panic("ExponentialBackoffStrategy must set either Jitter or JitterFraction, not both")
+		}
+	case *RateLimitResetStrategy:
+		validateStrategy(s.Fallback)
+	}
+}
+
 // RetryableStatusCodes returns the default list of status codes to retry
 func RetryableStatusCodes() []int {
 	return []int{408, 429, 500, 502, 503, 504}
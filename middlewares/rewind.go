@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// errBodyRewound is returned to an earlier attempt that still reads the request body after
+// it was rewound for a retry.
+var errBodyRewound = errors.New("request body was rewound for a retry")
+
+// rewindableBody replays a seekable request body across retry attempts by seeking back to
+// where it started instead of buffering it. Only a Body that itself implements
+// io.ReadSeekCloser, such as an *os.File passed to http.NewRequest, can be rewound; a reader
+// hidden behind io.NopCloser cannot, so it is buffered. Bodies from core.Request.WithBody, or
+// from http.NewRequest with a bytes or strings reader, come with GetBody and need neither.
+//
+// Each attempt reads through its own handle; once the body is rewound, handles of earlier
+// attempts fail, so a transport still writing a previous attempt cannot consume data meant for
+// the next one. The original body is closed once the retry loop is done and the final attempt
+// has closed its handle.
+type rewindableBody struct {
+	body   io.ReadSeekCloser
+	offset int64
+
+	mu       sync.Mutex
+	current  *rewindHandle
+	released bool
+	closed   bool
+}
+
+// newRewindableBody records the current offset of body, reporting false when body cannot seek.
+func newRewindableBody(body io.ReadCloser) (*rewindableBody, bool) {
+	seeker, ok := body.(io.ReadSeekCloser)
+	if !ok {
+		return nil, false
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, false
+	}
+	return &rewindableBody{body: seeker, offset: offset}, true
+}
+
+// attempt returns the body for the next attempt, rewinding it unless it is the first.
+func (b *rewindableBody) attempt() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.current != nil {
+		if _, err := b.body.Seek(b.offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	b.current = &rewindHandle{parent: b}
+	return b.current, nil
+}
+
+// release ends the retry loop, closing the original body if the final attempt is done with it.
+func (b *rewindableBody) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.released = true
+	if b.current == nil || b.current.closed {
+		b.closeBody()
+	}
+}
+
+// closeBody closes the original body once; the caller holds mu.
+func (b *rewindableBody) closeBody() {
+	if !b.closed {
+		b.closed = true
+		_ = b.body.Close()
+	}
+}
+
+// rewindHandle is the request body of a single attempt.
+type rewindHandle struct {
+	parent *rewindableBody
+	closed bool
+}
+
+func (h *rewindHandle) Read(p []byte) (int, error) {
+	h.parent.mu.Lock()
+	defer h.parent.mu.Unlock()
+
+	if h.parent.current != h {
+		return 0, errBodyRewound
+	}
+	return h.parent.body.Read(p)
+}
+
+func (h *rewindHandle) Close() error {
+	h.parent.mu.Lock()
+	defer h.parent.mu.Unlock()
+
+	h.closed = true
+	if h.parent.released && h.parent.current == h {
+		h.parent.closeBody()
+	}
+	return nil
+}
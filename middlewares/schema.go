@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SchemaViolation is a single failure reported by a SchemaValidator
+type SchemaViolation struct {
+	// Path locates the offending value within the document, e.g. "items.0.id"; empty for the root
+	Path    string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// SchemaValidator checks a JSON document against a schema. It is usually a thin adapter over a
+// JSON Schema library, keeping that library out of this module's dependencies. Validate is only
+// called with well-formed JSON and returns no violations when the document conforms.
+type SchemaValidator interface {
+	Validate(document []byte) []SchemaViolation
+}
+
+// SchemaValidatorFunc adapts a function to the SchemaValidator interface
+type SchemaValidatorFunc func(document []byte) []SchemaViolation
+
+// Validate calls f(document).
+func (f SchemaValidatorFunc) Validate(document []byte) []SchemaViolation {
+	return f(document)
+}
+
+// SchemaValidationError lists every way a response body failed to match its schema
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	failures := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		failures[i] = violation.String()
+	}
+	return fmt.Sprintf("response body does not match schema: %s", strings.Join(failures, "; "))
+}
+
+// RequireJSONSchema returns a validator for ResponseValidationMiddleware that checks JSON
+// response bodies against validator, failing with a SchemaValidationError. The body is buffered
+// for validation and restored so callers can still read it; malformed JSON is reported as a
+// violation. Only 2xx responses with a non-empty body are checked, so error bodies, 204 No
+// Content and HEAD responses pass. Responses whose Content-Type is set to something other than
+// JSON, and responses to streaming requests, which are never buffered, are not checked either.
+func RequireJSONSchema(validator SchemaValidator) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if validator == nil || !schemaApplies(resp) {
+			return nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to read response body for schema validation: %w", err)
+		}
+		if len(body) == 0 {
+			return nil
+		}
+
+		var document json.RawMessage
+		if err := json.Unmarshal(body, &document); err != nil {
+			return &SchemaValidationError{
+				Violations: []SchemaViolation{{Message: fmt.Sprintf("invalid JSON: %v", err)}},
+			}
+		}
+		if violations := validator.Validate(body); len(violations) > 0 {
+			return &SchemaValidationError{Violations: violations}
+		}
+		return nil
+	}
+}
+
+// schemaApplies reports whether resp is a successful JSON response that may carry a body
+// and was not requested as a stream.
+func schemaApplies(resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || resp.StatusCode == http.StatusNoContent {
+		return false
+	}
+	if req := resp.Request; req != nil && (req.Method == http.MethodHead || IsStreamingRequest(req.Context())) {
+		return false
+	}
+	return jsonContentType(resp.Header.Get("Content-Type"))
+}
+
+// jsonContentType reports whether contentType is empty or names a JSON media type,
+// including structured syntax suffixes such as application/problem+json.
+func jsonContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
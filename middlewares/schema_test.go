@@ -0,0 +1,148 @@
+package middlewares_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequireJSONSchema", func() {
+	var (
+		contentType string
+		payload     string
+		status      int
+		validated   []string
+		validator   middlewares.SchemaValidatorFunc
+		dummy       core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		contentType = "application/json"
+		status = http.StatusOK
+		validated = nil
+		// The stub accepts documents carrying an "id" field
+		validator = func(document []byte) []middlewares.SchemaViolation {
+			validated = append(validated, string(document))
+			if strings.Contains(string(document), `"id"`) {
+				return nil
+			}
+			return []middlewares.SchemaViolation{
+				{Message: "missing property id"},
+				{Path: "name", Message: "expected string"},
+			}
+		}
+		dummy = func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {contentType}},
+				Body:       io.NopCloser(strings.NewReader(payload)),
+				Request:    req,
+			}, nil
+		}
+	})
+
+	roundTripRequest := func(req *http.Request) (*http.Response, error) {
+		mw := middlewares.ResponseValidationMiddleware(middlewares.RequireJSONSchema(validator))
+		return mw.Wrap(dummy)(req)
+	}
+
+	roundTrip := func() (*http.Response, error) {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		return roundTripRequest(req)
+	}
+
+	It("should pass a conforming body and restore it for the caller", func() {
+		payload = `{"id": 1}`
+
+		resp, err := roundTrip()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validated).To(Equal([]string{payload}))
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(payload))
+	})
+
+	It("should list every violation of a non-conforming body", func() {
+		payload = `{"name": 3}`
+
+		resp, err := roundTrip()
+		Expect(resp).To(BeNil())
+
+		var schemaErr *middlewares.SchemaValidationError
+		Expect(errors.As(err, &schemaErr)).To(BeTrue())
+		Expect(schemaErr.Violations).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring("missing property id; name: expected string"))
+
+		var validationErr *middlewares.ResponseValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(validationErr.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should report malformed JSON without calling the validator", func() {
+		payload = `{"id": `
+
+		_, err := roundTrip()
+		var schemaErr *middlewares.SchemaValidationError
+		Expect(errors.As(err, &schemaErr)).To(BeTrue())
+		Expect(schemaErr.Violations[0].Message).To(HavePrefix("invalid JSON"))
+		Expect(validated).To(BeEmpty())
+	})
+
+	It("should skip responses that are not JSON", func() {
+		contentType = "text/plain; charset=utf-8"
+		payload = "not json"
+
+		_, err := roundTrip()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validated).To(BeEmpty())
+
+		contentType = "application/problem+json"
+		payload = `{"title": "oops"}`
+		_, err = roundTrip()
+		Expect(err).To(HaveOccurred())
+		Expect(validated).To(HaveLen(1))
+	})
+	It("should skip empty bodies, including those without a Content-Type", func() {
+		contentType = ""
+		payload = ""
+
+		_, err := roundTrip()
+		Expect(err).NotTo(HaveOccurred())
+
+		status = http.StatusNoContent
+		_, err = roundTrip()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validated).To(BeEmpty())
+	})
+
+	It("should not validate error responses against the success schema", func() {
+		status = http.StatusInternalServerError
+		payload = `{"error": "boom"}`
+
+		resp, err := roundTrip()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(validated).To(BeEmpty())
+	})
+
+	It("should skip HEAD and streaming requests", func() {
+		payload = `{"name": "no id"}`
+
+		head, _ := http.NewRequest("HEAD", "http://example.com", nil)
+		_, err := roundTripRequest(head)
+		Expect(err).NotTo(HaveOccurred())
+
+		stream, _ := http.NewRequest("GET", "http://example.com", nil)
+		stream = stream.WithContext(middlewares.MarkStreamingRequest(stream.Context()))
+		_, err = roundTripRequest(stream)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validated).To(BeEmpty())
+	})
+})
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// SingleflightMiddleware creates a middleware that coalesces concurrent identical GET and HEAD
+// requests into a single round trip whose response is shared, avoiding stampedes on the same
+// resource. Requests are identical when they share a method and keyFunc returns the same key;
+// a nil keyFunc uses the full URL and the Authorization and Cookie headers, so callers with
+// different credentials never share a response. The shared response is buffered, so every
+// caller can read it concurrently. Other methods pass through untouched.
+// A caller whose context ends stops waiting, but the shared round trip runs with the context
+// of the first caller, so its cancellation fails every caller waiting on it.
+func SingleflightMiddleware(keyFunc func(*http.Request) string) ConfigurableMiddleware {
+	if keyFunc == nil {
+		keyFunc = singleflightKey
+	}
+	var group singleflight.Group
+
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(req)
+			}
+
+			ch := group.DoChan(req.Method+" "+keyFunc(req), func() (interface{}, error) {
+				resp, err := next(req)
+				if err != nil {
+					return nil, err
+				}
+				return newBufferedResponse(resp)
+			})
+
+			select {
+			case result := <-ch:
+				if result.Err != nil {
+					return nil, result.Err
+				}
+				return result.Val.(*bufferedResponse).response(req), nil
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+	}
+
+	return CreateMiddleware("singleflight", nil, wrapper)
+}
+
+// singleflightKey identifies a request by its URL and the headers carrying its credentials.
+func singleflightKey(req *http.Request) string {
+	return strings.Join([]string{
+		req.URL.String(),
+		strings.Join(req.Header.Values("Authorization"), "\n"),
+		strings.Join(req.Header.Values("Cookie"), "; "),
+	}, "\x00")
+}
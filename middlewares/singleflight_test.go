@@ -0,0 +1,162 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SingleflightMiddleware", func() {
+	var (
+		calls   atomic.Int32
+		release chan struct{}
+	)
+
+	blocking := func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Path": {req.URL.Path}},
+			Body:       io.NopCloser(strings.NewReader("shared body")),
+		}, nil
+	}
+
+	// fire sends n concurrent requests and returns once every one has completed
+	fire := func(rt func(*http.Request) (*http.Response, error), method string, n int) ([]*http.Response, []error) {
+		resps := make([]*http.Response, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req, _ := http.NewRequest(method, "http://example.com/items", nil)
+				resps[i], errs[i] = rt(req)
+			}(i)
+		}
+		// Let every request join the flight before the transport answers
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		return resps, errs
+	}
+
+	BeforeEach(func() {
+		calls.Store(0)
+		release = make(chan struct{})
+	})
+
+	It("should coalesce concurrent GETs into one round trip with independent bodies", func() {
+		rt := middlewares.SingleflightMiddleware(nil).Wrap(blocking)
+
+		resps, errs := fire(rt, "GET", 10)
+
+		Expect(calls.Load()).To(Equal(int32(1)))
+		var wg sync.WaitGroup
+		for i, resp := range resps {
+			Expect(errs[i]).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("X-Path")).To(Equal("/items"))
+			wg.Add(1)
+			go func(resp *http.Response) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				body, err := io.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(body)).To(Equal("shared body"))
+				Expect(resp.Body.Close()).To(Succeed())
+			}(resp)
+		}
+		wg.Wait()
+
+		resps[0].Header.Set("X-Path", "changed")
+		Expect(resps[1].Header.Get("X-Path")).To(Equal("/items"))
+	})
+
+	It("should pass non-idempotent methods through", func() {
+		rt := middlewares.SingleflightMiddleware(nil).Wrap(blocking)
+
+		_, errs := fire(rt, "POST", 3)
+
+		Expect(calls.Load()).To(Equal(int32(3)))
+		for _, err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should use keyFunc to group requests", func() {
+		rt := middlewares.SingleflightMiddleware(func(req *http.Request) string {
+			return req.URL.Path
+		}).Wrap(blocking)
+
+		var wg sync.WaitGroup
+		for _, url := range []string{"http://a.example.com/items", "http://b.example.com/items"} {
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", url, nil)
+				_, _ = rt(req)
+			}(url)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("should not share a response between callers with different credentials", func() {
+		rt := middlewares.SingleflightMiddleware(nil).Wrap(blocking)
+
+		var wg sync.WaitGroup
+		for _, auth := range []string{"Bearer alice", "Bearer bob", "Bearer alice"} {
+			wg.Add(1)
+			go func(auth string) {
+				defer wg.Done()
+				req, _ := http.NewRequest("GET", "http://example.com/items", nil)
+				req.Header.Set("Authorization", auth)
+				_, _ = rt(req)
+			}(auth)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		Expect(calls.Load()).To(Equal(int32(2)))
+	})
+
+	It("should share errors and let a waiting caller give up on its own context", func() {
+		failing := func(req *http.Request) (*http.Response, error) {
+			<-release
+			return nil, errors.New("upstream down")
+		}
+		rt := middlewares.SingleflightMiddleware(nil).Wrap(failing)
+
+		leaderErr := make(chan error, 1)
+		go func() {
+			req, _ := http.NewRequest("GET", "http://example.com/items", nil)
+			_, err := rt(req)
+			leaderErr <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/items", nil)
+		_, err := rt(req)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		close(release)
+		Expect(<-leaderErr).To(MatchError("upstream down"))
+	})
+})
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"context"
 	"fmt"
 	"github.com/jzx17/gofetch/core"
 	"io"
@@ -64,6 +65,51 @@ func (r *sizeLimitedReader) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
+// sizeLimitedReadCloser enforces a size limit on a body while still closing the original
+type sizeLimitedReadCloser struct {
+	*sizeLimitedReader
+	closer io.Closer
+}
+
+func (r *sizeLimitedReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// NewLimitedBody wraps body so that reading more than maxSize bytes fails with a SizeError
+// of the given type ("request", "response", or "stream"). A maxSize of zero or less disables
+// the limit. Closing the returned body closes the original.
+func NewLimitedBody(body io.ReadCloser, maxSize int64, sizeType string) io.ReadCloser {
+	return &sizeLimitedReadCloser{
+		sizeLimitedReader: newSizeLimitedReader(body, maxSize, sizeType),
+		closer:            body,
+	}
+}
+
+type streamingContextKey struct{}
+
+// MarkStreamingRequest flags ctx as belonging to a streamed call, so the size middleware
+// enforces MaxStreamSize rather than MaxResponseBodySize on the response body.
+func MarkStreamingRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingContextKey{}, true)
+}
+
+// IsStreamingRequest reports whether ctx was flagged by MarkStreamingRequest.
+func IsStreamingRequest(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamingContextKey{}).(bool)
+	return streaming
+}
+
+// responseLimit returns the body limit and error type that apply to the response of req.
+func responseLimit(req *http.Request, config core.SizeConfig) (int64, string) {
+	if IsStreamingRequest(req.Context()) && config.MaxStreamSize > 0 {
+		return config.MaxStreamSize, "stream"
+	}
+	if config.MaxResponseBodySize > 0 {
+		return config.MaxResponseBodySize, "response"
+	}
+	return config.MaxStreamSize, "response"
+}
+
 // SizeValidationMiddleware creates a middleware that validates request and response sizes
 func SizeValidationMiddleware(config core.SizeConfig) ConfigurableMiddleware {
 	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
@@ -78,7 +124,7 @@ func SizeValidationMiddleware(config core.SizeConfig) ConfigurableMiddleware {
 						Current: req.ContentLength,
 					}
 				}
-				req.Body = io.NopCloser(newSizeLimitedReader(req.Body, config.MaxRequestBodySize, "request"))
+				req.Body = NewLimitedBody(req.Body, config.MaxRequestBodySize, "request")
 			}
 
 			// Make the actual request
@@ -87,24 +133,19 @@ func SizeValidationMiddleware(config core.SizeConfig) ConfigurableMiddleware {
 				return nil, err
 			}
 
-			// Check response body size if limit is set
-			if resp.Body != nil && (config.MaxResponseBodySize > 0 || config.MaxStreamSize > 0) {
-				maxSize := config.MaxResponseBodySize
-				if maxSize == 0 {
-					maxSize = config.MaxStreamSize
-				}
-
+			// Check response body size if limit is set; streamed calls use MaxStreamSize
+			if maxSize, sizeType := responseLimit(req, config); resp.Body != nil && maxSize > 0 {
 				// Check content length directly if available
 				if resp.ContentLength > 0 && resp.ContentLength > maxSize {
 					_ = resp.Body.Close() // Prevent resource leak
 					return nil, &SizeError{
-						Type:    "response",
+						Type:    sizeType,
 						Max:     maxSize,
 						Current: resp.ContentLength,
 					}
 				}
 
-				resp.Body = io.NopCloser(newSizeLimitedReader(resp.Body, maxSize, "response"))
+				resp.Body = NewLimitedBody(resp.Body, maxSize, sizeType)
 			}
 
 			return resp, nil
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// SLOOptions is the identifier payload of the SLO middleware
+type SLOOptions struct {
+	Threshold time.Duration
+}
+
+// SLOMiddleware creates a middleware that times each round trip, up to the arrival of the
+// response headers, and calls onViolation when it takes longer than threshold. Failed round
+// trips are timed too. The request and response are passed through unchanged, and
+// onViolation runs synchronously, so it should return quickly.
+func SLOMiddleware(threshold time.Duration, onViolation func(req *http.Request, duration time.Duration)) ConfigurableMiddleware {
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			if duration := time.Since(start); duration > threshold && onViolation != nil {
+				onViolation(req, duration)
+			}
+			return resp, err
+		}
+	}
+
+	return CreateMiddleware("slo", SLOOptions{Threshold: threshold}, wrapper)
+}
@@ -0,0 +1,74 @@
+package middlewares_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SLOMiddleware", func() {
+	var (
+		violations []time.Duration
+		violated   []*http.Request
+		record     func(*http.Request, time.Duration)
+	)
+
+	transportTaking := func(d time.Duration, err error) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			time.Sleep(d)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}
+	}
+
+	BeforeEach(func() {
+		violations = nil
+		violated = nil
+		record = func(req *http.Request, d time.Duration) {
+			violated = append(violated, req)
+			violations = append(violations, d)
+		}
+	})
+
+	It("should report a request slower than the threshold", func() {
+		mw := middlewares.SLOMiddleware(20*time.Millisecond, record)
+		req, _ := http.NewRequest("GET", "http://example.com/slow", nil)
+
+		resp, err := mw.Wrap(transportTaking(50*time.Millisecond, nil))(req)
+		Expect(err).NotTo(HaveOccurred())
+		body, _ := io.ReadAll(resp.Body)
+		Expect(string(body)).To(Equal("ok"))
+
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0]).To(BeNumerically(">=", 50*time.Millisecond))
+		Expect(violated[0]).To(BeIdenticalTo(req))
+	})
+
+	It("should not report a fast request", func() {
+		mw := middlewares.SLOMiddleware(time.Second, record)
+		req, _ := http.NewRequest("GET", "http://example.com/fast", nil)
+
+		_, err := mw.Wrap(transportTaking(0, nil))(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+
+	It("should time failed round trips and pass the error through", func() {
+		mw := middlewares.SLOMiddleware(10*time.Millisecond, record)
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		_, err := mw.Wrap(transportTaking(30*time.Millisecond, errors.New("reset")))(req)
+		Expect(err).To(MatchError("reset"))
+		Expect(violations).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// StatusRewriteFunc returns the status code a response should carry. body holds the buffered
+// response body, or is nil when it was not inspected. Returning 0 or the current code leaves
+// the response unchanged.
+type StatusRewriteFunc func(resp *http.Response, body []byte) int
+
+// StatusRewriteOptions configures the status rewrite middleware
+type StatusRewriteOptions struct {
+	// Rewrite decides the new status code
+	Rewrite StatusRewriteFunc
+	// MaxInspectBody caps how many body bytes are buffered for Rewrite (0 = unlimited).
+	// Larger bodies, and the bodies of streaming requests, are passed to Rewrite as nil.
+	MaxInspectBody int64
+}
+
+// DefaultStatusRewriteMaxInspectBody is the body inspection cap used by WithResponseStatusRewrite
+const DefaultStatusRewriteMaxInspectBody = 1 << 20
+
+// StatusRewriteMiddleware creates a middleware that rewrites response status codes for
+// upstreams with quirky semantics, e.g. mapping a vendor's 299 to 200, or turning a 200 with
+// an error body into a 502. Middlewares wrapping it, such as retry, see the rewritten status;
+// place it last in the chain so it applies to every attempt.
+func StatusRewriteMiddleware(options StatusRewriteOptions) ConfigurableMiddleware {
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || resp == nil || options.Rewrite == nil {
+				return resp, err
+			}
+
+			var body []byte
+			if !IsStreamingRequest(req.Context()) {
+				buffered, ok, bufErr := bufferResponseBody(resp, options.MaxInspectBody)
+				if bufErr != nil {
+					return nil, bufErr
+				}
+				if ok {
+					body = buffered
+				}
+			}
+
+			if code := options.Rewrite(resp, body); code != 0 && code != resp.StatusCode {
+				resp.StatusCode = code
+				resp.Status = fmt.Sprintf("%d %s", code, http.StatusText(code))
+			}
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("status-rewrite", options, wrapper)
+}
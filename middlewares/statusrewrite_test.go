@@ -0,0 +1,95 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StatusRewriteMiddleware", func() {
+	transportReturning := func(code int, body string) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: code,
+				Status:     http.StatusText(code),
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}
+	}
+
+	vendorRewrite := func(resp *http.Response, body []byte) int {
+		if resp.StatusCode == 299 {
+			return http.StatusOK
+		}
+		if bytes.Contains(body, []byte(`"error"`)) {
+			return http.StatusBadGateway
+		}
+		return 0
+	}
+
+	get := func(rt core.RoundTripFunc, ctx context.Context) (*http.Response, string) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com", nil)
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(data)
+	}
+
+	It("should map a non-standard status code", func() {
+		mw := middlewares.StatusRewriteMiddleware(middlewares.StatusRewriteOptions{Rewrite: vendorRewrite})
+		resp, body := get(mw.Wrap(transportReturning(299, "fine")), context.Background())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Status).To(Equal("200 OK"))
+		Expect(body).To(Equal("fine"))
+	})
+
+	It("should rewrite a success with an error body and keep the body readable", func() {
+		mw := middlewares.StatusRewriteMiddleware(middlewares.StatusRewriteOptions{Rewrite: vendorRewrite})
+		resp, body := get(mw.Wrap(transportReturning(http.StatusOK, `{"error":"upstream down"}`)), context.Background())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+		Expect(body).To(Equal(`{"error":"upstream down"}`))
+	})
+
+	It("should pass nil for bodies over MaxInspectBody", func() {
+		var seen []byte
+		mw := middlewares.StatusRewriteMiddleware(middlewares.StatusRewriteOptions{
+			Rewrite: func(resp *http.Response, body []byte) int {
+				seen = body
+				return 0
+			},
+			MaxInspectBody: 4,
+		})
+		resp, body := get(mw.Wrap(transportReturning(http.StatusOK, `{"error":"too long"}`)), context.Background())
+
+		Expect(seen).To(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(Equal(`{"error":"too long"}`))
+	})
+
+	It("should not buffer the body of a streaming request", func() {
+		var seen []byte
+		mw := middlewares.StatusRewriteMiddleware(middlewares.StatusRewriteOptions{
+			Rewrite: func(resp *http.Response, body []byte) int {
+				seen = body
+				return 0
+			},
+		})
+		ctx := middlewares.MarkStreamingRequest(context.Background())
+		_, body := get(mw.Wrap(transportReturning(http.StatusOK, "stream")), ctx)
+
+		Expect(seen).To(BeNil())
+		Expect(body).To(Equal("stream"))
+	})
+})
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// TimeoutPhase identifies the stage of an HTTP exchange in which a timeout occurred.
+type TimeoutPhase string
+
+const (
+	// TimeoutPhaseDial covers DNS resolution and establishing the TCP connection
+	TimeoutPhaseDial TimeoutPhase = "dial"
+	// TimeoutPhaseTLSHandshake covers the TLS handshake
+	TimeoutPhaseTLSHandshake TimeoutPhase = "TLS handshake"
+	// TimeoutPhaseHeaders covers writing the request and waiting for response headers
+	TimeoutPhaseHeaders TimeoutPhase = "response headers"
+	// TimeoutPhaseBody covers reading the response body
+	TimeoutPhaseBody TimeoutPhase = "response body"
+)
+
+// TrackTimeoutPhase attaches an httptrace.ClientTrace to the request that records
+// the current phase of the exchange. It returns the instrumented request and a
+// function reporting the phase that was last entered. Existing traces on the
+// request context are preserved.
+func TrackTimeoutPhase(req *http.Request) (*http.Request, func() TimeoutPhase) {
+	var current atomic.Value
+	current.Store(TimeoutPhase(""))
+	set := func(p TimeoutPhase) { current.Store(p) }
+
+	trace := &httptrace.ClientTrace{
+		GetConn:           func(string) { set(TimeoutPhaseDial) },
+		TLSHandshakeStart: func() { set(TimeoutPhaseTLSHandshake) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(TimeoutPhaseHeaders) },
+		GotConn:           func(httptrace.GotConnInfo) { set(TimeoutPhaseHeaders) },
+	}
+
+	ctx := httptrace.WithClientTrace(req.Context(), trace)
+	return req.WithContext(ctx), func() TimeoutPhase {
+		return current.Load().(TimeoutPhase)
+	}
+}
@@ -0,0 +1,353 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// Transcript is a structured record of a single request/response exchange
+type Transcript struct {
+	Method               string
+	URL                  string
+	RequestHeader        http.Header
+	RequestBody          []byte
+	RequestBodyTruncated bool
+
+	StatusCode            int
+	Status                string
+	ResponseHeader        http.Header
+	ResponseBody          []byte
+	ResponseBodyTruncated bool
+	// ResponseBodyErr is the error, other than io.EOF, hit while the response body was read
+	ResponseBodyErr error
+
+	Duration time.Duration
+	Err      error
+}
+
+// String renders the transcript as a human-readable dump, with request lines prefixed by
+// "> " and response lines prefixed by "< ".
+func (t *Transcript) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "> %s %s\n", t.Method, t.URL)
+	writeTranscriptHeaders(&b, "> ", t.RequestHeader)
+	writeTranscriptBody(&b, "> ", t.RequestBody, t.RequestBodyTruncated)
+
+	if t.Err != nil {
+		_, _ = fmt.Fprintf(&b, "! error: %v (%s)\n", t.Err, t.Duration)
+		return b.String()
+	}
+
+	_, _ = fmt.Fprintf(&b, "< %s (%s)\n", t.Status, t.Duration)
+	writeTranscriptHeaders(&b, "< ", t.ResponseHeader)
+	writeTranscriptBody(&b, "< ", t.ResponseBody, t.ResponseBodyTruncated)
+	if t.ResponseBodyErr != nil {
+		_, _ = fmt.Fprintf(&b, "! body error: %v\n", t.ResponseBodyErr)
+	}
+	return b.String()
+}
+
+func writeTranscriptHeaders(b *strings.Builder, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			_, _ = fmt.Fprintf(b, "%s%s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+func writeTranscriptBody(b *strings.Builder, prefix string, body []byte, truncated bool) {
+	if len(body) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(b, "%s\n%s%s", prefix, prefix, body)
+	if truncated {
+		b.WriteString("... [truncated]")
+	}
+	b.WriteString("\n")
+}
+
+// TraceOptions configures the trace middleware
+type TraceOptions struct {
+	// Writer receives the text form of each transcript (optional)
+	Writer io.Writer
+	// OnTranscript is called with each completed transcript (optional)
+	OnTranscript func(*Transcript)
+	// MaxBodyLen is the maximum number of request and response body bytes captured
+	MaxBodyLen int
+	// HeadersToRedact are headers, and JSON or form body fields, whose values are replaced with [REDACTED]
+	HeadersToRedact []string
+}
+
+// DefaultTraceOptions returns default trace options
+func DefaultTraceOptions() TraceOptions {
+	return TraceOptions{
+		MaxBodyLen:      4096,
+		HeadersToRedact: []string{"Authorization", "Cookie", "Set-Cookie"},
+	}
+}
+
+// CloneOptions returns a copy of the options that shares no slices with o.
+func (o TraceOptions) CloneOptions() interface{} {
+	return o.clone()
+}
+
+func (o TraceOptions) clone() TraceOptions {
+	o.HeadersToRedact = append([]string(nil), o.HeadersToRedact...)
+	return o
+}
+
+// TraceMiddleware creates a middleware that records a full transcript of every exchange.
+// Unlike LoggingMiddleware it emits a single record per call, once the response body has been
+// captured, which makes it convenient for dumping requests in tests. Bodies are captured up to
+// MaxBodyLen as they are sent and read, so nothing is read ahead of the caller: the transcript
+// is emitted when the caller has read past MaxBodyLen, reached the end of the body or closed it.
+// Response bodies of streaming requests are not captured and their transcript is emitted as
+// soon as the headers arrive. JSON and form bodies have the values of fields named in
+// HeadersToRedact redacted, matched case-insensitively.
+func TraceMiddleware(options TraceOptions) ConfigurableMiddleware {
+	options = options.clone()
+	if options.MaxBodyLen < 0 {
+		options.MaxBodyLen = 0
+	}
+
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			t := &Transcript{
+				Method:        req.Method,
+				URL:           req.URL.String(),
+				RequestHeader: redactHeaders(req.Header, options.HeadersToRedact),
+			}
+			var requestBody *bodyCapture
+			if req.Body != nil && req.Body != http.NoBody && options.MaxBodyLen > 0 {
+				requestBody = &bodyCapture{limit: options.MaxBodyLen}
+				traced := req.WithContext(req.Context())
+				traced.Body = &capturingBody{ReadCloser: req.Body, capture: requestBody}
+				req = traced
+			}
+
+			start := time.Now()
+			resp, err := next(req)
+			t.Duration = time.Since(start)
+			if requestBody != nil {
+				data, truncated := requestBody.snapshot()
+				t.RequestBody = redactBody(data, req.Header.Get("Content-Type"), options.HeadersToRedact)
+				t.RequestBodyTruncated = truncated
+			}
+
+			if err != nil {
+				t.Err = err
+				emitTranscript(t, options)
+				return resp, err
+			}
+
+			t.StatusCode = resp.StatusCode
+			t.Status = resp.Status
+			t.ResponseHeader = redactHeaders(resp.Header, options.HeadersToRedact)
+			if resp.Body == nil || resp.Body == http.NoBody || options.MaxBodyLen == 0 || IsStreamingRequest(req.Context()) {
+				emitTranscript(t, options)
+				return resp, nil
+			}
+
+			responseBody := &bodyCapture{limit: options.MaxBodyLen}
+			contentType := resp.Header.Get("Content-Type")
+			resp.Body = &capturingBody{
+				ReadCloser: resp.Body,
+				capture:    responseBody,
+				onDone: func(readErr error) {
+					data, truncated := responseBody.snapshot()
+					t.ResponseBody = redactBody(data, contentType, options.HeadersToRedact)
+					t.ResponseBodyTruncated = truncated
+					t.ResponseBodyErr = readErr
+					emitTranscript(t, options)
+				},
+			}
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("trace", options, wrapper)
+}
+
+// bodyCapture keeps the first limit bytes that pass through a capturingBody
+type bodyCapture struct {
+	mu        sync.Mutex
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+// write keeps what still fits of p and reports whether the capture is complete because
+// more than limit bytes were seen.
+func (c *bodyCapture) write(p []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	room := c.limit - len(c.data)
+	if len(p) > room {
+		c.truncated = true
+		p = p[:room]
+	}
+	c.data = append(c.data, p...)
+	return c.truncated
+}
+
+// snapshot returns a copy of the captured bytes and whether the body was longer.
+func (c *bodyCapture) snapshot() ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]byte(nil), c.data...), c.truncated
+}
+
+// capturingBody copies what is read from the body into capture. onDone, if set, is called once
+// when capture is complete: after reading past the limit, at the end of the body, on a read
+// error, which it receives, or when the body is closed.
+type capturingBody struct {
+	io.ReadCloser
+	capture *bodyCapture
+	onDone  func(readErr error)
+	once    sync.Once
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	complete := b.capture.write(p[:n])
+	switch {
+	case err == io.EOF:
+		b.done(nil)
+	case err != nil:
+		b.done(err)
+	case complete:
+		b.done(nil)
+	}
+	return n, err
+}
+
+func (b *capturingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *capturingBody) done(readErr error) {
+	if b.onDone != nil {
+		b.once.Do(func() { b.onDone(readErr) })
+	}
+}
+
+// redactBody replaces the values of JSON object fields and form fields named in redact with
+// [REDACTED]. A JSON body that cannot be parsed, such as a truncated one, is replaced as a
+// whole if it mentions any of those names. Other content types are returned unchanged.
+func redactBody(body []byte, contentType string, redact []string) []byte {
+	if len(body) == 0 || len(redact) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			if mentionsAny(body, redact) {
+				return []byte("[REDACTED: unparsable JSON body]")
+			}
+			return body
+		}
+		if !redactJSONValue(value, redact) {
+			return body
+		}
+		if redacted, err := json.Marshal(value); err == nil {
+			return redacted
+		}
+		return []byte("[REDACTED: unparsable JSON body]")
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []byte("[REDACTED: unparsable form body]")
+		}
+		changed := false
+		for key := range form {
+			if isHeaderRedacted(key, redact) {
+				form[key] = []string{"[REDACTED]"}
+				changed = true
+			}
+		}
+		if !changed {
+			return body
+		}
+		return []byte(form.Encode())
+	default:
+		return body
+	}
+}
+
+// mentionsAny reports whether body contains any of names, ignoring case.
+func mentionsAny(body []byte, names []string) bool {
+	lower := strings.ToLower(string(body))
+	for _, name := range names {
+		if strings.Contains(lower, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSONValue redacts matching object fields at any depth, in place, and reports whether
+// any field was redacted.
+func redactJSONValue(value interface{}, redact []string) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isHeaderRedacted(key, redact) {
+				v[key] = "[REDACTED]"
+				changed = true
+			} else if redactJSONValue(field, redact) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactJSONValue(item, redact) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func redactHeaders(header http.Header, redact []string) http.Header {
+	out := header.Clone()
+	if out == nil {
+		out = http.Header{}
+	}
+	for key := range out {
+		if isHeaderRedacted(key, redact) {
+			out[key] = []string{"[REDACTED]"}
+		}
+	}
+	return out
+}
+
+func emitTranscript(t *Transcript, options TraceOptions) {
+	if options.Writer != nil {
+		_, _ = io.WriteString(options.Writer, t.String())
+	}
+	if options.OnTranscript != nil {
+		options.OnTranscript(t)
+	}
+}
@@ -0,0 +1,204 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+)
+
+var _ = Describe("Trace Middleware", func() {
+	var (
+		request     *http.Request
+		transcripts []*middlewares.Transcript
+		options     middlewares.TraceOptions
+	)
+
+	respondWith := func(body string) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Body != nil {
+				_, _ = io.ReadAll(req.Body)
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Status:     "201 Created",
+				Header: http.Header{
+					"Content-Type": {"application/json"},
+					"Set-Cookie":   {"session=abc"},
+				},
+				Body: io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}
+	}
+
+	BeforeEach(func() {
+		transcripts = nil
+		options = middlewares.DefaultTraceOptions()
+		options.OnTranscript = func(t *middlewares.Transcript) {
+			transcripts = append(transcripts, t)
+		}
+
+		var err error
+		request, err = http.NewRequest("POST", "https://example.com/items", bytes.NewBufferString(`{"name":"widget"}`))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer secret-token")
+		request.Header.Set("X-Request-Id", "42")
+	})
+
+	It("should record the request and response fields", func() {
+		rt := middlewares.TraceMiddleware(options).Wrap(respondWith(`{"id":1}`))
+
+		resp, err := rt(request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transcripts).To(BeEmpty())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"id":1}`))
+		Expect(transcripts).To(HaveLen(1))
+
+		t := transcripts[0]
+		Expect(t.Method).To(Equal("POST"))
+		Expect(t.URL).To(Equal("https://example.com/items"))
+		Expect(t.RequestHeader.Get("X-Request-Id")).To(Equal("42"))
+		Expect(t.RequestHeader.Get("Authorization")).To(Equal("[REDACTED]"))
+		Expect(string(t.RequestBody)).To(Equal(`{"name":"widget"}`))
+		Expect(t.StatusCode).To(Equal(http.StatusCreated))
+		Expect(t.ResponseHeader.Get("Content-Type")).To(Equal("application/json"))
+		Expect(t.ResponseHeader.Get("Set-Cookie")).To(Equal("[REDACTED]"))
+		Expect(string(t.ResponseBody)).To(Equal(`{"id":1}`))
+	})
+
+	It("should cap captured bodies without truncating the response", func() {
+		options.MaxBodyLen = 4
+		rt := middlewares.TraceMiddleware(options).Wrap(respondWith("0123456789"))
+
+		resp, err := rt(request)
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("0123456789"))
+
+		t := transcripts[0]
+		Expect(string(t.ResponseBody)).To(Equal("0123"))
+		Expect(t.ResponseBodyTruncated).To(BeTrue())
+		Expect(t.RequestBodyTruncated).To(BeTrue())
+	})
+
+	It("should write a text transcript to the writer", func() {
+		var buf bytes.Buffer
+		options.Writer = &buf
+		rt := middlewares.TraceMiddleware(options).Wrap(respondWith(`{"id":1}`))
+
+		resp, err := rt(request)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		out := buf.String()
+		Expect(out).To(ContainSubstring("> POST https://example.com/items"))
+		Expect(out).To(ContainSubstring("> Authorization: [REDACTED]"))
+		Expect(out).To(ContainSubstring(`> {"name":"widget"}`))
+		Expect(out).To(ContainSubstring("< 201 Created"))
+		Expect(out).To(ContainSubstring("< Content-Type: application/json"))
+		Expect(out).To(ContainSubstring(`< {"id":1}`))
+		Expect(out).NotTo(ContainSubstring("secret-token"))
+	})
+
+	It("should record transport errors", func() {
+		rt := middlewares.TraceMiddleware(options).Wrap(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		_, err := rt(request)
+		Expect(err).To(HaveOccurred())
+		Expect(transcripts).To(HaveLen(1))
+		Expect(transcripts[0].Err).To(MatchError("connection refused"))
+		Expect(transcripts[0].String()).To(ContainSubstring("! error: connection refused"))
+	})
+	It("should not read ahead of the caller on streamed responses", func() {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		rt := middlewares.TraceMiddleware(options).Wrap(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}, Body: pr}, nil
+		})
+
+		resp, err := rt(request)
+		Expect(err).NotTo(HaveOccurred())
+		go func() { _, _ = pw.Write([]byte("data: 1\n\n")) }()
+		chunk := make([]byte, len("data: 1\n\n"))
+		_, err = io.ReadFull(resp.Body, chunk)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transcripts).To(BeEmpty())
+
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(transcripts).To(HaveLen(1))
+		Expect(string(transcripts[0].ResponseBody)).To(Equal("data: 1\n\n"))
+	})
+
+	It("should not capture response bodies of streaming requests", func() {
+		rt := middlewares.TraceMiddleware(options).Wrap(respondWith(`{"id":1}`))
+
+		resp, err := rt(request.WithContext(middlewares.MarkStreamingRequest(request.Context())))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transcripts).To(HaveLen(1))
+		Expect(transcripts[0].ResponseBody).To(BeEmpty())
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"id":1}`))
+	})
+
+	It("should record response body read errors", func() {
+		readErr := errors.New("connection reset")
+		rt := middlewares.TraceMiddleware(options).Wrap(func(req *http.Request) (*http.Response, error) {
+			body := io.MultiReader(strings.NewReader("part"), iotest.ErrReader(readErr))
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}, Body: io.NopCloser(body)}, nil
+		})
+
+		resp, err := rt(request)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).To(MatchError(readErr))
+
+		Expect(transcripts).To(HaveLen(1))
+		Expect(string(transcripts[0].ResponseBody)).To(Equal("part"))
+		Expect(transcripts[0].ResponseBodyErr).To(MatchError(readErr))
+		Expect(transcripts[0].String()).To(ContainSubstring("! body error: connection reset"))
+	})
+
+	It("should redact JSON and form body fields named in HeadersToRedact", func() {
+		options.HeadersToRedact = []string{"Authorization", "password"}
+		rt := middlewares.TraceMiddleware(options).Wrap(func(req *http.Request) (*http.Response, error) {
+			_, _ = io.ReadAll(req.Body)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+				Body:       io.NopCloser(strings.NewReader("user=bob&Password=hunter2")),
+			}, nil
+		})
+
+		req, err := http.NewRequest("POST", "https://example.com/login",
+			strings.NewReader(`{"user":{"name":"bob","password":"hunter2"},"authorization":"secret"}`))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := rt(req)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		t := transcripts[0]
+		Expect(t.RequestBody).To(MatchJSON(`{"user":{"name":"bob","password":"[REDACTED]"},"authorization":"[REDACTED]"}`))
+		Expect(string(t.ResponseBody)).To(Equal("Password=%5BREDACTED%5D&user=bob"))
+		Expect(t.String()).NotTo(ContainSubstring("hunter2"))
+	})
+})
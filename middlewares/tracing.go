@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// TracingOptions configures the OpenTelemetry tracing middleware
+type TracingOptions struct {
+	// Propagator injects the span context into outgoing request headers;
+	// defaults to W3C Trace Context (traceparent and tracestate)
+	Propagator propagation.TextMapPropagator
+	// SpanName names the span of a request; defaults to "HTTP " followed by the method
+	SpanName func(req *http.Request) string
+}
+
+// TracingMiddleware creates a middleware that starts a client span per request with tracer,
+// as a child of any span in the request context. The span carries the http.method, http.url
+// and http.status_code attributes, its context is injected into the request headers through
+// the propagator, and transport errors and error statuses are recorded on it. The span ends
+// when the response body is closed, so its duration covers the full download; a failed round
+// trip ends it immediately.
+func TracingMiddleware(tracer trace.Tracer, options TracingOptions) ConfigurableMiddleware {
+	if options.Propagator == nil {
+		options.Propagator = propagation.TraceContext{}
+	}
+	if options.SpanName == nil {
+		options.SpanName = func(req *http.Request) string {
+			return "HTTP " + req.Method
+		}
+	}
+
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), options.SpanName(req),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.method", req.Method),
+					attribute.String("http.url", req.URL.Redacted()),
+				),
+			)
+
+			traced := req.Clone(ctx)
+			options.Propagator.Inject(ctx, propagation.HeaderCarrier(traced.Header))
+
+			resp, err := next(traced)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+				return resp, err
+			}
+
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			if resp.Body == nil || resp.Body == http.NoBody {
+				span.End()
+				return resp, nil
+			}
+			resp.Body = &tracedBody{ReadCloser: resp.Body, span: span}
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("tracing", options, wrapper)
+}
+
+// tracedBody ends its span once the body is closed, recording read errors other than EOF.
+type tracedBody struct {
+	io.ReadCloser
+	span trace.Span
+	once sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.span.RecordError(err)
+		b.span.SetStatus(codes.Error, err.Error())
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.span.End()
+	})
+	return err
+}
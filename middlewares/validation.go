@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jzx17/gofetch/core"
+)
+
+// ResponseValidationError is returned when a response fails validation
+type ResponseValidationError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *ResponseValidationError) Error() string {
+	return fmt.Sprintf("response validation failed (status %d): %v", e.StatusCode, e.Err)
+}
+
+func (e *ResponseValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseValidationMiddleware creates a middleware that runs validate against every response.
+// If validate returns an error the response body is drained and closed and a
+// ResponseValidationError is returned instead of the response.
+func ResponseValidationMiddleware(validate func(*http.Response) error) ConfigurableMiddleware {
+	wrapper := func(next core.RoundTripFunc) core.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || validate == nil {
+				return resp, err
+			}
+
+			if vErr := validate(resp); vErr != nil {
+				DrainAndClose(resp)
+				return nil, &ResponseValidationError{
+					StatusCode: resp.StatusCode,
+					Err:        vErr,
+				}
+			}
+
+			return resp, nil
+		}
+	}
+
+	return CreateMiddleware("response-validation", nil, wrapper)
+}
+
+// RequireHeader returns a validator that fails when the response lacks the given header
+func RequireHeader(name string) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if resp.Header.Get(name) == "" {
+			return fmt.Errorf("missing required header %s", name)
+		}
+		return nil
+	}
+}
+
+// RequireStatus returns a validator that fails when the response status is not one of codes
+func RequireStatus(codes ...int) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		for _, code := range codes {
+			if resp.StatusCode == code {
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,101 @@
+package middlewares_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jzx17/gofetch/core"
+	"github.com/jzx17/gofetch/middlewares"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResponseValidationMiddleware", func() {
+	var (
+		header http.Header
+		status int
+		body   *trackingBody
+		dummy  core.RoundTripFunc
+	)
+
+	BeforeEach(func() {
+		header = http.Header{}
+		status = http.StatusOK
+		body = &trackingBody{Reader: strings.NewReader("payload")}
+		dummy = func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     header,
+				Body:       body,
+			}, nil
+		}
+	})
+
+	It("should reject a response missing a required header", func() {
+		mw := middlewares.ResponseValidationMiddleware(middlewares.RequireHeader("X-Request-Id"))
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		resp, err := mw.Wrap(dummy)(req)
+		Expect(resp).To(BeNil())
+		Expect(err).To(HaveOccurred())
+
+		var validationErr *middlewares.ResponseValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(validationErr.StatusCode).To(Equal(http.StatusOK))
+		Expect(err.Error()).To(ContainSubstring("X-Request-Id"))
+		Expect(body.closed).To(BeTrue())
+	})
+
+	It("should pass a response that satisfies the validator", func() {
+		header.Set("X-Request-Id", "abc")
+		mw := middlewares.ResponseValidationMiddleware(middlewares.RequireHeader("X-Request-Id"))
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		resp, err := mw.Wrap(dummy)(req)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("payload"))
+	})
+
+	It("should reject statuses outside the allowed set", func() {
+		status = http.StatusTeapot
+		mw := middlewares.ResponseValidationMiddleware(middlewares.RequireStatus(http.StatusOK, http.StatusCreated))
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+		_, err := mw.Wrap(dummy)(req)
+		var validationErr *middlewares.ResponseValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(validationErr.StatusCode).To(Equal(http.StatusTeapot))
+	})
+
+	It("should pass through transport errors without validating", func() {
+		called := false
+		mw := middlewares.ResponseValidationMiddleware(func(*http.Response) error {
+			called = true
+			return nil
+		})
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		failing := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("boom")
+		})
+
+		_, err := mw.Wrap(failing)(req)
+		Expect(err).To(MatchError("boom"))
+		Expect(called).To(BeFalse())
+	})
+})
+
+// trackingBody records whether Close was called
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
@@ -1,8 +1,15 @@
 package gofetch
 
 import (
+	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 type Option func(*Client)
@@ -21,6 +28,33 @@ func WithTransport(rt http.RoundTripper) Option {
 	}
 }
 
+// WithUnixSocket sends every request over the UNIX domain socket at path, e.g.
+// "/var/run/docker.sock", using NewUnixSocketTransport. The URL host is only used for the
+// Host header, so requests can address "http://unix/v1.41/containers/json".
+func WithUnixSocket(path string) Option {
+	return func(c *Client) {
+		c.rt = NewUnixSocketTransport(path)
+	}
+}
+
+// WithBaseURL resolves request URLs without a scheme and host, such as "/v1/users", against
+// base with url.ResolveReference, so the host need not be repeated; absolute request URLs are
+// sent as is. As with any reference starting with "/", the request path replaces the path of base.
+// Query parameters of base are added to every resolved request that does not set them.
+// Panics if base is not an absolute URL with a scheme and host.
+func WithBaseURL(base string) Option {
+	parsed, err := url.Parse(base)
+	if err == nil && (parsed.Scheme == "" || parsed.Host == "") {
+		err = fmt.Errorf("missing scheme or host")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("invalid base URL %q: %v", base, err))
+	}
+	return func(c *Client) {
+		c.baseURL = parsed
+	}
+}
+
 // WithConnectionPool sets the maximum idle connections and maximum idle connections per host.
 func WithConnectionPool(maxIdle, maxIdlePerHost int) Option {
 	return func(c *Client) {
@@ -78,3 +112,336 @@ func WithSizeConfig(config SizeConfig) Option {
 
 	}
 }
+
+// WithResponseBodyMaxBytes caps the response body Do reads into memory at n bytes, failing with a
+// ResponseError wrapping a *SizeError once it is exceeded, without installing the size validation
+// middleware. A declared Content-Length above n fails before the body is read. Streamed responses
+// and clients without auto-buffering are not affected. Combined with WithSizeConfig, the stricter
+// limit applies. A limit of zero or less disables the cap.
+func WithResponseBodyMaxBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxBufferedBody = n
+	}
+}
+
+// WithResponseBodyReaderFactory makes Do buffer response bodies into the stores created by
+// factory, such as temporary files, instead of memory. The Response then reads from the store
+// and closing it closes the store; unlike in-memory buffering, the body can be read only once.
+// Streamed responses and clients without auto-buffering are not affected.
+func WithResponseBodyReaderFactory(factory BodyBufferFactory) Option {
+	return func(c *Client) {
+		c.bodyBufferFactory = factory
+	}
+}
+
+// WithPerHostConcurrency limits the number of concurrent in-flight requests per host.
+// Keys in limits may be a bare hostname or host:port; hosts not listed use defaultN.
+// A limit of zero or less leaves the host unlimited. Requests wait for a free slot
+// until their context is done, and a slot is held until the response body is closed.
+func WithPerHostConcurrency(limits map[string]int, defaultN int) Option {
+	return func(c *Client) {
+		c.hostLimiter = newHostConcurrencyLimiter(limits, defaultN)
+	}
+}
+
+// WithResponseCache memoizes successful GET responses by URL for the given TTL,
+// keeping at most maxEntries responses. Authenticated requests are cached per credentials.
+func WithResponseCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, ResponseCacheMiddleware(ResponseCacheOptions{
+			TTL:        ttl,
+			MaxEntries: maxEntries,
+		}))
+	}
+}
+
+// WithProxyFromURL rotates requests among the given proxy URLs by setting the Proxy of the
+// client's transport. Panics if no usable proxy URL is provided.
+func WithProxyFromURL(proxyURLs []string, options RotatingProxyOptions) Option {
+	return func(c *Client) {
+		proxy, err := NewRotatingProxy(proxyURLs, options)
+		if err != nil {
+			panic(err.Error())
+		}
+		c.editTransport("WithProxyFromURL", func(t *http.Transport) {
+			t.Proxy = proxy.Proxy
+		})
+	}
+}
+
+// WithResponseCharsetOverride interprets every response body as the given charset, regardless of
+// what the server declares, and transcodes it to UTF-8. Useful for mislabeled servers. Only
+// responses with a textual Content-Type are transcoded; binary bodies pass through unchanged.
+// It panics if charset is not a supported encoding label.
+func WithResponseCharsetOverride(charset string) Option {
+	return func(c *Client) {
+		if _, err := htmlindex.Get(charset); err != nil {
+			panic(fmt.Sprintf("unsupported charset %q: %v", charset, err))
+		}
+		c.charsetOverride = charset
+	}
+}
+
+// WithResponseBodyDecryptor passes every response body to decrypt, for APIs that return encrypted
+// payloads such as JWE or AES-GCM, and hands the plaintext to the caller, so JSON, Bytes and
+// streaming see decrypted data. The body is read in full before decryption, streamed responses
+// included; charset overrides apply to the plaintext. A decryption error fails the request.
+// Empty bodies, HEAD responses and 1xx, 204 and 304 statuses are not passed to decrypt; see
+// WithResponseBodyDecryptorWhen to skip others, such as plaintext error responses.
+func WithResponseBodyDecryptor(decrypt func(ciphertext []byte) ([]byte, error)) Option {
+	return func(c *Client) {
+		c.bodyDecryptor = decrypt
+	}
+}
+
+// WithResponseBodyDecryptorWhen limits WithResponseBodyDecryptor to the responses for which
+// predicate returns true, e.g. by status or Content-Type. The predicate sees the response
+// before its body is read.
+func WithResponseBodyDecryptorWhen(predicate func(resp *http.Response) bool) Option {
+	return func(c *Client) {
+		c.decryptWhen = predicate
+	}
+}
+
+// WithTrace writes a full transcript of every request and response to w: method, URL,
+// redacted headers, and bodies capped at DefaultTraceOptions().MaxBodyLen bytes.
+func WithTrace(w io.Writer) Option {
+	options := DefaultTraceOptions()
+	options.Writer = w
+	return WithMiddlewares(TraceMiddleware(options))
+}
+
+// WithTraceFunc passes a structured Transcript of every request and response to fn.
+func WithTraceFunc(fn func(*Transcript)) Option {
+	options := DefaultTraceOptions()
+	options.OnTranscript = fn
+	return WithMiddlewares(TraceMiddleware(options))
+}
+
+// WithRequestTracePropagation injects the W3C trace context (traceparent and tracestate) and
+// W3C baggage found in each request's context into its headers, for cross-service context
+// propagation without a tracing middleware. propagator defaults to DefaultPropagator when nil.
+// Baggage already set on the request is merged with the context's, and explicitly set
+// traceparent or tracestate headers are kept.
+func WithRequestTracePropagation(propagator propagation.TextMapPropagator) Option {
+	return WithMiddlewares(PropagationMiddleware(propagator))
+}
+
+// WithResponseValidationSchema checks every JSON response body against validator, typically an
+// adapter over a JSON Schema library. Non-conforming bodies fail with a ResponseError wrapping a
+// SchemaValidationError that lists each violation; conforming bodies are returned unchanged.
+func WithResponseValidationSchema(validator SchemaValidator) Option {
+	return WithMiddlewares(ResponseValidationMiddleware(RequireJSONSchema(validator)))
+}
+
+// WithRequestConcurrencyObserver reports the number of requests in flight to observe as each
+// request starts and ends, to measure queueing behind rate limits and concurrency gates. Requests
+// are counted from the point the option appears in the middleware chain, so pass it before
+// WithMiddlewares installing a RateLimitMiddleware to include the time spent waiting in it.
+func WithRequestConcurrencyObserver(observe ConcurrencyObserver) Option {
+	return WithMiddlewares(ConcurrencyObserverMiddleware(observe))
+}
+
+// WithErrorOnStatus makes Do return a *StatusError together with the response whenever
+// predicate matches its status code, e.g. one reporting code >= 400 to treat 4xx and 5xx as errors.
+// The response is still returned and readable in full, and StatusError.Body holds up to
+// DefaultStatusErrorBodyLimit bytes of its body. Disabled by default.
+func WithErrorOnStatus(predicate func(statusCode int) bool) Option {
+	return func(c *Client) {
+		c.errorOnStatus = predicate
+	}
+}
+
+// WithMaxHeaderCount rejects responses carrying more than n header fields with a
+// ResponseError wrapping a HeaderCountError.
+func WithMaxHeaderCount(n int) Option {
+	return WithMiddlewares(HeaderCountMiddleware(n))
+}
+
+// WithResponseBodyReplay captures the first n bytes of every response body as it is read,
+// including streamed responses, so they can be inspected afterwards with Response.CapturedBody.
+// The caller still consumes the full, unmodified body.
+func WithResponseBodyReplay(n int) Option {
+	return func(c *Client) {
+		c.bodyReplayLimit = n
+	}
+}
+
+// WithResponseErrorClassifier centralizes the definition of failure: the retry middleware
+// retries exactly the exchanges that classifier reports as ErrorClassRetryable, instead of
+// consulting the strategy. This lets, for example, a 200 with an error envelope be retried.
+// The client ships no circuit breaker; a breaker middleware can count failures the same way
+// through ClassifyExchange.
+func WithResponseErrorClassifier(classifier ErrorClassifier) Option {
+	return func(c *Client) {
+		c.classifier = classifier
+	}
+}
+
+// WithMaxResponseTime calls onViolation for every request whose response headers take longer
+// than threshold to arrive, for SLO monitoring without a metrics pipeline.
+func WithMaxResponseTime(threshold time.Duration, onViolation func(req *http.Request, duration time.Duration)) Option {
+	return WithMiddlewares(SLOMiddleware(threshold, onViolation))
+}
+
+// WithResponseCompressionAuto decodes gzip and deflate response bodies, including chained
+// encodings, based on Content-Encoding, even when Accept-Encoding was set manually and the
+// transport left the body compressed. decoders adds other encodings keyed by lowercase name,
+// e.g. "br" backed by a brotli package; pass nil for the built-in ones only.
+func WithResponseCompressionAuto(decoders map[string]ContentDecoder) Option {
+	return WithMiddlewares(DecompressionMiddleware(DecompressionOptions{Decoders: decoders}))
+}
+
+// WithOnConnectionError calls fn with the target host whenever a round trip fails at the
+// connection level, such as a dial failure, a reset connection or a TLS handshake error.
+// Status errors never trigger it. Every attempt is reported, including retries, which makes
+// it suited to alerting on unreachable hosts. fn runs synchronously and should return quickly.
+func WithOnConnectionError(fn func(host string, err error)) Option {
+	return func(c *Client) {
+		c.onConnectionError = fn
+	}
+}
+
+// WithResponseStatusRewrite rewrites response status codes with rewrite before the response
+// reaches the caller and every middleware, including retry, so IsSuccess, IsError and retry
+// decisions all follow the rewritten status. Bodies up to DefaultStatusRewriteMaxInspectBody
+// bytes are buffered and passed to rewrite; larger or streamed bodies are passed as nil.
+func WithResponseStatusRewrite(rewrite StatusRewriteFunc) Option {
+	return func(c *Client) {
+		c.statusRewrite = StatusRewriteMiddleware(StatusRewriteOptions{
+			Rewrite:        rewrite,
+			MaxInspectBody: DefaultStatusRewriteMaxInspectBody,
+		})
+	}
+}
+
+// WithConnectTimeout bounds how long establishing a connection may take. Connections that
+// cannot be established are reported as a *ConnectError, whose Timeout method reports whether
+// the limit was hit, so callers can tell "couldn't connect" from "server too slow to respond".
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.editTransport("WithConnectTimeout", func(t *http.Transport) {
+			t.DialContext = DialWithConnectTimeout(t.DialContext, timeout)
+		})
+	}
+}
+
+// WithMaxRequestsPerConnection closes every connection after it has carried n requests, once the
+// response body of the last one is closed, so the next request dials again. This spreads load
+// across backends behind load balancers that pin connections to one instance.
+func WithMaxRequestsPerConnection(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			return
+		}
+		c.editTransport("WithMaxRequestsPerConnection", func(t *http.Transport) {
+			t.DialContext = countingDialer(t.DialContext)
+		})
+		c.maxRequestsPerConn = n
+	}
+}
+
+// WithRequestRetryHistory makes retries avoid IPs that already failed to connect. Each request
+// resolves its host with resolver, or net.DefaultResolver when nil, and every attempt dials a
+// single IP, preferring ones that have not failed for that request, so a retry after a
+// connection error rotates to another address instead of hitting the same broken one. Pair it
+// with a retry middleware.
+func WithRequestRetryHistory(resolver Resolver) Option {
+	return func(c *Client) {
+		c.editTransport("WithRequestRetryHistory", func(t *http.Transport) {
+			t.DialContext = historyDialer(t.DialContext, resolver)
+		})
+		c.retryHistory = true
+	}
+}
+
+// WithEmptyBodyPolicy sets how JSON, XML and the other decode helpers treat an empty response
+// body: EmptyBodyError, the default, fails with ErrEmptyBody; EmptyBodyIgnore makes decoding
+// a no-op, for APIs that answer 200 with no content but a JSON Content-Type.
+func WithEmptyBodyPolicy(policy EmptyBodyPolicy) Option {
+	return func(c *Client) {
+		c.emptyBodyPolicy = policy
+	}
+}
+
+// WithTLSKeyLogWriter writes TLS session secrets in NSS key log format to w, so captured traffic
+// can be decrypted with tools such as Wireshark. Anyone holding the output can decrypt every
+// session it covers: use it only for local debugging, never in production.
+func WithTLSKeyLogWriter(w io.Writer) Option {
+	return func(c *Client) {
+		c.editTransport("WithTLSKeyLogWriter", func(t *http.Transport) {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.KeyLogWriter = w
+		})
+	}
+}
+
+// WithHTTPSOnly rejects every request whose URL is not https, including redirects to plaintext
+// URLs, with a RequestError wrapping ErrInsecureScheme, so credentials are never sent in the clear
+// by accident. The check runs before the request is sent and on every redirect.
+func WithHTTPSOnly() Option {
+	return func(c *Client) {
+		c.httpsOnly = true
+	}
+}
+
+// WithRedirectPolicy decides whether to follow each redirect, with the same semantics as
+// http.Client.CheckRedirect: req is the upcoming request and via the requests made so far,
+// oldest first. Returning http.ErrUseLastResponse hands the redirect response to the caller;
+// any other error fails the request. It replaces the CheckRedirect of a client set with
+// WithHTTPClient.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) Option {
+	return func(c *Client) {
+		c.redirectPolicy = policy
+	}
+}
+
+// WithMaxRedirects follows at most n redirects, failing with an error wrapping
+// ErrTooManyRedirects when a request would need more.
+func WithMaxRedirects(n int) Option {
+	return WithRedirectPolicy(maxRedirectsPolicy(n))
+}
+
+// WithNoRedirects makes Do and DoStream return 3xx responses as they are instead of following them.
+func WithNoRedirects() Option {
+	return WithRedirectPolicy(noRedirectsPolicy)
+}
+
+// WithRedirectAuthForwarding controls whether the Authorization header of a request is kept
+// when a redirect leads to another host. net/http drops it by default, so credentials meant
+// for one host are not leaked to another; enable forwarding only for hosts you trust, such as
+// an API that redirects between its own domains. Headers set by middlewares are re-applied on
+// every hop regardless.
+func WithRedirectAuthForwarding(forward bool) Option {
+	return func(c *Client) {
+		c.forwardAuthOnRedirect = forward
+	}
+}
+
+// WithResponseHeaderWhitelist strips every response header not named in headers before the
+// response reaches the caller, which is useful when forwarding responses. Names are matched
+// case-insensitively. Middlewares and redirect handling still see the full header set, and
+// headers the decode helpers rely on, such as Content-Type, are stripped too unless listed.
+func WithResponseHeaderWhitelist(headers ...string) Option {
+	return func(c *Client) {
+		c.headerWhitelist = make(map[string]bool, len(headers))
+		for _, name := range headers {
+			c.headerWhitelist[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// WithRetryLog keeps the outcomes of the last size requests, including their status, number
+// of attempts, duration and error, in memory for Client.RecentRequests. It helps debug
+// intermittent failures without enabling full logging. A size of zero or less disables it.
+func WithRetryLog(size int) Option {
+	return func(c *Client) {
+		if size <= 0 {
+			c.requestLog = nil
+			return
+		}
+		c.requestLog = newRequestLog(size)
+	}
+}
@@ -1,16 +1,29 @@
 package gofetch_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
 	"errors"
+	"fmt"
 	"github.com/jzx17/gofetch"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jzx17/gofetch/core"
+	"go.opentelemetry.io/otel/baggage"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -235,4 +248,1132 @@ var _ = Describe("Client Options", func() {
 			)
 		}).NotTo(Panic())
 	})
+
+	It("should limit concurrency per host with WithPerHostConcurrency", func() {
+		var mu sync.Mutex
+		inFlight := map[string]int{}
+		peak := map[string]int{}
+
+		mockTransport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Hostname()
+			mu.Lock()
+			inFlight[host]++
+			if inFlight[host] > peak[host] {
+				peak[host] = inFlight[host]
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			inFlight[host]--
+			mu.Unlock()
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("ok")),
+			}, nil
+		})
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(mockTransport),
+			gofetch.WithPerHostConcurrency(map[string]int{"limited.example.com": 1}, 0),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			for _, host := range []string{"limited.example.com", "free.example.com"} {
+				wg.Add(1)
+				go func(url string) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := client.Do(context.Background(), core.NewRequest("GET", url))
+					Expect(err).NotTo(HaveOccurred())
+				}("http://" + host + "/")
+			}
+		}
+		wg.Wait()
+
+		Expect(peak["limited.example.com"]).To(Equal(1))
+		Expect(peak["free.example.com"]).To(BeNumerically(">", 1))
+	})
+
+	It("should stop waiting for a host slot when the context is done", func() {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		mockTransport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("ok")),
+			}, nil
+		})
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(mockTransport),
+			gofetch.WithPerHostConcurrency(nil, 1),
+		)
+
+		first := client.DoAsync(context.Background(), core.NewRequest("GET", "http://example.com"))
+		Eventually(started).Should(Receive())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := client.Do(ctx, core.NewRequest("GET", "http://example.com"))
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+
+		close(release)
+		result := <-first
+		Expect(result.Error).NotTo(HaveOccurred())
+	})
+
+	It("should rotate requests across proxies with WithProxyFromURL", func() {
+		var mu sync.Mutex
+		var order []string
+		newProxy := func(name string) *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				io.WriteString(w, "via "+name)
+			}))
+		}
+		proxyA := newProxy("a")
+		defer proxyA.Close()
+		proxyB := newProxy("b")
+		defer proxyB.Close()
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(&http.Transport{}),
+			gofetch.WithProxyFromURL([]string{proxyA.URL, proxyB.URL}, gofetch.RotatingProxyOptions{}),
+		)
+
+		for i := 0; i < 4; i++ {
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://upstream.invalid/"))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(order).To(Equal([]string{"a", "b", "a", "b"}))
+	})
+
+	It("should apply WithProxyFromURL to a transport set later or through WithHTTPClient", func() {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "via proxy")
+		}))
+		defer proxy.Close()
+		shared := &http.Transport{}
+
+		for _, client := range []*gofetch.Client{
+			gofetch.NewClient(
+				gofetch.WithProxyFromURL([]string{proxy.URL}, gofetch.RotatingProxyOptions{}),
+				gofetch.WithTransport(shared),
+			),
+			gofetch.NewClient(
+				gofetch.WithProxyFromURL([]string{proxy.URL}, gofetch.RotatingProxyOptions{}),
+				gofetch.WithHTTPClient(&http.Client{Transport: shared}),
+			),
+		} {
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://upstream.invalid/"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.String()).To(Equal("via proxy"))
+		}
+		Expect(shared.Proxy).To(BeNil())
+	})
+
+	It("should panic when a transport option is used with a transport that is not an *http.Transport", func() {
+		Expect(func() {
+			gofetch.NewClient(
+				gofetch.WithProxyFromURL([]string{"http://proxy.example:8080"}, gofetch.RotatingProxyOptions{}),
+				gofetch.WithTransport(core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					return nil, errors.New("unused")
+				})),
+			)
+		}).To(PanicWith(ContainSubstring("WithProxyFromURL requires an *http.Transport")))
+	})
+
+	It("should panic in WithProxyFromURL when no proxy is usable", func() {
+		Expect(func() {
+			gofetch.NewClient(gofetch.WithProxyFromURL([]string{"bad"}, gofetch.RotatingProxyOptions{SkipInvalid: true}))
+		}).To(Panic())
+	})
+
+	It("should transcode responses with WithResponseCharsetOverride", func() {
+		mockTransport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				// "Привет" encoded as Windows-1251
+				Body: io.NopCloser(strings.NewReader("\xCF\xF0\xE8\xE2\xE5\xF2")),
+			}, nil
+		})
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(mockTransport),
+			gofetch.WithResponseCharsetOverride("windows-1251"),
+		)
+
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("Привет"))
+	})
+
+	It("should panic in WithResponseCharsetOverride on an unknown charset", func() {
+		Expect(func() {
+			gofetch.NewClient(gofetch.WithResponseCharsetOverride("no-such-charset"))
+		}).To(PanicWith(ContainSubstring("unsupported charset")))
+	})
+
+	It("should dump a request transcript with WithTrace", func() {
+		mockTransport := core.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("pong")),
+			}, nil
+		})
+
+		var transcript bytes.Buffer
+		client := gofetch.NewClient(
+			gofetch.WithTransport(mockTransport),
+			gofetch.WithTrace(&transcript),
+		)
+
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com/ping"))
+		Expect(err).NotTo(HaveOccurred())
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("pong"))
+
+		Expect(transcript.String()).To(ContainSubstring("> GET http://example.com/ping"))
+		Expect(transcript.String()).To(ContainSubstring("< 200 OK"))
+		Expect(transcript.String()).To(ContainSubstring("< pong"))
+	})
+
+	It("should reject responses with excessive headers using WithMaxHeaderCount", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 200; i++ {
+				w.Header().Add("X-Amplified", "x")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := gofetch.NewClient(gofetch.WithMaxHeaderCount(100))
+		_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).To(HaveOccurred())
+
+		var countErr *gofetch.HeaderCountError
+		Expect(errors.As(err, &countErr)).To(BeTrue())
+		Expect(countErr.Count).To(BeNumerically(">", 100))
+	})
+
+	It("should reject JSON bodies that fail WithResponseValidationSchema", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"name":"widget"}`)
+		}))
+		defer server.Close()
+
+		validator := gofetch.SchemaValidatorFunc(func(document []byte) []gofetch.SchemaViolation {
+			return []gofetch.SchemaViolation{{Message: "missing property id"}}
+		})
+		client := gofetch.NewClient(gofetch.WithResponseValidationSchema(validator))
+		_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+
+		var schemaErr *gofetch.SchemaValidationError
+		Expect(errors.As(err, &schemaErr)).To(BeTrue())
+		Expect(schemaErr.Violations).To(ConsistOf(gofetch.SchemaViolation{Message: "missing property id"}))
+	})
+
+	It("should report in-flight counts with WithRequestConcurrencyObserver", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+
+		var counts []int64
+		client := gofetch.NewClient(gofetch.WithRequestConcurrencyObserver(func(phase gofetch.ConcurrencyPhase, inFlight int64) {
+			counts = append(counts, inFlight)
+		}))
+		_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal([]int64{1, 0}))
+	})
+
+	Context("WithErrorOnStatus", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/missing" {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = io.WriteString(w, `{"error":"not found"}`)
+					return
+				}
+				_, _ = io.WriteString(w, "ok")
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		isError := func(code int) bool { return code >= 400 }
+
+		It("should return a StatusError with the buffered body alongside the response", func() {
+			client := gofetch.NewClient(gofetch.WithErrorOnStatus(isError))
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL+"/missing"))
+
+			var statusErr *gofetch.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(gofetch.IsStatusError(err, http.StatusNotFound)).To(BeTrue())
+			Expect(statusErr.URL).To(Equal(server.URL + "/missing"))
+			Expect(string(statusErr.Body)).To(Equal(`{"error":"not found"}`))
+
+			Expect(resp).NotTo(BeNil())
+			body, readErr := resp.String()
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(body).To(Equal(`{"error":"not found"}`))
+		})
+
+		It("should not fail statuses the predicate does not match", func() {
+			client := gofetch.NewClient(gofetch.WithErrorOnStatus(isError))
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should leave the full body on the response when streaming", func() {
+			client := gofetch.NewClient(gofetch.WithErrorOnStatus(isError), gofetch.WithAutoBufferResponse(false))
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL+"/missing"))
+
+			var statusErr *gofetch.StatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(string(statusErr.Body)).To(Equal(`{"error":"not found"}`))
+			body, readErr := resp.String()
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(body).To(Equal(`{"error":"not found"}`))
+		})
+
+		It("should keep returning nil errors for error statuses by default", func() {
+			resp, err := gofetch.NewClient().Do(context.Background(), core.NewRequest("GET", server.URL+"/missing"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	It("should dial a new connection after WithMaxRequestsPerConnection requests", func() {
+		var mu sync.Mutex
+		remotes := map[string]bool{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			remotes[r.RemoteAddr] = true
+			mu.Unlock()
+			_, _ = io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(&http.Transport{}),
+			gofetch.WithMaxRequestsPerConnection(2),
+		)
+
+		var reused []bool
+		for i := 0; i < 5; i++ {
+			ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					reused = append(reused, info.Reused)
+				},
+			})
+			_, err := client.Do(ctx, core.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(reused).To(Equal([]bool{false, true, false, true, false}))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(remotes).To(HaveLen(3))
+	})
+
+	It("should rotate connections for requests with a body", func() {
+		var mu sync.Mutex
+		remotes := map[string]int{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			mu.Lock()
+			remotes[r.RemoteAddr]++
+			mu.Unlock()
+			_, _ = io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(&http.Transport{}),
+			gofetch.WithMaxRequestsPerConnection(2),
+		)
+		for i := 0; i < 4; i++ {
+			_, err := client.Do(context.Background(), core.NewRequest("POST", server.URL).WithBody([]byte("payload")))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(remotes).To(HaveLen(2))
+		for _, n := range remotes {
+			Expect(n).To(Equal(2))
+		}
+	})
+
+	It("should send requests over a UNIX socket with WithUnixSocket", func() {
+		dir, err := os.MkdirTemp("", "gofetch")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		socketPath := filepath.Join(dir, "daemon.sock")
+
+		listener, err := net.Listen("unix", socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[{"Id":"abc"}]`)
+		})}
+		go func() { _ = server.Serve(listener) }()
+		defer server.Close()
+
+		client := gofetch.NewClient(gofetch.WithUnixSocket(socketPath))
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://docker/v1.41/containers/json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.String()).To(Equal(`[{"Id":"abc"}]`))
+	})
+
+	It("should retry against a different IP with WithRequestRetryHistory", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+		defer server.Close()
+		_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+
+		var mu sync.Mutex
+		var dialed []string
+		dialer := &net.Dialer{}
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				mu.Lock()
+				dialed = append(dialed, addr)
+				mu.Unlock()
+				if strings.HasPrefix(addr, "10.0.0.1:") {
+					return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+				}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+		resolver := staticResolver{"service.test": {"10.0.0.1", "127.0.0.1"}}
+
+		client := gofetch.NewClient(
+			gofetch.WithTransport(transport),
+			gofetch.WithRequestRetryHistory(resolver),
+			gofetch.WithMiddlewares(gofetch.RetryMiddleware(gofetch.NewConstantDelayStrategy(time.Millisecond, 3))),
+		)
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://service.test:"+port+"/"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.String()).To(Equal("ok"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(dialed).To(Equal([]string{"10.0.0.1:" + port, "127.0.0.1:" + port}))
+	})
+
+	It("should drive retries from WithResponseErrorClassifier", func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				_, _ = io.WriteString(w, `{"error":"rate limited"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"result":42}`)
+		}))
+		defer server.Close()
+
+		client := gofetch.NewClient(
+			gofetch.WithMiddlewares(gofetch.RetryMiddleware(gofetch.NewConstantDelayStrategy(time.Millisecond, 3))),
+			gofetch.WithResponseErrorClassifier(func(resp *http.Response, err error) gofetch.ErrorClass {
+				if err != nil {
+					return gofetch.ErrorClassRetryable
+				}
+				body, _ := io.ReadAll(resp.Body)
+				if strings.Contains(string(body), `"error"`) {
+					return gofetch.ErrorClassRetryable
+				}
+				return gofetch.ErrorClassSuccess
+			}),
+		)
+
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(`{"result":42}`))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	})
+
+	It("should decompress responses to a manual Accept-Encoding with WithResponseCompressionAuto", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Accept-Encoding")).To(Equal("gzip, deflate"))
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			_, _ = io.WriteString(zw, `{"ok":true}`)
+			_ = zw.Close()
+		}))
+		defer server.Close()
+
+		client := gofetch.NewClient(gofetch.WithResponseCompressionAuto(nil))
+		resp, err := client.Do(context.Background(),
+			core.NewRequest("GET", server.URL).WithHeader("Accept-Encoding", "gzip, deflate"))
+		Expect(err).NotTo(HaveOccurred())
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(`{"ok":true}`))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+	})
+
+	Context("WithOnConnectionError", func() {
+		type connError struct {
+			host string
+			err  error
+		}
+
+		var (
+			mu     sync.Mutex
+			events []connError
+			record func(host string, err error)
+		)
+
+		BeforeEach(func() {
+			events = nil
+			record = func(host string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, connError{host: host, err: err})
+			}
+		})
+
+		It("should report a dial failure with the target host", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			host := listener.Addr().String()
+			Expect(listener.Close()).To(Succeed())
+
+			client := gofetch.NewClient(gofetch.WithOnConnectionError(record))
+			_, err = client.Do(context.Background(), core.NewRequest("GET", "http://"+host+"/"))
+			Expect(err).To(HaveOccurred())
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].host).To(Equal(host))
+			var opErr *net.OpError
+			Expect(errors.As(events[0].err, &opErr)).To(BeTrue())
+			Expect(opErr.Op).To(Equal("dial"))
+		})
+
+		It("should report a TLS handshake error", func() {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			client := gofetch.NewClient(gofetch.WithOnConnectionError(record))
+			_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+			Expect(err).To(HaveOccurred())
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].host).To(Equal(strings.TrimPrefix(server.URL, "https://")))
+		})
+
+		It("should not report status errors", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			client := gofetch.NewClient(gofetch.WithOnConnectionError(record))
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(events).To(BeEmpty())
+		})
+	})
+
+	Context("WithResponseStatusRewrite", func() {
+		rewriteErrorEnvelope := func(resp *http.Response, body []byte) int {
+			if bytes.Contains(body, []byte(`"error"`)) {
+				return http.StatusServiceUnavailable
+			}
+			if resp.StatusCode == 299 {
+				return http.StatusOK
+			}
+			return 0
+		}
+
+		It("should drive IsSuccess and IsError from the rewritten status", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/vendor" {
+					w.WriteHeader(299)
+					return
+				}
+				_, _ = io.WriteString(w, `{"error":"maintenance"}`)
+			}))
+			defer server.Close()
+
+			client := gofetch.NewClient(gofetch.WithResponseStatusRewrite(rewriteErrorEnvelope))
+
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL+"/vendor"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.IsSuccess()).To(BeTrue())
+
+			resp, err = client.Do(context.Background(), core.NewRequest("GET", server.URL+"/envelope"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.IsError()).To(BeTrue())
+		})
+
+		It("should let retry act on the rewritten status", func() {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) < 3 {
+					_, _ = io.WriteString(w, `{"error":"busy"}`)
+					return
+				}
+				_, _ = io.WriteString(w, `{"result":1}`)
+			}))
+			defer server.Close()
+
+			client := gofetch.NewClient(
+				gofetch.WithMiddlewares(gofetch.RetryMiddleware(gofetch.NewConstantDelayStrategy(time.Millisecond, 3))),
+				gofetch.WithResponseStatusRewrite(rewriteErrorEnvelope),
+			)
+
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.IsSuccess()).To(BeTrue())
+			body, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal(`{"result":1}`))
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+		})
+	})
+
+	It("should surface a ConnectError from WithConnectTimeout", func() {
+		// Stands in for an unroutable address: the dial never completes on its own
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}
+		client := gofetch.NewClient(
+			gofetch.WithTransport(transport),
+			gofetch.WithConnectTimeout(50*time.Millisecond),
+		)
+
+		start := time.Now()
+		_, err := client.Do(context.Background(), core.NewRequest("GET", "http://10.255.255.1:81/"))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+		var connectErr *gofetch.ConnectError
+		Expect(errors.As(err, &connectErr)).To(BeTrue())
+		Expect(connectErr.Timeout()).To(BeTrue())
+		Expect(connectErr.Addr).To(Equal("10.255.255.1:81"))
+	})
+
+	It("should apply WithEmptyBodyPolicy to every response", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+		}))
+		defer server.Close()
+
+		strict := gofetch.NewClient()
+		resp, err := strict.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		var result map[string]interface{}
+		Expect(errors.Is(resp.JSON(&result), gofetch.ErrEmptyBody)).To(BeTrue())
+
+		lenient := gofetch.NewClient(gofetch.WithEmptyBodyPolicy(gofetch.EmptyBodyIgnore))
+		resp, err = lenient.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.JSON(&result)).To(Succeed())
+		Expect(result).To(BeNil())
+	})
+
+	It("should log TLS key material with WithTLSKeyLogWriter", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "secure")
+		}))
+		defer server.Close()
+
+		shared := server.Client().Transport.(*http.Transport)
+		var keyLog bytes.Buffer
+		client := gofetch.NewClient(
+			gofetch.WithTransport(shared),
+			gofetch.WithTLSKeyLogWriter(&keyLog),
+		)
+
+		resp, err := client.Do(context.Background(), core.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("secure"))
+
+		Expect(keyLog.String()).To(ContainSubstring("CLIENT_TRAFFIC_SECRET_0"))
+		// The shared transport is cloned rather than modified
+		Expect(shared.TLSClientConfig.KeyLogWriter).To(BeNil())
+	})
+
+	Context("WithResponseBodyDecryptor", func() {
+		var (
+			server *httptest.Server
+			gcm    cipher.AEAD
+		)
+
+		BeforeEach(func() {
+			block, err := aes.NewCipher([]byte("0123456789abcdef"))
+			Expect(err).NotTo(HaveOccurred())
+			gcm, err = cipher.NewGCM(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nonce := make([]byte, gcm.NonceSize())
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(gcm.Seal(nonce, nonce, []byte(`{"secret":"plaintext"}`), nil))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		decrypt := func(ciphertext []byte) ([]byte, error) {
+			if len(ciphertext) < gcm.NonceSize() {
+				return nil, errors.New("ciphertext too short")
+			}
+			nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+			return gcm.Open(nil, nonce, sealed, nil)
+		}
+
+		It("should decode the decrypted body", func() {
+			client := gofetch.NewClient(gofetch.WithResponseBodyDecryptor(decrypt))
+
+			resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+
+			var payload map[string]string
+			Expect(resp.JSON(&payload)).To(Succeed())
+			Expect(payload).To(Equal(map[string]string{"secret": "plaintext"}))
+		})
+
+		It("should decrypt streamed bodies", func() {
+			client := gofetch.NewClient(gofetch.WithResponseBodyDecryptor(decrypt))
+
+			resp, err := client.DoStream(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.CloseBody()
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"secret":"plaintext"}`))
+			Expect(resp.ContentLength).To(Equal(int64(len(body))))
+		})
+
+		It("should fail the request when decryption fails", func() {
+			client := gofetch.NewClient(gofetch.WithResponseBodyDecryptor(func([]byte) ([]byte, error) {
+				return nil, errors.New("bad key")
+			}))
+
+			_, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).To(MatchError(ContainSubstring("decrypt response body")))
+			Expect(err).To(MatchError(ContainSubstring("bad key")))
+		})
+
+		It("should leave bodiless and empty responses alone", func() {
+			empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/none" {
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer empty.Close()
+			calls := 0
+			client := gofetch.NewClient(gofetch.WithResponseBodyDecryptor(func(ciphertext []byte) ([]byte, error) {
+				calls++
+				return decrypt(ciphertext)
+			}))
+
+			for _, path := range []string{"/none", "/empty"} {
+				resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", empty.URL+path))
+				Expect(err).NotTo(HaveOccurred())
+				body, err := resp.Bytes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(body).To(BeEmpty())
+			}
+			Expect(calls).To(BeZero())
+		})
+
+		It("should only decrypt the responses accepted by WithResponseBodyDecryptorWhen", func() {
+			plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = io.WriteString(w, "upstream down")
+			}))
+			defer plain.Close()
+			client := gofetch.NewClient(
+				gofetch.WithResponseBodyDecryptor(decrypt),
+				gofetch.WithResponseBodyDecryptorWhen(func(resp *http.Response) bool {
+					return resp.Header.Get("Content-Type") == "application/json"
+				}),
+			)
+
+			resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", plain.URL))
+			Expect(err).NotTo(HaveOccurred())
+			body, err := resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("upstream down"))
+
+			resp, err = client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			body, err = resp.String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal(`{"secret":"plaintext"}`))
+		})
+	})
+
+	Context("WithHTTPSOnly", func() {
+		var (
+			plainServer *httptest.Server
+			tlsServer   *httptest.Server
+			plainHits   atomic.Int32
+		)
+
+		BeforeEach(func() {
+			plainHits.Store(0)
+			plainServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				plainHits.Add(1)
+				_, _ = w.Write([]byte("plaintext"))
+			}))
+			tlsServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/downgrade" {
+					http.Redirect(w, r, plainServer.URL, http.StatusFound)
+					return
+				}
+				_, _ = w.Write([]byte("secure"))
+			}))
+		})
+
+		AfterEach(func() {
+			tlsServer.Close()
+			plainServer.Close()
+		})
+
+		newClient := func() *gofetch.Client {
+			return gofetch.NewClient(
+				gofetch.WithTransport(tlsServer.Client().Transport),
+				gofetch.WithHTTPSOnly(),
+			)
+		}
+
+		It("should reject http URLs before sending them", func() {
+			_, err := newClient().Do(context.Background(), gofetch.NewRequest("GET", plainServer.URL))
+
+			Expect(errors.Is(err, gofetch.ErrInsecureScheme)).To(BeTrue())
+			var clientErr *gofetch.ClientError
+			Expect(errors.As(err, &clientErr)).To(BeTrue())
+			Expect(clientErr.Phase).To(Equal("request"))
+			Expect(plainHits.Load()).To(BeZero())
+		})
+
+		It("should send https URLs", func() {
+			resp, err := newClient().Do(context.Background(), gofetch.NewRequest("GET", tlsServer.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.String()).To(Equal("secure"))
+		})
+
+		It("should block a redirect from https to http", func() {
+			_, err := newClient().Do(context.Background(), gofetch.NewRequest("GET", tlsServer.URL+"/downgrade"))
+
+			Expect(errors.Is(err, gofetch.ErrInsecureScheme)).To(BeTrue())
+			var clientErr *gofetch.ClientError
+			Expect(errors.As(err, &clientErr)).To(BeTrue())
+			Expect(clientErr.Phase).To(Equal("request"))
+			Expect(plainHits.Load()).To(BeZero())
+		})
+	})
+
+	Context("WithResponseHeaderWhitelist", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-Id", "abc")
+				w.Header().Set("X-Internal-Host", "db-01")
+				w.Header().Set("Set-Cookie", "session=secret")
+				_, _ = w.Write([]byte(`{"ok":true}`))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should strip headers outside the whitelist", func() {
+			client := gofetch.NewClient(gofetch.WithResponseHeaderWhitelist("content-type", "X-Request-ID"))
+
+			resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(resp.Header.Get("X-Request-Id")).To(Equal("abc"))
+			Expect(resp.Header).NotTo(HaveKey("X-Internal-Host"))
+			Expect(resp.Header).NotTo(HaveKey("Set-Cookie"))
+			Expect(resp.Header).NotTo(HaveKey("Date"))
+		})
+
+		It("should strip headers from streamed responses", func() {
+			client := gofetch.NewClient(gofetch.WithResponseHeaderWhitelist("X-Request-Id"))
+
+			resp, err := client.DoStream(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.CloseBody()
+
+			Expect(resp.Header).To(HaveLen(1))
+			Expect(resp.Header.Get("X-Request-Id")).To(Equal("abc"))
+		})
+
+		It("should let middlewares see every header", func() {
+			var seen http.Header
+			spy := gofetch.CreateMiddleware("spy", nil, func(next core.RoundTripFunc) core.RoundTripFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					resp, err := next(req)
+					if err == nil {
+						seen = resp.Header.Clone()
+					}
+					return resp, err
+				}
+			})
+			client := gofetch.NewClient(
+				gofetch.WithMiddlewares(spy),
+				gofetch.WithResponseHeaderWhitelist("X-Request-Id"),
+			)
+
+			_, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(seen.Get("X-Internal-Host")).To(Equal("db-01"))
+		})
+	})
+
+	Context("WithResponseBodyMaxBytes", func() {
+		bodyTransport := func(body string, contentLength int64) core.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    200,
+					ContentLength: contentLength,
+					Body:          io.NopCloser(strings.NewReader(body)),
+				}, nil
+			}
+		}
+
+		It("should fail a buffered body over the limit", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(bodyTransport(strings.Repeat("A", 20), -1)),
+				gofetch.WithResponseBodyMaxBytes(10),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+
+			var sizeErr *gofetch.SizeError
+			Expect(errors.As(err, &sizeErr)).To(BeTrue())
+			Expect(sizeErr.Type).To(Equal("response"))
+			Expect(sizeErr.Max).To(Equal(int64(10)))
+			Expect(sizeErr.Current).To(BeNumerically(">", 10))
+			Expect(client.GetMiddlewares()).To(BeEmpty())
+		})
+
+		It("should fail before reading when Content-Length is over the limit", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(bodyTransport(strings.Repeat("A", 20), 20)),
+				gofetch.WithResponseBodyMaxBytes(10),
+			)
+
+			_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+
+			var sizeErr *gofetch.SizeError
+			Expect(errors.As(err, &sizeErr)).To(BeTrue())
+			Expect(sizeErr.Current).To(Equal(int64(20)))
+		})
+
+		It("should accept a body at the limit and leave streams alone", func() {
+			client := gofetch.NewClient(
+				gofetch.WithTransport(bodyTransport(strings.Repeat("A", 10), -1)),
+				gofetch.WithResponseBodyMaxBytes(10),
+			)
+
+			resp, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.String()).To(HaveLen(10))
+
+			client = gofetch.NewClient(
+				gofetch.WithTransport(bodyTransport(strings.Repeat("A", 20), -1)),
+				gofetch.WithResponseBodyMaxBytes(10),
+			)
+			stream, err := client.DoStream(context.Background(), core.NewRequest("GET", "http://example.com"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stream.String()).To(HaveLen(20))
+		})
+
+		It("should apply the stricter of the option and the size middleware", func() {
+			body := strings.Repeat("A", 20)
+			cases := []struct {
+				option, middleware int64
+				wantMax            int64
+			}{
+				{option: 10, middleware: 15, wantMax: 10},
+				{option: 15, middleware: 10, wantMax: 10},
+			}
+			for _, tc := range cases {
+				client := gofetch.NewClient(
+					gofetch.WithTransport(bodyTransport(body, -1)),
+					gofetch.WithSizeConfig(gofetch.DefaultSizeConfig().WithResponseBodySize(tc.middleware)),
+					gofetch.WithResponseBodyMaxBytes(tc.option),
+				)
+
+				_, err := client.Do(context.Background(), core.NewRequest("GET", "http://example.com"))
+
+				var sizeErr *gofetch.SizeError
+				Expect(errors.As(err, &sizeErr)).To(BeTrue())
+				Expect(sizeErr.Max).To(Equal(tc.wantMax))
+			}
+		})
+	})
+
+	Context("Redirect policy", func() {
+		var (
+			server *httptest.Server
+			hops   atomic.Int32
+		)
+
+		BeforeEach(func() {
+			hops.Store(0)
+			// /r/N redirects N more times before answering
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hops.Add(1)
+				n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/r/"))
+				if n > 0 {
+					http.Redirect(w, r, fmt.Sprintf("/r/%d", n-1), http.StatusFound)
+					return
+				}
+				_, _ = w.Write([]byte("done"))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should follow up to the maximum number of redirects", func() {
+			client := gofetch.NewClient(gofetch.WithMaxRedirects(3))
+
+			resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL+"/r/3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.String()).To(Equal("done"))
+			Expect(hops.Load()).To(Equal(int32(4)))
+
+			hops.Store(0)
+			client = gofetch.NewClient(gofetch.WithMaxRedirects(2))
+			_, err = client.Do(context.Background(), gofetch.NewRequest("GET", server.URL+"/r/3"))
+			Expect(errors.Is(err, gofetch.ErrTooManyRedirects)).To(BeTrue())
+			Expect(hops.Load()).To(Equal(int32(3)))
+		})
+
+		It("should return the redirect response with WithNoRedirects", func() {
+			client := gofetch.NewClient(gofetch.WithNoRedirects())
+
+			resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL+"/r/3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusFound))
+			Expect(resp.Header.Get("Location")).To(Equal("/r/2"))
+			Expect(hops.Load()).To(Equal(int32(1)))
+		})
+
+		It("should pass every hop to a custom policy", func() {
+			var seen []string
+			client := gofetch.NewClient(gofetch.WithRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+				seen = append(seen, req.URL.Path)
+				Expect(via).To(HaveLen(len(seen)))
+				return nil
+			}))
+
+			_, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL+"/r/3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(seen).To(Equal([]string{"/r/2", "/r/1", "/r/0"}))
+		})
+
+		It("should forward Authorization across hosts only when enabled", func() {
+			var auth string
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+			}))
+			defer target.Close()
+			otherHost := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+			origin := httptest.NewServer(http.RedirectHandler(otherHost, http.StatusFound))
+			defer origin.Close()
+
+			for _, forward := range []bool{false, true} {
+				auth = ""
+				client := gofetch.NewClient(gofetch.WithRedirectAuthForwarding(forward))
+				_, err := client.Do(context.Background(),
+					gofetch.NewRequest("GET", origin.URL).WithBearerToken("secret"))
+				Expect(err).NotTo(HaveOccurred())
+				if forward {
+					Expect(auth).To(Equal("Bearer secret"))
+				} else {
+					Expect(auth).To(BeEmpty())
+				}
+			}
+		})
+	})
+
+	It("should propagate context baggage with WithRequestTracePropagation", func() {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get("Baggage")
+		}))
+		defer server.Close()
+
+		member, err := baggage.NewMember("userId", "alice")
+		Expect(err).NotTo(HaveOccurred())
+		bag, err := baggage.New(member)
+		Expect(err).NotTo(HaveOccurred())
+		ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+		client := gofetch.NewClient(gofetch.WithRequestTracePropagation(nil))
+		_, err = client.Do(ctx, gofetch.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(received).To(Equal("userId=alice"))
+	})
 })
+
+// staticResolver resolves hosts from a fixed table.
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return addrs, nil
+}
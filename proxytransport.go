@@ -0,0 +1,73 @@
+package gofetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// maxProxyTransports bounds the per-proxy transports a client keeps; the least recently used
+// one is dropped, and its idle connections closed, when another proxy is needed.
+const maxProxyTransports = 16
+
+// proxyTransports holds clones of a base transport, one per proxy set with Request.WithProxy.
+// Each clone keeps its own connection pool, so connections are never shared between proxies,
+// and the first request through a proxy always dials.
+type proxyTransports struct {
+	base *http.Transport
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+	// order lists the proxies of transports, least recently used first
+	order []string
+}
+
+// routeProxies sends requests carrying a per-request proxy through a transport configured for
+// it. Requests without one go to base unchanged. The proxy can only be honored when base is an
+// *http.Transport; otherwise such requests fail rather than silently bypass the proxy.
+func routeProxies(base http.RoundTripper) http.RoundTripper {
+	t, ok := base.(*http.Transport)
+	var pool *proxyTransports
+	if ok {
+		pool = &proxyTransports{base: t, transports: make(map[string]*http.Transport)}
+	}
+	return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		proxyURL := ProxyFromContext(req.Context())
+		if proxyURL == nil {
+			return base.RoundTrip(req)
+		}
+		if pool == nil {
+			return nil, fmt.Errorf("per-request proxy %s requires an *http.Transport, got %T", proxyURL.Redacted(), base)
+		}
+		return pool.get(proxyURL).RoundTrip(req)
+	})
+}
+
+// get returns the transport for proxyURL, cloning the base transport on first use.
+func (p *proxyTransports) get(proxyURL *url.URL) *http.Transport {
+	key := proxyURL.String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, k := range p.order {
+		if k == key {
+			p.order = append(append(p.order[:i:i], p.order[i+1:]...), key)
+			return p.transports[key]
+		}
+	}
+
+	t := p.base.Clone()
+	t.Proxy = http.ProxyURL(proxyURL)
+	p.transports[key] = t
+	p.order = append(p.order, key)
+
+	if len(p.order) > maxProxyTransports {
+		oldest := p.order[0]
+		p.order = p.order[1:]
+		p.transports[oldest].CloseIdleConnections()
+		delete(p.transports, oldest)
+	}
+	return t
+}
@@ -0,0 +1,54 @@
+package gofetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrTooManyRedirects is reported when a request exceeds the limit set by WithMaxRedirects.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// maxRedirectsPolicy stops following redirects after n hops.
+func maxRedirectsPolicy(n int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, n)
+		}
+		return nil
+	}
+}
+
+// defaultRedirectPolicy mirrors the policy net/http applies when CheckRedirect is nil.
+func defaultRedirectPolicy(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// noRedirectsPolicy hands the 3xx response to the caller instead of following it.
+func noRedirectsPolicy(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// applyRedirectPolicy installs the configured redirect policy on client, restoring the
+// Authorization header that net/http strips on cross-host redirects when asked to.
+func (c *Client) applyRedirectPolicy(client *http.Client) {
+	if c.redirectPolicy != nil {
+		client.CheckRedirect = c.redirectPolicy
+	}
+	if !c.forwardAuthOnRedirect {
+		return
+	}
+	checkRedirect := client.CheckRedirect
+	if checkRedirect == nil {
+		checkRedirect = defaultRedirectPolicy
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if auth := via[0].Header.Get("Authorization"); auth != "" && req.Header.Get("Authorization") == "" {
+			req.Header.Set("Authorization", auth)
+		}
+		return checkRedirect(req, via)
+	}
+}
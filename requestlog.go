@@ -0,0 +1,95 @@
+package gofetch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestRecord is the outcome of one request kept by WithRetryLog.
+type RequestRecord struct {
+	Method     string
+	URL        string
+	StatusCode int // zero when no response was received
+	// Attempts counts the round trips that reached the transport, including retries and redirects
+	Attempts int
+	Start    time.Time
+	Duration time.Duration // until the response headers, or the error, were returned
+	Err      error
+}
+
+// requestLog is a fixed-size ring buffer of the most recent request records.
+type requestLog struct {
+	mu      sync.Mutex
+	records []RequestRecord
+	next    int
+	full    bool
+}
+
+func newRequestLog(size int) *requestLog {
+	return &requestLog{records: make([]RequestRecord, size)}
+}
+
+type attemptCounterKey struct{}
+
+// start begins recording httpReq and returns a context that counts its attempts, plus a
+// function that stores the outcome once the request is done.
+func (l *requestLog) start(ctx context.Context, httpReq *http.Request) (context.Context, func(*Response, error)) {
+	attempts := new(atomic.Int32)
+	record := RequestRecord{
+		Method: httpReq.Method,
+		URL:    httpReq.URL.Redacted(),
+		Start:  time.Now(),
+	}
+	return context.WithValue(ctx, attemptCounterKey{}, attempts), func(res *Response, err error) {
+		record.Duration = time.Since(record.Start)
+		record.Attempts = int(attempts.Load())
+		record.Err = err
+		if res != nil && res.Response != nil {
+			record.StatusCode = res.StatusCode
+		}
+		l.add(record)
+	}
+}
+
+func (l *requestLog) add(record RequestRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// snapshot returns the stored records, oldest first.
+func (l *requestLog) snapshot() []RequestRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.full {
+		return append([]RequestRecord(nil), l.records[:l.next]...)
+	}
+	snapshot := make([]RequestRecord, 0, len(l.records))
+	snapshot = append(snapshot, l.records[l.next:]...)
+	return append(snapshot, l.records[:l.next]...)
+}
+
+// countAttempt registers a round trip against the request log counter carried by ctx, if any.
+func countAttempt(ctx context.Context) {
+	if attempts, ok := ctx.Value(attemptCounterKey{}).(*atomic.Int32); ok {
+		attempts.Add(1)
+	}
+}
+
+// RecentRequests returns the outcomes of the most recent requests, oldest first, when the
+// client was created with WithRetryLog. It returns nil otherwise.
+func (c *Client) RecentRequests() []RequestRecord {
+	if c.requestLog == nil {
+		return nil
+	}
+	return c.requestLog.snapshot()
+}
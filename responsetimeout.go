@@ -0,0 +1,64 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrResponseTimeout is the cause reported when a response exceeds the limit set by WithResponseTimeout.
+var ErrResponseTimeout = errors.New("response timeout exceeded")
+
+// WithResponseTimeout cancels a request if receiving the complete response, headers and body,
+// takes longer than timeout. Unlike an idle timeout it is not reset by progress on the connection.
+// For streamed responses the limit keeps running until the body is closed.
+func WithResponseTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.responseTimeout = timeout
+	}
+}
+
+// withResponseTimeout derives a context bounded by the response timeout, if one is configured.
+func (c *Client) withResponseTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.responseTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, c.responseTimeout, ErrResponseTimeout)
+}
+
+// timeoutError converts err into a TimeoutError for the given phase. When the response
+// timeout fired, the error is reported as ErrResponseTimeout with the configured limit.
+func (c *Client) timeoutError(ctx context.Context, err error, phase TimeoutPhase) error {
+	if err == nil || c.responseTimeout <= 0 || !errors.Is(context.Cause(ctx), ErrResponseTimeout) {
+		return withTimeoutPhase(err, phase)
+	}
+	cause := fmt.Errorf("%w (limit %v)", ErrResponseTimeout, c.responseTimeout)
+	if !errors.Is(err, ErrResponseTimeout) {
+		cause = fmt.Errorf("%w: %w", cause, err)
+	}
+	return &TimeoutError{Phase: phase, Err: cause}
+}
+
+// responseTimeoutBody releases the response timeout when the body is closed and
+// annotates read errors caused by it.
+type responseTimeoutBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *Client
+}
+
+func (b *responseTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = b.client.timeoutError(b.ctx, err, TimeoutPhaseBody)
+	}
+	return n, err
+}
+
+func (b *responseTimeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
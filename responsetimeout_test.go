@@ -0,0 +1,79 @@
+package gofetch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response Timeout", func() {
+	var dripServer *httptest.Server
+
+	BeforeEach(func() {
+		// Sends one byte every 30ms for 300ms: no single read is idle for long,
+		// but the full response takes well over the configured total.
+		dripServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			for i := 0; i < 10; i++ {
+				_, _ = fmt.Fprint(w, "x")
+				flusher.Flush()
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(30 * time.Millisecond):
+				}
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		dripServer.Close()
+	})
+
+	It("should cancel a buffered request whose body drips past the total timeout", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeout(100 * time.Millisecond))
+
+		start := time.Now()
+		_, err := client.Do(context.Background(), core.NewRequest("GET", dripServer.URL))
+		Expect(time.Since(start)).To(BeNumerically("<", 250*time.Millisecond))
+
+		Expect(errors.Is(err, gofetch.ErrResponseTimeout)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("response timeout exceeded (limit 100ms)"))
+
+		var timeoutErr *gofetch.TimeoutError
+		Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+		Expect(timeoutErr.Phase).To(Equal(gofetch.TimeoutPhaseBody))
+	})
+
+	It("should keep the timeout running while a streamed body is read", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeout(100 * time.Millisecond))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", dripServer.URL))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+
+		_, err = io.ReadAll(resp.Body)
+		Expect(errors.Is(err, gofetch.ErrResponseTimeout)).To(BeTrue())
+	})
+
+	It("should not interfere with responses that finish in time", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeout(time.Second))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", dripServer.URL))
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("xxxxxxxxxx"))
+	})
+})
@@ -0,0 +1,112 @@
+package gofetch
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// Resolver looks up the IP addresses of a host. *net.Resolver implements it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// dialHistoryKey is the context key of the dialHistory of a logical request.
+type dialHistoryKey struct{}
+
+// dialHistory records the IPs that failed to connect for one logical request, across its retries.
+type dialHistory struct {
+	mu sync.Mutex
+	// failed lists the IPs whose last dial failed, least recently failed first
+	failed []string
+}
+
+// withDialHistory gives ctx a fresh dialHistory shared by every attempt of the request.
+func withDialHistory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dialHistoryKey{}, &dialHistory{})
+}
+
+// pick returns the first of ips that has not failed yet. Once every IP has failed, the one that
+// failed longest ago is tried again, so retries cycle through all of them.
+func (h *dialHistory) pick(ips []string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ip := range ips {
+		if !containsString(h.failed, ip) {
+			return ip
+		}
+	}
+	for _, ip := range h.failed {
+		if containsString(ips, ip) {
+			return ip
+		}
+	}
+	return ips[0]
+}
+
+// record notes the outcome of dialing ip.
+func (h *dialHistory) record(ip string, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, f := range h.failed {
+		if f == ip {
+			h.failed = append(h.failed[:i], h.failed[i+1:]...)
+			break
+		}
+	}
+	if failed {
+		h.failed = append(h.failed, ip)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// historyDialer wraps dial so requests carrying a dialHistory resolve the host themselves and
+// dial a single IP per attempt, skipping IPs that already failed for the same request. Other
+// dials, and hosts that are IP literals, go to dial unchanged. A nil dial uses a net.Dialer
+// with the settings of http.DefaultTransport; a nil resolver uses net.DefaultResolver.
+func historyDialer(dial DialContextFunc, resolver Resolver) DialContextFunc {
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		history, ok := ctx.Value(dialHistoryKey{}).(*dialHistory)
+		if !ok {
+			return dial(ctx, network, addr)
+		}
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+		if len(addrs) == 0 {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}}
+		}
+		ips := make([]string, len(addrs))
+		for i, a := range addrs {
+			ips[i] = a.String()
+		}
+
+		ip := history.pick(ips)
+		conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+		history.record(ip, err != nil)
+		return conn, err
+	}
+}
@@ -0,0 +1,91 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrResponseStalled is the cause reported when a response body makes no progress for longer
+// than the limit set by WithResponseTimeoutPerByte.
+var ErrResponseStalled = errors.New("response body stalled")
+
+// WithResponseTimeoutPerByte cancels a request once its response body delivers no data for
+// longer than stall, detecting stalled connections that a total timeout would only catch late,
+// or never for long-lived streams. The limit starts when the headers arrive and is reset by
+// every read that returns data, so a streamed body must be read continuously. Reads then fail
+// with a TimeoutError of phase TimeoutPhaseBody wrapping ErrResponseStalled.
+func WithResponseTimeoutPerByte(stall time.Duration) Option {
+	return func(c *Client) {
+		c.stallTimeout = stall
+	}
+}
+
+// stallDetector cancels a request whose response body makes no progress for its timeout.
+type stallDetector struct {
+	timeout time.Duration
+	cancel  context.CancelCauseFunc
+	timer   *time.Timer
+}
+
+// withStallDetection derives a context the stall detector can cancel and extends cancel to
+// release it. The returned detector is nil when stall detection is off.
+func (c *Client) withStallDetection(ctx context.Context, cancel context.CancelFunc) (context.Context, context.CancelFunc, *stallDetector) {
+	if c.stallTimeout <= 0 {
+		return ctx, cancel, nil
+	}
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	d := &stallDetector{timeout: c.stallTimeout, cancel: cancelCause}
+	return ctx, func() {
+		d.stop()
+		cancelCause(nil)
+		cancel()
+	}, d
+}
+
+// watch starts the stall timer and wraps the body of resp so reads reset it.
+func (d *stallDetector) watch(ctx context.Context, resp *http.Response) {
+	if d == nil || resp.Body == nil {
+		return
+	}
+	d.timer = time.AfterFunc(d.timeout, func() {
+		d.cancel(ErrResponseStalled)
+	})
+	resp.Body = &stallBody{ReadCloser: resp.Body, ctx: ctx, detector: d}
+}
+
+func (d *stallDetector) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// stallBody resets the stall timer on progress and reports reads failed by a stall.
+type stallBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	detector *stallDetector
+}
+
+func (b *stallBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.detector.timer.Reset(b.detector.timeout)
+	}
+	if err != nil && err != io.EOF && errors.Is(context.Cause(b.ctx), ErrResponseStalled) {
+		err = &TimeoutError{
+			Phase: TimeoutPhaseBody,
+			Err:   fmt.Errorf("%w (no progress for %v): %w", ErrResponseStalled, b.detector.timeout, err),
+		}
+	}
+	return n, err
+}
+
+func (b *stallBody) Close() error {
+	b.detector.stop()
+	defer b.detector.cancel(nil)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,93 @@
+package gofetch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/core"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response Stall Timeout", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			switch r.URL.Path {
+			case "/stall":
+				// Sends some data, then goes silent without closing the connection
+				_, _ = fmt.Fprint(w, "partial")
+				flusher.Flush()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(2 * time.Second):
+				}
+			default:
+				// Sends one byte every 30ms for 300ms: slow overall, but never idle for long
+				for i := 0; i < 10; i++ {
+					_, _ = fmt.Fprint(w, "x")
+					flusher.Flush()
+					select {
+					case <-r.Context().Done():
+						return
+					case <-time.After(30 * time.Millisecond):
+					}
+				}
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should cancel a streamed response that stops making progress", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeoutPerByte(100 * time.Millisecond))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", server.URL+"/stall"))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.CloseBody()
+
+		start := time.Now()
+		body, err := io.ReadAll(resp.Body)
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(string(body)).To(Equal("partial"))
+
+		Expect(errors.Is(err, gofetch.ErrResponseStalled)).To(BeTrue())
+		var timeoutErr *gofetch.TimeoutError
+		Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+		Expect(timeoutErr.Phase).To(Equal(gofetch.TimeoutPhaseBody))
+	})
+
+	It("should cancel a buffered request whose body stalls", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeoutPerByte(100 * time.Millisecond))
+
+		_, err := client.Do(context.Background(), core.NewRequest("GET", server.URL+"/stall"))
+		Expect(errors.Is(err, gofetch.ErrResponseStalled)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("no progress for 100ms"))
+	})
+
+	It("should let a slow but steady body finish", func() {
+		client := gofetch.NewClient(gofetch.WithResponseTimeoutPerByte(100 * time.Millisecond))
+
+		resp, err := client.DoStream(context.Background(), core.NewRequest("GET", server.URL+"/steady"))
+		Expect(err).NotTo(HaveOccurred())
+
+		body, err := resp.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal("xxxxxxxxxx"))
+
+		resp, err = client.Do(context.Background(), core.NewRequest("GET", server.URL+"/steady"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.String()).To(Equal("xxxxxxxxxx"))
+	})
+})
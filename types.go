@@ -1,6 +1,10 @@
 package gofetch
 
 import (
+	"io"
+	"net/url"
+	"time"
+
 	"github.com/jzx17/gofetch/core"
 	"github.com/jzx17/gofetch/middlewares"
 )
@@ -9,39 +13,184 @@ type Request = core.Request
 type Response = core.Response
 type AsyncResponse = core.AsyncResponse
 type SizeConfig = core.SizeConfig
+type MultipartPart = core.MultipartPart
+type GraphQLError = core.GraphQLError
+type GraphQLErrorDetail = core.GraphQLErrorDetail
+type GraphQLLocation = core.GraphQLLocation
+type FileReader = core.FileReader
 type StreamOption = core.StreamOption
+type DecompressOption = core.DecompressOption
+type SaveOption = core.SaveOption
+type ChecksumError = core.ChecksumError
+type HeaderMap = core.HeaderMap
+type CORSHeaders = core.CORSHeaders
+type ConnectError = core.ConnectError
+type SSEEvent = core.SSEEvent
+type EmptyBodyPolicy = core.EmptyBodyPolicy
+type DialContextFunc = core.DialContextFunc
+type ContentDecoder = core.ContentDecoder
+type TimestampFormat = core.TimestampFormat
 
 var NewRequest = core.NewRequest
+var NewBufferedResponse = core.NewBufferedResponse
 var DefaultSizeConfig = core.DefaultSizeConfig
 var WithBufferSize = core.WithBufferSize
+var WithDecompression = core.WithDecompression
+var WithMaxLineLength = core.WithMaxLineLength
+var WithProgress = core.WithProgress
+var WithReadTimeout = core.WithReadTimeout
+var ErrStreamReadTimeout = core.ErrStreamReadTimeout
+var WithLenientDecompression = core.WithLenientDecompression
+var WithKeepPartialFile = core.WithKeepPartialFile
+var WithProgressThrottle = core.WithProgressThrottle
+var WithTrailerChecksum = core.WithTrailerChecksum
+var NewCharsetReader = core.NewCharsetReader
+var RegisterContentDecoder = core.RegisterContentDecoder
+var DialWithConnectTimeout = core.DialWithConnectTimeout
+var ErrBodyStreamAborted = core.ErrBodyStreamAborted
+var ErrMultipartStreamNotReplayable = core.ErrMultipartStreamNotReplayable
+var ErrEmptyBody = core.ErrEmptyBody
+var ErrNotUpgraded = core.ErrNotUpgraded
+var WithTrailerChecksumHash = core.WithTrailerChecksumHash
 
 type RoundTripFunc = core.RoundTripFunc
 type TLSTransport = core.TLSTransport
+type RotatingProxy = core.RotatingProxy
+type RotatingProxyOptions = core.RotatingProxyOptions
+type ProxyRotation = core.ProxyRotation
+type ConcurrencyLimitedTransport = core.ConcurrencyLimitedTransport
+type ConcurrencyLimitMode = core.ConcurrencyLimitMode
 type ConfigurableMiddleware = middlewares.ConfigurableMiddleware
 type MiddlewareIdentifier = middlewares.MiddlewareIdentifier
 type Middleware = middlewares.Middleware
 
 var NewTLSTransport = core.NewTLSTransport
+var NewUnixSocketTransport = core.NewUnixSocketTransport
+var NewRotatingProxy = core.NewRotatingProxy
+var WithProxyContext = core.WithProxyContext
+var ProxyFromContext = core.ProxyFromContext
+var NewConcurrencyLimitedTransport = core.NewConcurrencyLimitedTransport
+var ErrConcurrencyLimit = core.ErrConcurrencyLimit
 var CreateMiddleware = middlewares.CreateMiddleware
 var ChainMiddlewares = middlewares.ChainMiddlewares
 var SizeValidationMiddleware = middlewares.SizeValidationMiddleware
 var RetryMiddleware = middlewares.RetryMiddleware
 var RateLimitMiddleware = middlewares.RateLimitMiddleware
 var LoggingMiddleware = middlewares.LoggingMiddleware
+var ResponseValidationMiddleware = middlewares.ResponseValidationMiddleware
+var RequireJSONSchema = middlewares.RequireJSONSchema
+var ResponseCacheMiddleware = middlewares.ResponseCacheMiddleware
+var IdempotencyMiddleware = middlewares.IdempotencyMiddleware
+var CacheMiddleware = middlewares.CacheMiddleware
+var NewMemoryCacheStore = middlewares.NewMemoryCacheStore
+var StatusRewriteMiddleware = middlewares.StatusRewriteMiddleware
+var AuthMiddleware = middlewares.AuthMiddleware
+var TraceMiddleware = middlewares.TraceMiddleware
+var TracingMiddleware = middlewares.TracingMiddleware
+var MetricsMiddleware = middlewares.MetricsMiddleware
+var ConcurrencyObserverMiddleware = middlewares.ConcurrencyObserverMiddleware
+var SingleflightMiddleware = middlewares.SingleflightMiddleware
+var PropagationMiddleware = middlewares.PropagationMiddleware
+var DefaultPropagator = middlewares.DefaultPropagator
+var HeaderCountMiddleware = middlewares.HeaderCountMiddleware
+var SLOMiddleware = middlewares.SLOMiddleware
+var DecompressionMiddleware = middlewares.DecompressionMiddleware
+var DefaultTraceOptions = middlewares.DefaultTraceOptions
 var NewConstantDelayStrategy = middlewares.NewConstantDelayStrategy
+var WithAfterResponseRetry = middlewares.WithAfterResponseRetry
+var WithMaxBodyForAutoRetry = middlewares.WithMaxBodyForAutoRetry
+var WithErrorClassifier = middlewares.WithErrorClassifier
+var ClassifyExchange = middlewares.ClassifyExchange
 var NewExponentialBackoffStrategy = middlewares.NewExponentialBackoffStrategy
+var NewExponentialBackoffStrategyWithJitter = middlewares.NewExponentialBackoffStrategyWithJitter
+var NewRateLimitResetStrategy = middlewares.NewRateLimitResetStrategy
+var RateLimitResetDelay = middlewares.RateLimitResetDelay
 
 type SizeError = middlewares.SizeError
 type RetryError = middlewares.RetryError
 type TimeoutError = middlewares.TimeoutError
+type TimeoutPhase = middlewares.TimeoutPhase
 type RateLimitExceededError = middlewares.RateLimitExceededError
+type ResponseValidationError = middlewares.ResponseValidationError
+type ConcurrencyObserver = middlewares.ConcurrencyObserver
+type ConcurrencyPhase = middlewares.ConcurrencyPhase
+type SchemaValidationError = middlewares.SchemaValidationError
+type SchemaValidator = middlewares.SchemaValidator
+type SchemaValidatorFunc = middlewares.SchemaValidatorFunc
+type SchemaViolation = middlewares.SchemaViolation
+type HeaderCountError = middlewares.HeaderCountError
 type RateLimitOptions = middlewares.RateLimitOptions
 type LoggingOptions = middlewares.LoggingOptions
+type ResponseCacheOptions = middlewares.ResponseCacheOptions
+type IdempotencyOptions = middlewares.IdempotencyOptions
+type CacheOptions = middlewares.CacheOptions
+type CacheStore = middlewares.CacheStore
+type CacheEntry = middlewares.CacheEntry
+type MemoryCacheStore = middlewares.MemoryCacheStore
+type StatusRewriteOptions = middlewares.StatusRewriteOptions
+type AuthOptions = middlewares.AuthOptions
+type StatusRewriteFunc = middlewares.StatusRewriteFunc
+type TraceOptions = middlewares.TraceOptions
+type SLOOptions = middlewares.SLOOptions
+type DecompressionOptions = middlewares.DecompressionOptions
+type TracingOptions = middlewares.TracingOptions
+type MetricsOptions = middlewares.MetricsOptions
+type MetricsRecorder = middlewares.MetricsRecorder
+type Transcript = middlewares.Transcript
 type LogLevel = middlewares.LogLevel
 type LogFormat = middlewares.LogFormat
 type RetryStrategy = middlewares.RetryStrategy
+type RetryOption = middlewares.RetryOption
+type JitterMode = middlewares.JitterMode
+type ErrorClass = middlewares.ErrorClass
+type ErrorClassifier = middlewares.ErrorClassifier
 type ConstantDelayStrategy = middlewares.ConstantDelayStrategy
 type ExponentialRetryStrategy = middlewares.ExponentialBackoffStrategy
+type RateLimitResetStrategy = middlewares.RateLimitResetStrategy
+
+const (
+	ProxyRoundRobin = core.ProxyRoundRobin
+	ProxyRandom     = core.ProxyRandom
+
+	ConcurrencyBlock    = core.ConcurrencyBlock
+	ConcurrencyFailFast = core.ConcurrencyFailFast
+
+	EmptyBodyError  = core.EmptyBodyError
+	EmptyBodyIgnore = core.EmptyBodyIgnore
+
+	TimestampUnixSeconds = core.TimestampUnixSeconds
+	TimestampUnixMillis  = core.TimestampUnixMillis
+	TimestampRFC3339     = core.TimestampRFC3339
+
+	DefaultProgressInterval = core.DefaultProgressInterval
+	DefaultProgressBytes    = core.DefaultProgressBytes
+)
+
+const (
+	JitterNone  = middlewares.JitterNone
+	JitterFull  = middlewares.JitterFull
+	JitterEqual = middlewares.JitterEqual
+)
+
+const (
+	ErrorClassSuccess   = middlewares.ErrorClassSuccess
+	ErrorClassRetryable = middlewares.ErrorClassRetryable
+	ErrorClassFatal     = middlewares.ErrorClassFatal
+)
+
+const (
+	TimeoutPhaseDial         = middlewares.TimeoutPhaseDial
+	TimeoutPhaseTLSHandshake = middlewares.TimeoutPhaseTLSHandshake
+	TimeoutPhaseHeaders      = middlewares.TimeoutPhaseHeaders
+	TimeoutPhaseBody         = middlewares.TimeoutPhaseBody
+)
+
+const (
+	ConcurrencyPhaseStart = middlewares.ConcurrencyPhaseStart
+	ConcurrencyPhaseEnd   = middlewares.ConcurrencyPhaseEnd
+)
+
+const DefaultStatusRewriteMaxInspectBody = middlewares.DefaultStatusRewriteMaxInspectBody
 
 // RequestMethod represents HTTP request methods
 type RequestMethod string
@@ -68,6 +217,20 @@ func WithHeader(key, value string) RequestOption {
 	}
 }
 
+// WithBasicAuth sets HTTP Basic credentials in the Authorization header
+func WithBasicAuth(username, password string) RequestOption {
+	return func(r *Request) {
+		r.WithBasicAuth(username, password)
+	}
+}
+
+// WithBearerToken sets a Bearer token in the Authorization header
+func WithBearerToken(token string) RequestOption {
+	return func(r *Request) {
+		r.WithBearerToken(token)
+	}
+}
+
 // WithQueryParam adds a query parameter to the request
 func WithQueryParam(key, value string) RequestOption {
 	return func(r *Request) {
@@ -75,6 +238,55 @@ func WithQueryParam(key, value string) RequestOption {
 	}
 }
 
+// WithQueryParamInt adds an integer query parameter to the request
+func WithQueryParamInt(key string, value int) RequestOption {
+	return func(r *Request) {
+		r.WithQueryParamInt(key, value)
+	}
+}
+
+// WithQueryParamBool adds a boolean query parameter to the request
+func WithQueryParamBool(key string, value bool) RequestOption {
+	return func(r *Request) {
+		r.WithQueryParamBool(key, value)
+	}
+}
+
+// WithQueryParamSlice adds a repeated query parameter to the request
+func WithQueryParamSlice(key string, values ...string) RequestOption {
+	return func(r *Request) {
+		r.WithQueryParamSlice(key, values...)
+	}
+}
+
+// WithTimestampParam adds a formatted timestamp query parameter to the request
+func WithTimestampParam(key string, t time.Time, format TimestampFormat) RequestOption {
+	return func(r *Request) {
+		r.WithTimestampParam(key, t, format)
+	}
+}
+
+// WithNonce adds a random nonce query parameter to the request
+func WithNonce(key string, source io.Reader) RequestOption {
+	return func(r *Request) {
+		r.WithNonce(key, source)
+	}
+}
+
+// WithUpgrade requests a connection upgrade to the given protocol
+func WithUpgrade(protocol string) RequestOption {
+	return func(r *Request) {
+		r.WithUpgrade(protocol)
+	}
+}
+
+// WithAcceptEncodingQ sets a q-valued Accept-Encoding header on the request
+func WithAcceptEncodingQ(encodings map[string]float64) RequestOption {
+	return func(r *Request) {
+		r.WithAcceptEncodingQ(encodings)
+	}
+}
+
 // WithJSONBody sets a JSON body on the request
 func WithJSONBody(data interface{}) RequestOption {
 	return func(r *Request) {
@@ -82,6 +294,34 @@ func WithJSONBody(data interface{}) RequestOption {
 	}
 }
 
+// WithForm sets a URL-encoded form body on the request
+func WithForm(values url.Values) RequestOption {
+	return func(r *Request) {
+		r.WithFormURLEncoded(values)
+	}
+}
+
+// WithJSONTemplate renders a text/template into a JSON body on the request
+func WithJSONTemplate(tmpl string, data interface{}) RequestOption {
+	return func(r *Request) {
+		r.WithJSONTemplate(tmpl, data)
+	}
+}
+
+// WithGzipBody sets a gzip-compressed byte slice as the request body
+func WithGzipBody(body []byte) RequestOption {
+	return func(r *Request) {
+		r.WithGzipBody(body)
+	}
+}
+
+// WithGzipJSONBody sets a gzip-compressed JSON body on the request
+func WithGzipJSONBody(data interface{}) RequestOption {
+	return func(r *Request) {
+		r.WithGzipJSONBody(data)
+	}
+}
+
 // WithBody sets a byte slice as the request body
 func WithBody(body []byte) RequestOption {
 	return func(r *Request) {
@@ -89,6 +329,13 @@ func WithBody(body []byte) RequestOption {
 	}
 }
 
+// WithAutoContentType enables Content-Type detection for raw bodies, or suppresses the header when false
+func WithAutoContentType(enabled bool) RequestOption {
+	return func(r *Request) {
+		r.WithAutoContentType(enabled)
+	}
+}
+
 // WithHeaders adds multiple headers to the request
 func WithHeaders(headers map[string]string) RequestOption {
 	return func(r *Request) {
@@ -103,6 +350,41 @@ func WithMultipartForm(fields map[string]string, files map[string]string) Reques
 	}
 }
 
+// WithMultipartStream adds a multipart form to the request that is streamed from the file readers
+func WithMultipartStream(fields map[string]string, files map[string]FileReader) RequestOption {
+	return func(r *Request) {
+		r.WithMultipartStream(fields, files)
+	}
+}
+
+// WithBodyTee copies the request body to sink as it is sent, once across retries
+func WithBodyTee(sink io.Writer) RequestOption {
+	return func(r *Request) {
+		r.WithBodyTee(sink)
+	}
+}
+
+// WithProxy sends the request through the given proxy instead of the client's proxy
+func WithProxy(proxyURL string) RequestOption {
+	return func(r *Request) {
+		r.WithProxy(proxyURL)
+	}
+}
+
+// WithMultipartMixed sets a multipart/mixed body on the request
+func WithMultipartMixed(parts []MultipartPart) RequestOption {
+	return func(r *Request) {
+		r.WithMultipartMixed(parts)
+	}
+}
+
+// WithMultipartRelated sets a multipart/related body on the request
+func WithMultipartRelated(rootType string, parts []MultipartPart) RequestOption {
+	return func(r *Request) {
+		r.WithMultipartRelated(rootType, parts)
+	}
+}
+
 // WithChunkedEncoding enables chunked transfer encoding
 func WithChunkedEncoding() RequestOption {
 	return func(r *Request) {
@@ -150,6 +432,19 @@ func NewJSONRequest(method, url string, data interface{}, opts ...RequestOption)
 	return NewRequestWithOptions(method, url, opts...)
 }
 
+// NewGraphQLRequest creates a POST request carrying a GraphQL query and its variables as JSON.
+// Decode the reply with Response.GraphQL.
+func NewGraphQLRequest(url, query string, variables map[string]interface{}, opts ...RequestOption) *Request {
+	payload := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{
+		Query:     query,
+		Variables: variables,
+	}
+	return NewJSONRequest("POST", url, payload, opts...)
+}
+
 // NewWebhookRequest creates a customized request for webhook delivery
 func NewWebhookRequest(url string, payload interface{}, signature string) *Request {
 	return NewJSONRequest("POST", url, payload,
@@ -1,12 +1,15 @@
 package gofetch_test
 
 import (
+	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"time"
 
 	"github.com/jzx17/gofetch"
+	"github.com/jzx17/gofetch/middlewares"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -92,6 +95,23 @@ var _ = Describe("Types and Constructors", func() {
 		Expect(string(body)).To(ContainSubstring(`"value":42`))
 	})
 
+	It("should support GraphQL request constructor", func() {
+		query := "query($id: ID!) { node(id: $id) { id } }"
+		req := gofetch.NewGraphQLRequest("http://example.com/graphql", query,
+			map[string]interface{}{"id": "42"},
+			gofetch.WithBearerToken("token"))
+
+		httpReq, err := req.BuildHTTPRequest()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(httpReq.Method).To(Equal("POST"))
+		Expect(httpReq.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(httpReq.Header.Get("Authorization")).To(Equal("Bearer token"))
+
+		body, err := io.ReadAll(httpReq.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(MatchJSON(`{"query": "query($id: ID!) { node(id: $id) { id } }", "variables": {"id": "42"}}`))
+	})
+
 	It("should support webhook request constructor", func() {
 		payload := map[string]interface{}{
 			"event": "update",
@@ -161,13 +181,44 @@ var _ = Describe("Types and Constructors", func() {
 		Expect(httpReq.ContentLength).To(Equal(int64(-1))) // Indicates chunked encoding
 	})
 
+	It("should set credentials with the auth request options without logging them", func() {
+		var gotAuth []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		}))
+		defer ts.Close()
+
+		var logs bytes.Buffer
+		logOptions := middlewares.DefaultLoggingOptions()
+		logOptions.Level = middlewares.LogLevelDebug
+		logOptions.Writer = &logs
+		client := gofetch.NewClient(gofetch.WithMiddlewares(gofetch.LoggingMiddleware(logOptions)))
+
+		_, err := client.Do(context.Background(), gofetch.NewGetRequest(ts.URL,
+			gofetch.WithBearerToken("old-token"),
+			gofetch.WithBearerToken("s3cr3t-token")))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.Do(context.Background(), gofetch.NewGetRequest(ts.URL,
+			gofetch.WithBasicAuth("user", "p@ss")))
+		Expect(err).NotTo(HaveOccurred())
+
+		expected, _ := http.NewRequest("GET", ts.URL, nil)
+		expected.SetBasicAuth("user", "p@ss")
+		Expect(gotAuth).To(Equal([]string{"Bearer s3cr3t-token", expected.Header.Get("Authorization")}))
+
+		Expect(logs.String()).To(ContainSubstring("Authorization"))
+		Expect(logs.String()).NotTo(ContainSubstring("s3cr3t-token"))
+		Expect(logs.String()).NotTo(ContainSubstring(expected.Header.Get("Authorization")[len("Basic "):]))
+	})
+
 	It("should implement retry strategies", func() {
-		// Test ConstantRetryStrategy
-		constStrategy := &gofetch.ConstantRetryStrategy{
+		// Test ConstantDelayStrategy
+		constStrategy := &gofetch.ConstantDelayStrategy{
 			Delay: 100 * time.Millisecond,
 		}
 
-		delay := constStrategy.NextDelay(2, nil)
+		delay := constStrategy.NextDelay(2, nil, nil)
 		Expect(delay).To(Equal(100 * time.Millisecond))
 
 		// Test ExponentialRetryStrategy
@@ -177,7 +228,10 @@ var _ = Describe("Types and Constructors", func() {
 			Factor:       2.0,
 		}
 
-		delay = expStrategy.NextDelay(1, nil)
+		delay = expStrategy.NextDelay(1, nil, nil)
+		Expect(delay).To(Equal(100 * time.Millisecond)) // First retry waits InitialDelay
+
+		delay = expStrategy.NextDelay(2, nil, nil)
 		Expect(delay).To(Equal(200 * time.Millisecond)) // 100ms * 2.0
 
 		// Test max delay cap
@@ -187,7 +241,7 @@ var _ = Describe("Types and Constructors", func() {
 			Factor:       10.0, // Would result in 10s without cap
 		}
 
-		delay = hugeStrategy.NextDelay(1, nil)
+		delay = hugeStrategy.NextDelay(2, nil, nil)
 		Expect(delay).To(Equal(2 * time.Second)) // Capped at MaxDelay
 	})
 })
@@ -0,0 +1,50 @@
+package gofetch
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithConnectionUpgradeSupport makes Do and DoStream hand back 101 Switching Protocols responses
+// untouched, without buffering, decrypting or timing their body, so protocols such as WebSocket
+// can be bootstrapped on top of the client. Send the handshake with Request.WithUpgrade and take
+// the raw connection with Response.UpgradedConn. Response timeouts stop applying once the
+// connection is upgraded.
+func WithConnectionUpgradeSupport() Option {
+	return func(c *Client) {
+		c.upgradeSupport = true
+	}
+}
+
+// upgradedResponse returns resp as is when it switched protocols and upgrades are supported.
+func (c *Client) upgradedResponse(resp *http.Response) (*Response, bool) {
+	if !c.upgradeSupport || resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, false
+	}
+	c.stripHeaders(resp)
+	return &Response{Response: resp}, true
+}
+
+// send performs httpReq with the underlying client. http.Client hides the connection of an
+// upgraded response behind its timeout wrapper, so upgrade requests are sent without the
+// client timeout and bounded by an equivalent context deadline that ends with the handshake.
+func (c *Client) send(httpReq *http.Request) (*http.Response, error) {
+	if !c.upgradeSupport || httpReq.Header.Get("Upgrade") == "" || c.client.Timeout <= 0 {
+		return c.client.Do(httpReq)
+	}
+
+	client := *c.client
+	client.Timeout = 0
+	ctx, cancel := context.WithTimeout(httpReq.Context(), c.client.Timeout)
+	resp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		cancel()
+		return resp, nil
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
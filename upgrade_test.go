@@ -0,0 +1,115 @@
+package gofetch_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/jzx17/gofetch"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Upgrade", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		// Switches to a line-based echo protocol when asked for "echo"
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "echo" || !strings.EqualFold(r.Header.Get("Connection"), "upgrade") {
+				_, _ = w.Write([]byte("plain"))
+				return
+			}
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\nwelcome\n")
+			_ = rw.Flush()
+			for {
+				line, err := rw.ReadString('\n')
+				if err != nil {
+					return
+				}
+				_, _ = rw.WriteString("echo: " + line)
+				_ = rw.Flush()
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should hand back the raw connection of a 101 response", func() {
+		client := gofetch.NewClient(
+			gofetch.WithConnectionUpgradeSupport(),
+			gofetch.WithResponseTimeout(time.Second),
+		)
+
+		resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL).WithUpgrade("echo"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.IsSwitchingProtocols()).To(BeTrue())
+		Expect(resp.Header.Get("Upgrade")).To(Equal("echo"))
+
+		conn, err := resp.UpgradedConn()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		Expect(reader.ReadString('\n')).To(Equal("welcome\n"))
+		for _, msg := range []string{"hello\n", "world\n"} {
+			_, err = conn.Write([]byte(msg))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.ReadString('\n')).To(Equal("echo: " + msg))
+		}
+	})
+
+	It("should keep the connection open past the client timeout", func() {
+		client := gofetch.NewClient(
+			gofetch.WithConnectionUpgradeSupport(),
+			gofetch.WithTimeout(100*time.Millisecond),
+		)
+
+		resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL).WithUpgrade("echo"))
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := resp.UpgradedConn()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		time.Sleep(200 * time.Millisecond)
+		reader := bufio.NewReader(conn)
+		Expect(reader.ReadString('\n')).To(Equal("welcome\n"))
+		_, err = conn.Write([]byte("late\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reader.ReadString('\n')).To(Equal("echo: late\n"))
+	})
+
+	It("should upgrade streamed requests too", func() {
+		client := gofetch.NewClient(gofetch.WithConnectionUpgradeSupport())
+
+		resp, err := client.DoStream(context.Background(), gofetch.NewRequest("GET", server.URL).WithUpgrade("echo"))
+		Expect(err).NotTo(HaveOccurred())
+
+		conn, err := resp.UpgradedConn()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("should report responses that did not switch protocols", func() {
+		client := gofetch.NewClient(gofetch.WithConnectionUpgradeSupport())
+
+		resp, err := client.Do(context.Background(), gofetch.NewRequest("GET", server.URL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.String()).To(Equal("plain"))
+
+		_, err = resp.UpgradedConn()
+		Expect(errors.Is(err, gofetch.ErrNotUpgraded)).To(BeTrue())
+	})
+})